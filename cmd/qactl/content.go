@@ -0,0 +1,175 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"qa-api/internal/badges"
+	"qa-api/internal/repository"
+	"qa-api/internal/reputation"
+	"qa-api/internal/service"
+	"strconv"
+	"time"
+)
+
+// target parses "question|answer ID"
+func target(args []string) (string, int, error) {
+	if len(args) != 2 || (args[0] != "question" && args[0] != "answer") {
+		return "", 0, errUsage
+	}
+	id, err := strconv.Atoi(args[1])
+	if err != nil || id <= 0 {
+		return "", 0, fmt.Errorf("%w: ID must be a positive integer", errUsage)
+	}
+	return args[0], id, nil
+}
+
+// runDelete handles delete question|answer ID. Deletes are soft, so they
+// can be undone with restore; events are recorded as for API deletes.
+func runDelete(ctx context.Context, a *app, args []string) error {
+	kind, id, err := target(args)
+	if err != nil {
+		return err
+	}
+	if err := a.connect(); err != nil {
+		return err
+	}
+
+	questionRepo := repository.NewQuestionRepository()
+	if kind == "question" {
+		err = service.NewQuestionService(questionRepo).DeleteQuestion(id, 0)
+	} else {
+		err = service.NewAnswerService(repository.NewAnswerRepository(), questionRepo).DeleteAnswer(id, 0)
+	}
+	if err != nil {
+		return err
+	}
+
+	return a.out.result(map[string]interface{}{"deleted": kind, "id": id}, func(w io.Writer) {
+		fmt.Fprintf(w, "Deleted %s %d\n", kind, id)
+	})
+}
+
+// runRestore handles restore question|answer ID
+func runRestore(ctx context.Context, a *app, args []string) error {
+	kind, id, err := target(args)
+	if err != nil {
+		return err
+	}
+	if err := a.connect(); err != nil {
+		return err
+	}
+
+	questionRepo := repository.NewQuestionRepository()
+	if kind == "question" {
+		question, err := service.NewQuestionService(questionRepo).RestoreQuestion(id)
+		if err != nil {
+			return err
+		}
+		result := map[string]interface{}{"restored": kind, "id": question.ID, "answers": len(question.Answers)}
+		return a.out.result(result, func(w io.Writer) {
+			fmt.Fprintf(w, "Restored question %d with %d answers\n", question.ID, len(question.Answers))
+		})
+	}
+
+	answer, err := service.NewAnswerService(repository.NewAnswerRepository(), questionRepo).RestoreAnswer(id)
+	if err != nil {
+		return err
+	}
+	result := map[string]interface{}{"restored": kind, "id": answer.ID, "question_id": answer.QuestionID}
+	return a.out.result(result, func(w io.Writer) {
+		fmt.Fprintf(w, "Restored answer %d on question %d\n", answer.ID, answer.QuestionID)
+	})
+}
+
+// runRecount handles recount
+func runRecount(ctx context.Context, a *app, args []string) error {
+	if len(args) != 0 {
+		return errUsage
+	}
+	if err := a.connect(); err != nil {
+		return err
+	}
+
+	updated, err := service.NewQuestionService(repository.NewQuestionRepository()).RecountAnswers()
+	if err != nil {
+		return err
+	}
+	return a.out.result(map[string]int64{"updated": updated}, func(w io.Writer) {
+		fmt.Fprintf(w, "Fixed answer counts of %d questions\n", updated)
+	})
+}
+
+// runPurge handles purge -older-than DURATION. API and CLI deletes are
+// soft; this is the only path that removes the rows for good.
+func runPurge(ctx context.Context, a *app, args []string) error {
+	var olderThan time.Duration
+	flags, err := subcommand("purge", args, func(flags *flag.FlagSet) {
+		flags.DurationVar(&olderThan, "older-than", 0, "purge content deleted longer ago than this, e.g. 720h")
+	})
+	if err != nil {
+		return err
+	}
+	if flags.NArg() != 0 {
+		return errUsage
+	}
+	if olderThan <= 0 {
+		return fmt.Errorf("%w: -older-than must be a positive duration", errUsage)
+	}
+	if err := a.connect(); err != nil {
+		return err
+	}
+
+	questions, answers, err := service.NewQuestionService(repository.NewQuestionRepository()).PurgeDeleted(olderThan)
+	if err != nil {
+		return err
+	}
+	return a.out.result(map[string]int64{"questions": questions, "answers": answers}, func(w io.Writer) {
+		fmt.Fprintf(w, "Purged %d questions and %d answers\n", questions, answers)
+	})
+}
+
+// runReputation handles reputation rebuild. The server may keep running:
+// the events it records meanwhile are not counted twice.
+func runReputation(ctx context.Context, a *app, args []string) error {
+	if len(args) != 1 || args[0] != "rebuild" {
+		return errUsage
+	}
+	if err := a.connect(); err != nil {
+		return err
+	}
+
+	ledger := reputation.NewLedger(repository.NewReputationRepository())
+	replayed, err := ledger.Rebuild(ctx, repository.NewOutboxRepository())
+	if err != nil {
+		return err
+	}
+	return a.out.result(map[string]int{"replayed": replayed}, func(w io.Writer) {
+		fmt.Fprintf(w, "Rebuilt the reputation ledger from %d events\n", replayed)
+	})
+}
+
+// runBadges handles badges backfill. It reads the same badge rules as the
+// server; badges already held are not awarded again.
+func runBadges(ctx context.Context, a *app, args []string) error {
+	if len(args) != 1 || args[0] != "backfill" {
+		return errUsage
+	}
+	rules, err := badges.Load(a.cfg.Features.BadgesFile)
+	if err != nil {
+		return err
+	}
+	if err := a.connect(); err != nil {
+		return err
+	}
+
+	engine := badges.NewEngine(rules, repository.NewBadgeRepository())
+	awarded, err := engine.Backfill(ctx)
+	if err != nil {
+		return err
+	}
+	return a.out.result(map[string]int{"awarded": awarded}, func(w io.Writer) {
+		fmt.Fprintf(w, "Awarded %d badges\n", awarded)
+	})
+}
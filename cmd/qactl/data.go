@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"qa-api/internal/dataset"
+	"qa-api/internal/repository"
+	"qa-api/internal/service"
+	"strings"
+)
+
+// runImport handles import [-format ndjson|csv] [-dry-run] FILE
+func runImport(ctx context.Context, a *app, args []string) error {
+	var format string
+	var dryRun bool
+	flags, err := subcommand("import", args, func(flags *flag.FlagSet) {
+		flags.StringVar(&format, "format", "", "ndjson or csv (default: from the file extension, else ndjson)")
+		flags.BoolVar(&dryRun, "dry-run", false, "validate without inserting")
+	})
+	if err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return errUsage
+	}
+	path := flags.Arg(0)
+	if format == "" {
+		format = formatOf(path)
+	}
+
+	in := io.Reader(os.Stdin)
+	if path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		in = file
+	}
+	reader, err := dataset.NewReader(format, in)
+	if err != nil {
+		return err
+	}
+
+	if err := a.connect(); err != nil {
+		return err
+	}
+	datasetService := service.NewDatasetService(repository.NewQuestionRepository())
+	report, importErr := datasetService.Import(ctx, reader, dryRun)
+
+	if err := a.out.result(report, func(w io.Writer) { printReport(w, report) }); err != nil {
+		return err
+	}
+	switch {
+	case importErr != nil:
+		return importErr
+	case report.Error != "":
+		return errors.New(report.Error)
+	case report.Rejected > 0:
+		return fmt.Errorf("%d questions rejected", report.Rejected)
+	}
+	return nil
+}
+
+func printReport(w io.Writer, report *service.ImportReport) {
+	verb := "Imported"
+	if report.DryRun {
+		verb = "Dry run: would import"
+	}
+	fmt.Fprintf(w, "%s %d questions with %d answers, rejected %d\n", verb, report.Questions, report.Answers, report.Rejected)
+	for _, e := range report.Errors {
+		message := e.Message
+		if len(e.Fields) > 0 {
+			message += ": " + e.Fields.Error()
+		}
+		fmt.Fprintf(w, "  line %d: %s\n", e.Line, message)
+	}
+	if report.ErrorsTruncated {
+		fmt.Fprintln(w, "  (more errors omitted)")
+	}
+}
+
+// runExport handles export [-format ndjson|csv] [-o FILE]
+func runExport(ctx context.Context, a *app, args []string) error {
+	var format, path string
+	flags, err := subcommand("export", args, func(flags *flag.FlagSet) {
+		flags.StringVar(&format, "format", "", "ndjson or csv (default: from the -o extension, else ndjson)")
+		flags.StringVar(&path, "o", "", "write to FILE instead of stdout")
+	})
+	if err != nil {
+		return err
+	}
+	if flags.NArg() != 0 {
+		return errUsage
+	}
+	if format == "" {
+		format = formatOf(path)
+	}
+
+	if err := a.connect(); err != nil {
+		return err
+	}
+
+	out := a.out.w
+	if path != "" {
+		file, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		out = file
+	}
+	writer, err := dataset.NewWriter(format, out)
+	if err != nil {
+		return err
+	}
+
+	datasetService := service.NewDatasetService(repository.NewQuestionRepository())
+	if err := datasetService.Export(ctx, writer); err != nil {
+		return err
+	}
+	if path == "" {
+		return nil
+	}
+	return a.out.result(map[string]string{"path": path, "format": format}, func(w io.Writer) {
+		fmt.Fprintf(w, "Exported to %s\n", path)
+	})
+}
+
+// formatOf guesses the format from a file name
+func formatOf(path string) string {
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		return dataset.FormatCSV
+	}
+	return dataset.FormatNDJSON
+}
@@ -0,0 +1,149 @@
+// Command qactl operates the Q&A service from the command line: migrations,
+// bulk import and export, moderation and local development data.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"qa-api/internal/config"
+	"qa-api/internal/database"
+	"sort"
+)
+
+// command is a qactl subcommand
+type command struct {
+	usage string
+	help  string
+	run   func(ctx context.Context, app *app, args []string) error
+}
+
+var commands = map[string]command{
+	"migrate":    {"migrate up|down|status|create NAME", "Apply, roll back, list or create SQL migrations", runMigrate},
+	"import":     {"import [-format ndjson|csv] [-dry-run] FILE", "Import questions with answers; FILE - reads stdin", runImport},
+	"export":     {"export [-format ndjson|csv] [-o FILE]", "Export all questions with answers to stdout or FILE", runExport},
+	"delete":     {"delete question|answer ID", "Delete a question (with its answers) or an answer", runDelete},
+	"restore":    {"restore question|answer ID", "Restore a deleted question or answer", runRestore},
+	"purge":      {"purge -older-than DURATION", "Permanently remove content deleted longer ago than DURATION", runPurge},
+	"recount":    {"recount", "Recompute the denormalized answer counts", runRecount},
+	"reputation": {"reputation rebuild", "Recompute reputation by replaying the event history", runReputation},
+	"badges":     {"badges backfill", "Award the badges earned by past activity", runBadges},
+	"seed":       {"seed [-questions N] [-answers N] [-seed N]", "Insert fake questions and answers for local development", runSeed},
+	"config":     {"config", "Validate the configuration and print it with secrets redacted", runConfig},
+}
+
+// errUsage marks errors caused by bad arguments
+var errUsage = errors.New("usage")
+
+// app carries what every command needs
+type app struct {
+	cfg     *config.Config
+	out     *printer
+	verbose bool
+}
+
+// connect opens the GORM connection used by the repositories. Commands
+// such as recount and the backfills run long statements, so the statement
+// timeout, which protects request handlers, is left off.
+func (a *app) connect() error {
+	if !a.verbose {
+		a.cfg.DB.LogLevel = "silent"
+	}
+	a.cfg.DB.StatementTimeout = 0
+	return database.Init(a.cfg)
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run executes qactl and returns the exit code
+func run(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("qactl", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	asJSON := flags.Bool("json", false, "print results as JSON")
+	verbose := flags.Bool("v", false, "log SQL statements")
+	flags.Usage = func() { usage(stderr, flags) }
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() == 0 {
+		usage(stderr, flags)
+		return 2
+	}
+
+	cmd, ok := commands[flags.Arg(0)]
+	if !ok {
+		fmt.Fprintf(stderr, "qactl: unknown command %q\n\n", flags.Arg(0))
+		usage(stderr, flags)
+		return 2
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	cfg, err := config.Load(nil, nil)
+	if err != nil {
+		fmt.Fprintf(stderr, "qactl: %v\n", err)
+		return 1
+	}
+	a := &app{cfg: cfg, out: &printer{w: stdout, json: *asJSON}, verbose: *verbose}
+	if err := cmd.run(ctx, a, flags.Args()[1:]); err != nil {
+		if errors.Is(err, errUsage) {
+			if err != errUsage {
+				fmt.Fprintf(stderr, "qactl: %v\n", err)
+			}
+			fmt.Fprintf(stderr, "usage: qactl %s\n", cmd.usage)
+			return 2
+		}
+		fmt.Fprintf(stderr, "qactl: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+func usage(w io.Writer, flags *flag.FlagSet) {
+	fmt.Fprintln(w, "usage: qactl [-json] [-v] COMMAND [ARGS]")
+	fmt.Fprintln(w, "\nCommands:")
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "  %-48s %s\n", commands[name].usage, commands[name].help)
+	}
+	fmt.Fprintln(w, "\nFlags:")
+	flags.PrintDefaults()
+	fmt.Fprintln(w, "\nThe database is configured like the server: CONFIG_FILE and environment variables.")
+}
+
+// runConfig handles config
+func runConfig(ctx context.Context, a *app, args []string) error {
+	if len(args) != 0 {
+		return errUsage
+	}
+	return a.out.result(a.cfg.Redacted(), func(w io.Writer) {
+		a.cfg.Dump(w)
+	})
+}
+
+// subcommand parses the flags of a command; -h prints them
+func subcommand(name string, args []string, define func(*flag.FlagSet)) (*flag.FlagSet, error) {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	if define != nil {
+		define(flags)
+	}
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil, errUsage
+		}
+		return nil, fmt.Errorf("%w: %v", errUsage, err)
+	}
+	return flags, nil
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunUsage(t *testing.T) {
+	tests := []struct {
+		name   string
+		args   []string
+		stderr string
+	}{
+		{"no command", nil, "usage: qactl [-json] [-v] COMMAND"},
+		{"unknown command", []string{"frobnicate"}, `unknown command "frobnicate"`},
+		{"bad target", []string{"delete", "comment", "1"}, "usage: qactl delete question|answer ID"},
+		{"bad ID", []string{"restore", "answer", "x"}, "ID must be a positive integer"},
+		{"bad migrate action", []string{"migrate", "sideways"}, "usage: qactl migrate"},
+		{"unknown flag", []string{"seed", "-bogus"}, "flag provided but not defined: -bogus"},
+		{"purge without age", []string{"purge"}, "-older-than must be a positive duration"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			code := run(tt.args, &stdout, &stderr)
+			assert.Equal(t, 2, code)
+			assert.Contains(t, stderr.String(), tt.stderr)
+			assert.Empty(t, stdout.String())
+		})
+	}
+}
+
+func TestMigrateCreate(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "004_versions.sql"), nil, 0o644))
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"migrate", "-dir", dir, "create", "Add tags"}, &stdout, &stderr)
+	require.Equal(t, 0, code, stderr.String())
+	assert.Equal(t, "Created "+filepath.Join(dir, "005_add_tags.sql")+"\n", stdout.String())
+
+	stdout.Reset()
+	code = run([]string{"-json", "migrate", "-dir", dir, "create", "more"}, &stdout, &stderr)
+	require.Equal(t, 0, code, stderr.String())
+	var result map[string]string
+	require.NoError(t, json.Unmarshal(stdout.Bytes(), &result))
+	assert.Equal(t, filepath.Join(dir, "006_more.sql"), result["path"])
+}
+
+func TestFakeQuestions(t *testing.T) {
+	now := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	first := fakeQuestions(rand.New(rand.NewSource(7)), 20, 3, now)
+	second := fakeQuestions(rand.New(rand.NewSource(7)), 20, 3, now)
+
+	assert.Equal(t, first, second, "the same seed gives the same data")
+	require.Len(t, first, 20)
+	for _, q := range first {
+		assert.NotEmpty(t, q.Text)
+		assert.LessOrEqual(t, len(q.Answers), 3)
+		assert.True(t, q.CreatedAt.Before(now))
+		for _, a := range q.Answers {
+			assert.True(t, a.CreatedAt.After(q.CreatedAt))
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"qa-api/internal/config"
+	"qa-api/internal/database"
+	"qa-api/internal/migrate"
+	"qa-api/migrations"
+	"time"
+)
+
+// runMigrate handles migrate up|down|status|create NAME
+func runMigrate(ctx context.Context, a *app, args []string) error {
+	var dir string
+	flags, err := subcommand("migrate", args, func(flags *flag.FlagSet) {
+		flags.StringVar(&dir, "dir", "", "read migrations from this directory instead of the embedded ones; create writes to "+migrate.Dir+" by default")
+	})
+	if err != nil {
+		return err
+	}
+	args = flags.Args()
+	if len(args) == 0 {
+		return errUsage
+	}
+
+	if args[0] == "create" {
+		if len(args) != 2 {
+			return errUsage
+		}
+		if dir == "" {
+			dir = migrate.Dir
+		}
+		paths, err := migrate.Create(dir, args[1])
+		if err != nil {
+			return err
+		}
+		result := map[string]string{"path": paths[0]}
+		if len(paths) > 1 {
+			result["sqlite_path"] = paths[1]
+		}
+		return a.out.result(result, func(w io.Writer) {
+			for _, path := range paths {
+				fmt.Fprintf(w, "Created %s\n", path)
+			}
+		})
+	}
+	if len(args) != 1 || (args[0] != "up" && args[0] != "down" && args[0] != "status") {
+		return errUsage
+	}
+
+	dialect := a.cfg.DB.Dialect()
+	var db *sql.DB
+	if dialect == config.DialectSQLite {
+		db, err = sql.Open("sqlite", database.SQLiteDSN(a.cfg.DB.URL))
+	} else {
+		db, err = database.OpenMigrations(a.cfg.DB.URL)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+	fsys := migrations.ForDialect(dialect)
+	if dir != "" {
+		if dialect == config.DialectSQLite {
+			dir = filepath.Join(dir, migrate.SQLiteDir)
+		}
+		fsys = os.DirFS(dir)
+	}
+	migrator, err := migrate.New(db, dialect, fsys)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "up":
+		results, err := migrator.Up(ctx)
+		if printErr := printResults(a.out, results, "Nothing to apply"); printErr != nil {
+			return printErr
+		}
+		return err
+	case "down":
+		results, err := migrator.Down(ctx)
+		if printErr := printResults(a.out, results, "Nothing to roll back"); printErr != nil {
+			return printErr
+		}
+		return err
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		return a.out.result(statuses, func(w io.Writer) {
+			rows := [][]string{{"VERSION", "NAME", "APPLIED AT"}}
+			for _, s := range statuses {
+				appliedAt := "pending"
+				if s.AppliedAt != nil {
+					appliedAt = s.AppliedAt.Format("2006-01-02 15:04:05")
+				}
+				rows = append(rows, []string{fmt.Sprint(s.Version), s.Name, appliedAt})
+			}
+			table(w, rows)
+		})
+	default:
+		return errUsage
+	}
+}
+
+func printResults(out *printer, results []migrate.Result, none string) error {
+	return out.result(results, func(w io.Writer) {
+		if len(results) == 0 {
+			fmt.Fprintln(w, none)
+		}
+		for _, r := range results {
+			fmt.Fprintf(w, "%-4s %s (%v)\n", r.Direction, r.Name, r.Duration.Round(time.Millisecond))
+		}
+	})
+}
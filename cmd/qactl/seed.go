@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"math/rand"
+	"qa-api/internal/models"
+	"qa-api/internal/repository"
+	"time"
+)
+
+const seedBatchSize = 500
+
+var (
+	seedTopics  = []string{"goroutines", "channels", "interfaces", "generics", "error wrapping", "context cancellation", "slices", "maps", "modules", "testing"}
+	seedAsks    = []string{"How do I use %s?", "What is the idiomatic way to handle %s?", "Why are %s slow in my benchmark?", "When should I avoid %s?", "Can someone explain %s?"}
+	seedReplies = []string{"Start with the official documentation on %s.", "I usually keep %s simple and test them well.", "Profile first: %s are rarely the bottleneck.", "Have a look at the standard library, it uses %s everywhere.", "It depends on the use case, but %s work well here."}
+)
+
+// runSeed handles seed [-questions N] [-answers N] [-seed N]
+func runSeed(ctx context.Context, a *app, args []string) error {
+	var questions, answers int
+	var seed int64
+	_, err := subcommand("seed", args, func(flags *flag.FlagSet) {
+		flags.IntVar(&questions, "questions", 50, "number of questions")
+		flags.IntVar(&answers, "answers", 3, "maximum answers per question")
+		flags.Int64Var(&seed, "seed", 1, "random seed, for reproducible data")
+	})
+	if err != nil {
+		return err
+	}
+	if questions < 0 || answers < 0 {
+		return fmt.Errorf("%w: counts must not be negative", errUsage)
+	}
+	if err := a.connect(); err != nil {
+		return err
+	}
+
+	questionRepo := repository.NewQuestionRepository()
+	generated := fakeQuestions(rand.New(rand.NewSource(seed)), questions, answers, time.Now())
+	answerTotal := 0
+	for start := 0; start < len(generated); start += seedBatchSize {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		end := start + seedBatchSize
+		if end > len(generated) {
+			end = len(generated)
+		}
+		if err := questionRepo.CreateInBatches(generated[start:end], seedBatchSize); err != nil {
+			return err
+		}
+		for _, q := range generated[start:end] {
+			answerTotal += len(q.Answers)
+		}
+	}
+
+	return a.out.result(map[string]int{"questions": len(generated), "answers": answerTotal}, func(w io.Writer) {
+		fmt.Fprintf(w, "Seeded %d questions with %d answers\n", len(generated), answerTotal)
+	})
+}
+
+// fakeQuestions generates questions with up to maxAnswers answers each,
+// created over the 30 days before now
+func fakeQuestions(rng *rand.Rand, count, maxAnswers int, now time.Time) []models.Question {
+	questions := make([]models.Question, 0, count)
+	for i := 0; i < count; i++ {
+		topic := seedTopics[rng.Intn(len(seedTopics))]
+		createdAt := now.Add(-time.Duration(rng.Int63n(int64(30 * 24 * time.Hour))))
+		question := models.Question{
+			Text:      fmt.Sprintf(seedAsks[rng.Intn(len(seedAsks))], topic),
+			CreatedAt: createdAt,
+		}
+
+		for j := rng.Intn(maxAnswers + 1); j > 0; j-- {
+			createdAt = createdAt.Add(time.Duration(rng.Int63n(int64(time.Hour))) + time.Second)
+			question.Answers = append(question.Answers, models.Answer{
+				UserID:    fmt.Sprintf("user-%d", rng.Intn(20)+1),
+				Text:      fmt.Sprintf(seedReplies[rng.Intn(len(seedReplies))], topic),
+				CreatedAt: createdAt,
+			})
+		}
+		questions = append(questions, question)
+	}
+	return questions
+}
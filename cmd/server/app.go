@@ -0,0 +1,274 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"qa-api/internal/auth"
+	"qa-api/internal/badges"
+	"qa-api/internal/cache"
+	"qa-api/internal/config"
+	"qa-api/internal/database"
+	"qa-api/internal/events"
+	"qa-api/internal/flags"
+	"qa-api/internal/graphapi"
+	"qa-api/internal/grpcserver"
+	"qa-api/internal/handler"
+	"qa-api/internal/models"
+	"qa-api/internal/notify"
+	"qa-api/internal/openapi"
+	"qa-api/internal/reputation"
+	"qa-api/internal/router"
+	"qa-api/internal/service"
+	"qa-api/internal/storage"
+	"qa-api/internal/stream"
+	"qa-api/internal/webhook"
+	"time"
+
+	"github.com/gorilla/mux"
+	"google.golang.org/grpc"
+)
+
+// app is the wired service. It runs on the storage backend chosen by
+// cfg.Storage, the SQL one on the database set up by database.Init; its
+// background workers stop when the context passed to newApp is cancelled.
+type app struct {
+	handler    http.Handler
+	grpcServer *grpc.Server
+	// stats are the expvar variables of the app, published by main
+	stats map[string]func() interface{}
+
+	closers []func() error
+}
+
+// newApp builds the repositories, services and handlers and starts the
+// background workers
+func newApp(ctx context.Context, cfg *config.Config) (*app, error) {
+	a := &app{stats: make(map[string]func() interface{})}
+
+	// Initialize repositories
+	backend, err := storage.New(cfg.Storage, cfg.DB.URL)
+	if err != nil {
+		return nil, err
+	}
+	questionRepo := backend.Questions
+	answerRepo := backend.Answers
+	outboxRepo := backend.Outbox
+	webhookRepo := backend.Webhooks
+
+	// Initialize event dispatcher
+	eventBus := events.NewInProcessSink()
+	sinks := []events.Sink{eventBus, webhook.NewSink(webhookRepo)}
+	if cfg.Server.EventsFile != "" {
+		fileSink, err := events.NewFileSink(cfg.Server.EventsFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize events file sink: %w", err)
+		}
+		a.closers = append(a.closers, fileSink.Close)
+		sinks = append(sinks, fileSink)
+	}
+	// Keep the reputation ledger up to date, award badges and fill the
+	// notification inboxes. Handlers run in order, so badges see the
+	// reputation of the event they follow.
+	ledger := reputation.NewLedger(backend.Reputation)
+	eventBus.Subscribe(ledger.Handle)
+	badgeRules, err := badges.Load(cfg.Features.BadgesFile)
+	if err != nil {
+		return nil, err
+	}
+	eventBus.Subscribe(badges.NewEngine(badgeRules, backend.Badges).Handle)
+	eventBus.Subscribe(notify.NewNotifier(backend.Notifications).Handle)
+
+	dispatcher := events.NewDispatcher(outboxRepo, sinks...)
+	go dispatcher.Run(ctx)
+
+	// Initialize webhook deliverer
+	deliverer := webhook.NewDeliverer(webhookRepo, cfg.Limits.WebhookMaxAttempts, cfg.Server.WebhookAllowPrivate)
+	go deliverer.Run(ctx)
+
+	// Initialize live event stream (LISTEN/NOTIFY fan-out across replicas
+	// on PostgreSQL)
+	broker := stream.NewBroker()
+	go func() {
+		if err := backend.Feed(ctx, broker); err != nil {
+			log.Printf("Event feed stopped: %v", err)
+		}
+	}()
+
+	// Route reads to the read replicas while they are healthy. Writes made
+	// through other instances arrive as events and stick here as well.
+	if database.Replicas != nil {
+		a.stats["db_replicas"] = func() interface{} { return database.Replicas.Stats() }
+		go database.Replicas.Monitor(ctx, cfg.DB.Replicas.CheckInterval)
+		go stickToPrimary(ctx, broker, database.Replicas)
+	}
+
+	// Load feature flags and runtime settings, reloaded on SIGHUP or change
+	featureFlags, err := flags.NewStore(cfg.Features.FlagsFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load feature flags: %w", err)
+	}
+	go featureFlags.Watch(ctx, cfg.Features.FlagsPollInterval)
+
+	// Initialize services
+	questions := service.NewQuestionService(questionRepo)
+	questions.SetViewMilestones(badges.Thresholds(badgeRules, "question_views"))
+	go questions.FlushViewsEvery(ctx, cfg.Features.ViewFlushInterval)
+	var questionService service.QuestionServiceInterface = questions
+	if cfg.Cache.QuestionSize > 0 {
+		questionCache := cache.NewLRU[int, *models.Question](cfg.Cache.QuestionSize, cfg.Cache.QuestionTTL)
+		a.stats["question_cache"] = func() interface{} { return questionCache.Stats() }
+		cachedQuestions := service.NewCachedQuestionService(questionService, questionCache)
+		go invalidateQuestionCache(ctx, broker, cachedQuestions)
+		questionService = cachedQuestions
+	}
+	answerService := service.NewAnswerService(answerRepo, questionRepo)
+	webhookService := service.NewWebhookService(webhookRepo, questionRepo, cfg.Server.WebhookAllowPrivate)
+	datasetService := service.NewDatasetService(questionRepo)
+	userService := service.NewUserService(backend.Users, backend.Reputation)
+	voteService := service.NewVoteService(backend.Votes, answerRepo)
+	badgeService := service.NewBadgeService(badgeRules, backend.Badges, backend.Users)
+	notificationService := service.NewNotificationService(backend.Notifications)
+
+	// Initialize handlers
+	questionHandler := handler.NewQuestionHandler(questionService)
+	answerHandler := handler.NewAnswerHandler(answerService)
+	webhookHandler := handler.NewWebhookHandler(webhookService)
+	streamHandler := handler.NewStreamHandler(questionService, broker, outboxRepo)
+	webSocketHandler := handler.NewWebSocketHandler(broker, cfg.Server.WSSlowConsumer)
+	v2Handler := handler.NewV2Handler(questionService, answerService)
+	adminHandler := handler.NewAdminHandler(datasetService)
+	flagsHandler := handler.NewFlagsHandler(featureFlags)
+	userHandler := handler.NewUserHandler(userService)
+	voteHandler := handler.NewVoteHandler(voteService, featureFlags)
+	badgeHandler := handler.NewBadgeHandler(badgeService)
+	notificationHandler := handler.NewNotificationHandler(notificationService)
+
+	schema, err := graphapi.NewSchema(questionService, answerService, questionRepo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build GraphQL schema: %w", err)
+	}
+	graphQLLimits := func() graphapi.Limits {
+		return graphapi.Limits{
+			MaxDepth:      featureFlags.Int(flags.GraphQLMaxDepth, cfg.Limits.GraphQLMaxDepth),
+			MaxComplexity: featureFlags.Int(flags.GraphQLMaxComplexity, cfg.Limits.GraphQLMaxComplexity),
+		}
+	}
+	graphQLHandler := graphapi.NewHandler(schema, questionRepo, answerRepo, graphQLLimits())
+	featureFlags.OnReload(func() { graphQLHandler.SetLimits(graphQLLimits()) })
+
+	authenticator := auth.NewAuthenticator(cfg.Auth.APITokens)
+	a.grpcServer = grpcserver.New(questionService, answerService, authenticator)
+
+	// Setup routes
+	middlewares := []mux.MiddlewareFunc{authenticator.Middleware}
+	if database.Breaker != nil {
+		a.stats["db_breaker"] = func() interface{} { return database.Breaker.State().String() }
+		middlewares = append(middlewares, database.Breaker.Middleware)
+	}
+	if cfg.Features.OpenAPIValidate {
+		validator, err := openapi.NewValidator(openapi.Spec())
+		if err != nil {
+			return nil, fmt.Errorf("failed to load OpenAPI spec: %w", err)
+		}
+		middlewares = append(middlewares, validator.Middleware)
+	}
+	deprecatedAt, err := time.Parse(time.DateOnly, cfg.Server.LegacyDeprecatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("invalid LEGACY_API_DEPRECATED_AT: %w", err)
+	}
+	sunset, err := time.Parse(time.DateOnly, cfg.Server.LegacySunset)
+	if err != nil {
+		return nil, fmt.Errorf("invalid LEGACY_API_SUNSET: %w", err)
+	}
+	a.handler = router.New(router.Handlers{
+		Question:     questionHandler,
+		Answer:       answerHandler,
+		Webhook:      webhookHandler,
+		Stream:       streamHandler,
+		WebSocket:    webSocketHandler,
+		V2:           v2Handler,
+		User:         userHandler,
+		Vote:         voteHandler,
+		Badge:        badgeHandler,
+		Notification: notificationHandler,
+		Admin:        adminHandler,
+		Flags:        flagsHandler,
+		GraphQL:      graphQLHandler,
+	}, router.Options{
+		DeprecatedAt: deprecatedAt,
+		Sunset:       sunset,
+		Admin:        []mux.MiddlewareFunc{authenticator.RequireUsers(cfg.Auth.AdminUsers, cfg.Auth.AdminOpen)},
+	}, middlewares...)
+
+	return a, nil
+}
+
+// Close releases what the app opened; the background workers stop with
+// the context passed to newApp
+func (a *app) Close() error {
+	var firstErr error
+	for _, close := range a.closers {
+		if err := close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// invalidateQuestionCache drops cached questions as their events arrive. The
+// broker is fed over LISTEN/NOTIFY, so every replica sees every change. If
+// the subscription falls behind or the feed reconnects, events were lost
+// and the whole cache goes.
+func invalidateQuestionCache(ctx context.Context, broker *stream.Broker, cached *service.CachedQuestionService) {
+	all := func(events.Event) bool { return true }
+	for {
+		sub := broker.Subscribe(all, 256)
+		for open := true; open; {
+			select {
+			case <-ctx.Done():
+				broker.Unsubscribe(sub)
+				return
+			case event, ok := <-sub.Events():
+				if ok {
+					cached.Invalidate(event)
+				}
+				open = ok
+			case <-sub.Gaps():
+				log.Printf("Event feed may have missed events, purging the question cache")
+				cached.Purge()
+			}
+		}
+
+		log.Printf("Question cache fell behind the event stream, purging")
+		cached.Purge()
+	}
+}
+
+// stickToPrimary marks the questions of events as written, so reads after a
+// write made through another instance also see it. The view milestones that
+// award badges change nothing readers see, so they are skipped. If the
+// subscription falls behind or the feed reconnects, events were lost and
+// every question sticks for the window.
+func stickToPrimary(ctx context.Context, broker *stream.Broker, replicas *database.ReplicaSet) {
+	writes := func(event events.Event) bool { return event.Type != events.QuestionViewed }
+	for {
+		sub := broker.Subscribe(writes, 256)
+		for open := true; open; {
+			select {
+			case <-ctx.Done():
+				broker.Unsubscribe(sub)
+				return
+			case event, ok := <-sub.Events():
+				if ok {
+					replicas.MarkWritten(event.QuestionID)
+				}
+				open = ok
+			case <-sub.Gaps():
+				replicas.MarkWritten()
+			}
+		}
+		replicas.MarkWritten()
+	}
+}
@@ -0,0 +1,330 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"qa-api/internal/config"
+	"qa-api/internal/database"
+	"qa-api/internal/storage"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newMemoryServer runs the whole service on the in-memory storage
+func newMemoryServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	cfg, err := config.Load(nil, nil)
+	require.NoError(t, err)
+	cfg.Storage = storage.Memory
+	return startServer(t, cfg)
+}
+
+// newSQLiteServer runs the whole service on a fresh SQLite database,
+// migrated the way the server does it at startup
+func newSQLiteServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	cfg, err := config.Load(nil, nil)
+	require.NoError(t, err)
+	cfg.DB.URL = "sqlite:" + filepath.Join(t.TempDir(), "qa.db")
+	cfg.DB.LogLevel = "silent"
+
+	require.NoError(t, database.Init(cfg))
+	sqlDB, err := database.GetDB().DB()
+	require.NoError(t, err)
+	t.Cleanup(func() { sqlDB.Close() })
+	require.NoError(t, migrateSchema(cfg, "auto"))
+	require.NoError(t, migrateSchema(cfg, "check"))
+	return startServer(t, cfg)
+}
+
+func startServer(t *testing.T, cfg *config.Config) *httptest.Server {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	application, err := newApp(ctx, cfg)
+	require.NoError(t, err)
+	t.Cleanup(func() { application.Close() })
+
+	server := httptest.NewServer(application.handler)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestApp_MemoryStorage(t *testing.T) {
+	server := newMemoryServer(t)
+
+	questionID := createQuestion(t, server, "How do I stop a goroutine?")
+	resp, body := createAnswer(t, server, questionID, "alice")
+	require.Equal(t, http.StatusCreated, resp.StatusCode, string(body))
+
+	resp, body = do(t, "GET", fmt.Sprintf("%s/v1/questions/%d", server.URL, questionID), nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Contains(t, string(body), `"user_id":"alice"`)
+	assert.Equal(t, `W/"2"`, resp.Header.Get("ETag"))
+
+	resp, _ = do(t, "DELETE", fmt.Sprintf("%s/v1/questions/%d", server.URL, questionID), nil, "If-Match", `W/"1"`)
+	assert.Equal(t, http.StatusPreconditionFailed, resp.StatusCode)
+	resp, _ = do(t, "DELETE", fmt.Sprintf("%s/v1/questions/%d", server.URL, questionID), nil, "If-Match", `W/"2"`)
+	require.Equal(t, http.StatusNoContent, resp.StatusCode)
+	resp, _ = createAnswer(t, server, questionID, "bob")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestApp_SQLite(t *testing.T) {
+	server := newSQLiteServer(t)
+
+	questionID := createQuestion(t, server, "How do I stop a goroutine?")
+	questionURL := fmt.Sprintf("%s/v1/questions/%d", server.URL, questionID)
+	resp, _ := do(t, "GET", questionURL, nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, `W/"1"`, resp.Header.Get("ETag"))
+
+	resp, body := createAnswer(t, server, questionID, "alice")
+	require.Equal(t, http.StatusCreated, resp.StatusCode, string(body))
+
+	// The cached question is dropped once the outbox poller sees the answer
+	assert.Eventually(t, func() bool {
+		resp, body, err := request("GET", questionURL, nil)
+		return err == nil && resp.Header.Get("ETag") == `W/"2"` && bytes.Contains(body, []byte(`"user_id":"alice"`))
+	}, 5*time.Second, 20*time.Millisecond)
+
+	resp, _ = do(t, "DELETE", questionURL, nil, "If-Match", `W/"2"`)
+	require.Equal(t, http.StatusNoContent, resp.StatusCode)
+	resp, _ = createAnswer(t, server, questionID, "bob")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	resp, _ = createAnswer(t, server, questionID+1000, "bob")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestApp_Reputation(t *testing.T) {
+	cfg, err := config.Load(nil, nil)
+	require.NoError(t, err)
+	cfg.Storage = storage.Memory
+	cfg.Features.FlagsFile = filepath.Join(t.TempDir(), "flags.yaml")
+	require.NoError(t, os.WriteFile(cfg.Features.FlagsFile, []byte("flags:\n  voting:\n    enabled: true\n"), 0o644))
+	server := startServer(t, cfg)
+
+	resp, body := do(t, "POST", server.URL+"/v1/questions/", map[string]string{"user_id": "alice", "text": "How do I stop a goroutine?"})
+	require.Equal(t, http.StatusCreated, resp.StatusCode, string(body))
+	var question struct{ ID int }
+	require.NoError(t, json.Unmarshal(body, &question))
+
+	answerIDs := map[string]int{}
+	for _, userID := range []string{"bob", "carol"} {
+		resp, body := createAnswer(t, server, question.ID, userID)
+		require.Equal(t, http.StatusCreated, resp.StatusCode, string(body))
+		var answer struct{ ID int }
+		require.NoError(t, json.Unmarshal(body, &answer))
+		answerIDs[userID] = answer.ID
+	}
+	voteURL := fmt.Sprintf("%s/v1/answers/%d/vote", server.URL, answerIDs["bob"])
+	acceptURL := fmt.Sprintf("%s/v1/answers/%d/accept", server.URL, answerIDs["bob"])
+
+	resp, _ = do(t, "PUT", voteURL, map[string]interface{}{"user_id": "carol", "value": 1})
+	require.Equal(t, http.StatusNoContent, resp.StatusCode)
+	resp, _ = do(t, "PUT", voteURL, map[string]interface{}{"user_id": "dave", "value": -1})
+	require.Equal(t, http.StatusNoContent, resp.StatusCode)
+	resp, _ = do(t, "PUT", voteURL, map[string]interface{}{"user_id": "bob", "value": 1})
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	resp, _ = do(t, "POST", acceptURL+"?user_id=carol", nil)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	resp, _ = do(t, "POST", acceptURL+"?user_id=alice", nil)
+	require.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	// The ledger catches up once the dispatcher delivers the events
+	assert.Eventually(t, func() bool {
+		resp, body, err := request("GET", server.URL+"/v1/users/bob", nil)
+		return err == nil && resp.StatusCode == http.StatusOK && bytes.Contains(body, []byte(`"reputation":23`))
+	}, 5*time.Second, 20*time.Millisecond)
+
+	resp, body = do(t, "GET", server.URL+"/v1/leaderboard?limit=2", nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var standings []struct {
+		UserID     string `json:"user_id"`
+		Reputation int    `json:"reputation"`
+	}
+	require.NoError(t, json.Unmarshal(body, &standings))
+	require.Len(t, standings, 2)
+	assert.Equal(t, "bob", standings[0].UserID)
+	assert.Equal(t, "alice", standings[1].UserID)
+	assert.Equal(t, 2, standings[1].Reputation)
+
+	resp, _ = do(t, "GET", server.URL+"/v1/users/nobody", nil)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	resp, body = do(t, "PUT", server.URL+"/v1/users/alice", map[string]string{"display_name": "Alice"})
+	require.Equal(t, http.StatusOK, resp.StatusCode, string(body))
+	assert.Contains(t, string(body), `"display_name":"Alice"`)
+}
+
+func TestApp_Badges(t *testing.T) {
+	cfg, err := config.Load(nil, nil)
+	require.NoError(t, err)
+	cfg.Storage = storage.Memory
+	cfg.Features.BadgesFile = filepath.Join(t.TempDir(), "badges.yaml")
+	cfg.Features.ViewFlushInterval = 10 * time.Millisecond
+	require.NoError(t, os.WriteFile(cfg.Features.BadgesFile, []byte(`badges:
+  - name: first_answer
+    title: Teacher
+    metric: answers
+    tiers: [1]
+  - name: popular_question
+    title: Popular Question
+    metric: question_views
+    tiers: [2, 3]
+`), 0o644))
+	cfg.Auth.APITokens = "alice-token:alice,bob-token:bob,carol-token:carol"
+	server := startServer(t, cfg)
+	bob := []string{"Authorization", "Bearer bob-token"}
+
+	resp, body := do(t, "GET", server.URL+"/v1/badges", nil, bob...)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Contains(t, string(body), `"name":"popular_question"`)
+
+	resp, body = do(t, "POST", server.URL+"/v1/questions/", map[string]string{"text": "How do I stop a goroutine?"}, "Authorization", "Bearer alice-token")
+	require.Equal(t, http.StatusCreated, resp.StatusCode, string(body))
+	var question struct{ ID int }
+	require.NoError(t, json.Unmarshal(body, &question))
+	resp, body = do(t, "POST", answersURL(server, question.ID), map[string]string{"user_id": "mallory", "text": "Use a channel"}, bob...)
+	require.Equal(t, http.StatusCreated, resp.StatusCode, string(body))
+	assert.Contains(t, string(body), `"user_id":"bob"`, "the authenticated user is the author")
+	// Repeated views by bob count once, so the question has two views
+	for _, token := range []string{"bob-token", "carol-token", "bob-token"} {
+		resp, _ = do(t, "GET", fmt.Sprintf("%s/v1/questions/%d", server.URL, question.ID), nil, "Authorization", "Bearer "+token)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+	}
+
+	var awards []struct {
+		Badge     string `json:"badge"`
+		Tier      int    `json:"tier"`
+		Threshold int    `json:"threshold"`
+	}
+	assert.Eventually(t, func() bool {
+		resp, body, err := request("GET", server.URL+"/v1/users/alice/badges", nil, bob...)
+		return err == nil && resp.StatusCode == http.StatusOK && json.Unmarshal(body, &awards) == nil && len(awards) == 1
+	}, 5*time.Second, 20*time.Millisecond)
+	assert.Equal(t, "popular_question", awards[0].Badge)
+	assert.Equal(t, 2, awards[0].Threshold)
+
+	assert.Eventually(t, func() bool {
+		resp, body, err := request("GET", server.URL+"/v1/users/bob/badges", nil, bob...)
+		return err == nil && resp.StatusCode == http.StatusOK && bytes.Contains(body, []byte(`"badge":"first_answer"`))
+	}, 5*time.Second, 20*time.Millisecond)
+
+	resp, _ = do(t, "GET", server.URL+"/v1/users/nobody/badges", nil, bob...)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestApp_Notifications(t *testing.T) {
+	cfg, err := config.Load(nil, nil)
+	require.NoError(t, err)
+	cfg.Storage = storage.Memory
+	cfg.Auth.APITokens = "alice-token:alice,bob-token:bob,carol-token:carol,dave-token:dave"
+	server := startServer(t, cfg)
+	as := func(user string) []string { return []string{"Authorization", "Bearer " + user + "-token"} }
+
+	resp, body := do(t, "POST", server.URL+"/v1/questions/", map[string]string{"text": "How do I stop a goroutine?"}, as("alice")...)
+	require.Equal(t, http.StatusCreated, resp.StatusCode, string(body))
+	var question struct{ ID int }
+	require.NoError(t, json.Unmarshal(body, &question))
+	followURL := fmt.Sprintf("%s/v1/questions/%d/follow", server.URL, question.ID)
+	resp, _ = do(t, "PUT", followURL, nil, as("carol")...)
+	require.Equal(t, http.StatusNoContent, resp.StatusCode)
+	resp, _ = do(t, "PUT", fmt.Sprintf("%s/v1/questions/%d/follow", server.URL, question.ID+1000), nil, as("carol")...)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	resp, body = do(t, "PUT", server.URL+"/v1/me/notifications/preferences", map[string]interface{}{
+		"preferences": map[string]bool{"accepted": false},
+	}, as("bob")...)
+	require.Equal(t, http.StatusOK, resp.StatusCode, string(body))
+	assert.Contains(t, string(body), `"accepted":false`)
+	assert.Contains(t, string(body), `"answer":true`)
+	resp, _ = do(t, "PUT", server.URL+"/v1/me/notifications/preferences", map[string]interface{}{
+		"preferences": map[string]bool{"mentions": false},
+	}, as("bob")...)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	resp, _ = do(t, "PUT", server.URL+"/v1/me/notifications/preferences", map[string]interface{}{
+		"user_id": "alice", "preferences": map[string]bool{"answer": false},
+	}, as("bob")...)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode, "the user cannot be named in the body")
+
+	resp, body = do(t, "POST", answersURL(server, question.ID), map[string]string{"text": "Use a channel"}, as("bob")...)
+	require.Equal(t, http.StatusCreated, resp.StatusCode, string(body))
+	var answer struct{ ID int }
+	require.NoError(t, json.Unmarshal(body, &answer))
+	resp, _ = do(t, "POST", fmt.Sprintf("%s/v1/answers/%d/accept", server.URL, answer.ID), nil, as("alice")...)
+	require.Equal(t, http.StatusNoContent, resp.StatusCode)
+	resp, body = do(t, "POST", answersURL(server, question.ID), map[string]string{"text": "Close a channel"}, as("dave")...)
+	require.Equal(t, http.StatusCreated, resp.StatusCode, string(body))
+
+	type inbox struct {
+		UnreadCount   int64 `json:"unread_count"`
+		Notifications []struct {
+			ID      int64  `json:"id"`
+			Kind    string `json:"kind"`
+			ActorID string `json:"actor_id"`
+			Read    bool   `json:"read"`
+		} `json:"notifications"`
+	}
+	// The inboxes fill once the dispatcher delivers the events, in order
+	var alice inbox
+	assert.Eventually(t, func() bool {
+		resp, body, err := request("GET", server.URL+"/v1/me/notifications", nil, as("alice")...)
+		return err == nil && resp.StatusCode == http.StatusOK && json.Unmarshal(body, &alice) == nil && alice.UnreadCount == 2
+	}, 5*time.Second, 20*time.Millisecond)
+	require.Len(t, alice.Notifications, 2)
+	assert.Equal(t, "dave", alice.Notifications[0].ActorID, "newest first")
+	assert.Equal(t, "answer", alice.Notifications[1].Kind)
+
+	var carol inbox
+	resp, body = do(t, "GET", server.URL+"/v1/me/notifications?user_id=alice", nil, as("carol")...)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.NoError(t, json.Unmarshal(body, &carol))
+	assert.Equal(t, int64(2), carol.UnreadCount, "?user_id= does not open another inbox")
+	assert.Equal(t, "followed_answer", carol.Notifications[0].Kind)
+	resp, body = do(t, "GET", server.URL+"/v1/me/notifications", nil, as("bob")...)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Contains(t, string(body), `"unread_count":0`, "bob turned acceptance notifications off")
+
+	readURL := fmt.Sprintf("%s/v1/me/notifications/%d/read", server.URL, alice.Notifications[1].ID)
+	resp, _ = do(t, "POST", readURL, nil, as("carol")...)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	resp, _ = do(t, "POST", readURL, nil, as("alice")...)
+	require.Equal(t, http.StatusNoContent, resp.StatusCode)
+	resp, body = do(t, "GET", server.URL+"/v1/me/notifications?limit=1", nil, as("alice")...)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.NoError(t, json.Unmarshal(body, &alice))
+	assert.Equal(t, int64(1), alice.UnreadCount)
+	assert.Len(t, alice.Notifications, 1)
+
+	resp, _ = do(t, "POST", server.URL+"/v1/me/notifications/read-all", nil, as("alice")...)
+	require.Equal(t, http.StatusNoContent, resp.StatusCode)
+	resp, body = do(t, "GET", server.URL+"/v1/me/notifications", nil, as("alice")...)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Contains(t, string(body), `"unread_count":0`)
+}
+
+func TestApp_NotificationsNeedAuthentication(t *testing.T) {
+	server := newMemoryServer(t)
+	questionID := createQuestion(t, server, "How do I stop a goroutine?")
+
+	for _, tt := range []struct{ method, path string }{
+		{"GET", "/v1/me/notifications?user_id=alice"},
+		{"POST", "/v1/me/notifications/1/read?user_id=alice"},
+		{"POST", "/v1/me/notifications/read-all?user_id=alice"},
+		{"GET", "/v1/me/notifications/preferences?user_id=alice"},
+		{"PUT", fmt.Sprintf("/v1/questions/%d/follow?user_id=alice", questionID)},
+		{"DELETE", fmt.Sprintf("/v1/questions/%d/follow?user_id=alice", questionID)},
+	} {
+		resp, _ := do(t, tt.method, server.URL+tt.path, nil)
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode, "%s %s", tt.method, tt.path)
+	}
+}
@@ -0,0 +1,239 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"qa-api/internal/config"
+	"qa-api/internal/database"
+	"qa-api/internal/models"
+	"qa-api/internal/pgtest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMain(m *testing.M) {
+	os.Exit(pgtest.Run(m))
+}
+
+// newTestServer runs the whole service against a fresh schema, migrated
+// the way the server does it at startup
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	dsn := pgtest.Schema(t)
+
+	cfg, err := config.Load(nil, nil)
+	require.NoError(t, err)
+	cfg.DB.URL = dsn
+	cfg.DB.LogLevel = "silent"
+	cfg.DB.Retry.Attempts = 1
+	// Answers reach the question cache through LISTEN/NOTIFY, so a cached
+	// question could be stale for a moment right after a write
+	cfg.Cache.QuestionSize = 0
+
+	require.NoError(t, database.Init(cfg))
+	sqlDB, err := database.GetDB().DB()
+	require.NoError(t, err)
+	t.Cleanup(func() { sqlDB.Close() })
+	require.NoError(t, migrateSchema(cfg, "auto"))
+	require.NoError(t, migrateSchema(cfg, "check"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	application, err := newApp(ctx, cfg)
+	require.NoError(t, err)
+	t.Cleanup(func() { application.Close() })
+
+	server := httptest.NewServer(application.handler)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// request sends a JSON request and reads the whole response; header holds
+// name, value pairs. It does not fail the test, so goroutines can use it.
+func request(method, url string, body interface{}, header ...string) (*http.Response, []byte, error) {
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return nil, nil, err
+		}
+	}
+	req, err := http.NewRequest(method, url, bytes.NewReader(payload))
+	if err != nil {
+		return nil, nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for i := 0; i+1 < len(header); i += 2 {
+		req.Header.Set(header[i], header[i+1])
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	return resp, data, err
+}
+
+func do(t *testing.T, method, url string, body interface{}, header ...string) (*http.Response, []byte) {
+	t.Helper()
+	resp, data, err := request(method, url, body, header...)
+	require.NoError(t, err)
+	return resp, data
+}
+
+func createQuestion(t *testing.T, server *httptest.Server, text string) int {
+	t.Helper()
+	resp, body := do(t, "POST", server.URL+"/v1/questions/", map[string]string{"text": text})
+	require.Equal(t, http.StatusCreated, resp.StatusCode, string(body))
+	var question struct{ ID int }
+	require.NoError(t, json.Unmarshal(body, &question))
+	return question.ID
+}
+
+func createAnswer(t *testing.T, server *httptest.Server, questionID int, userID string) (*http.Response, []byte) {
+	t.Helper()
+	return do(t, "POST", answersURL(server, questionID), map[string]string{"user_id": userID, "text": "Use a channel"})
+}
+
+func answersURL(server *httptest.Server, questionID int) string {
+	return fmt.Sprintf("%s/v1/questions/%d/answers/", server.URL, questionID)
+}
+
+func TestIntegration_QuestionLifecycle(t *testing.T) {
+	server := newTestServer(t)
+
+	questionID := createQuestion(t, server, "How do I stop a goroutine?")
+	resp, body := createAnswer(t, server, questionID, "alice")
+	require.Equal(t, http.StatusCreated, resp.StatusCode, string(body))
+	var answer struct{ ID int }
+	require.NoError(t, json.Unmarshal(body, &answer))
+
+	resp, body = do(t, "GET", fmt.Sprintf("%s/v1/questions/%d", server.URL, questionID), nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Contains(t, string(body), `"user_id":"alice"`)
+	etag := resp.Header.Get("ETag")
+	assert.Equal(t, `W/"2"`, etag, "the answer bumped the question version")
+
+	resp, _ = do(t, "DELETE", fmt.Sprintf("%s/v1/questions/%d", server.URL, questionID), nil, "If-Match", `W/"1"`)
+	assert.Equal(t, http.StatusPreconditionFailed, resp.StatusCode)
+	resp, _ = do(t, "DELETE", fmt.Sprintf("%s/v1/questions/%d", server.URL, questionID), nil, "If-Match", etag)
+	require.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	resp, _ = do(t, "GET", fmt.Sprintf("%s/v1/questions/%d", server.URL, questionID), nil)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	resp, _ = do(t, "GET", fmt.Sprintf("%s/v1/answers/%d", server.URL, answer.ID), nil)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode, "deleting a question deletes its answers")
+	resp, _ = createAnswer(t, server, questionID, "bob")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	var types []string
+	require.NoError(t, database.GetDB().Model(&models.OutboxEvent{}).Order("id").Pluck("event_type", &types).Error)
+	assert.Equal(t, []string{"question.created", "answer.created", "question.deleted"}, types, "every change records its event in the same transaction")
+}
+
+func TestIntegration_HardDeleteCascades(t *testing.T) {
+	server := newTestServer(t)
+
+	questionID := createQuestion(t, server, "Why is my map nil?")
+	for _, user := range []string{"alice", "bob"} {
+		resp, body := createAnswer(t, server, questionID, user)
+		require.Equal(t, http.StatusCreated, resp.StatusCode, string(body))
+	}
+
+	db := database.GetDB()
+	require.NoError(t, db.Exec("DELETE FROM questions WHERE id = ?", questionID).Error)
+	var answers int64
+	require.NoError(t, db.Unscoped().Model(&models.Answer{}).Where("question_id = ?", questionID).Count(&answers).Error)
+	assert.Zero(t, answers, "answers are removed by ON DELETE CASCADE")
+}
+
+func TestIntegration_ConstraintViolations(t *testing.T) {
+	server := newTestServer(t)
+	db := database.GetDB()
+
+	err := db.Exec("INSERT INTO answers (question_id, user_id, text) VALUES (999, 'alice', 'orphan')").Error
+	assert.ErrorContains(t, err, "SQLSTATE 23503", "answers must reference a question")
+
+	err = db.Exec("INSERT INTO questions (text) VALUES (NULL)").Error
+	assert.ErrorContains(t, err, "SQLSTATE 23502")
+
+	resp, _ := createAnswer(t, server, 999, "alice")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	resp, _ = do(t, "POST", server.URL+"/v1/questions/", map[string]string{"text": ""})
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestIntegration_ConcurrentAnswers(t *testing.T) {
+	server := newTestServer(t)
+	questionID := createQuestion(t, server, "Is append thread-safe?")
+
+	const writers = 20
+	var wg sync.WaitGroup
+	statuses := make([]int, writers)
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			resp, _, err := request("POST", answersURL(server, questionID), map[string]string{"user_id": fmt.Sprintf("user-%d", i), "text": "Only with a mutex"})
+			if assert.NoError(t, err) {
+				statuses[i] = resp.StatusCode
+			}
+		}(i)
+	}
+	wg.Wait()
+	for _, status := range statuses {
+		assert.Equal(t, http.StatusCreated, status)
+	}
+
+	var question models.Question
+	require.NoError(t, database.GetDB().First(&question, questionID).Error)
+	assert.Equal(t, writers, question.AnswerCount, "no lost update of the denormalized count")
+	assert.Equal(t, writers+1, question.Version)
+}
+
+func TestIntegration_ConcurrentDeletesWithSameVersion(t *testing.T) {
+	server := newTestServer(t)
+	questionID := createQuestion(t, server, "Who wins the race?")
+
+	const deleters = 5
+	var wg sync.WaitGroup
+	statuses := make([]int, deleters)
+	for i := 0; i < deleters; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			resp, _, err := request("DELETE", fmt.Sprintf("%s/v1/questions/%d", server.URL, questionID), nil, "If-Match", `W/"1"`)
+			if assert.NoError(t, err) {
+				statuses[i] = resp.StatusCode
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	deleted := 0
+	for _, status := range statuses {
+		if status == http.StatusNoContent {
+			deleted++
+		} else {
+			assert.Equal(t, http.StatusNotFound, status, "the row lock makes the others see the deletion")
+		}
+	}
+	assert.Equal(t, 1, deleted)
+
+	var events int64
+	require.NoError(t, database.GetDB().Model(&models.OutboxEvent{}).Where("event_type = ?", "question.deleted").Count(&events).Error)
+	assert.Equal(t, int64(1), events)
+}
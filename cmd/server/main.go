@@ -1,86 +1,117 @@
 package main
 
 import (
+	"context"
+	"expvar"
+	"flag"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
+	"os"
 	"qa-api/internal/config"
 	"qa-api/internal/database"
-	"qa-api/internal/handler"
-	"qa-api/internal/repository"
-	"qa-api/internal/service"
-
-	"github.com/gorilla/mux"
-	_ "github.com/lib/pq" // PostgreSQL driver
-	"github.com/pressly/goose/v3"
+	"qa-api/internal/migrate"
+	"qa-api/internal/storage"
+	"qa-api/migrations"
 )
 
 func main() {
 	// Load configuration
-	cfg := config.Load()
-
-	// Initialize database
-	if err := database.Init(cfg); err != nil {
-		log.Fatalf("Failed to initialize database: %v", err)
+	cli := flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+	printConfig := cli.Bool("print-config", false, "print the effective configuration with secrets redacted and exit")
+	cfg, err := config.Load(cli, os.Args[1:])
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
 	}
-
-	// Run migrations
-	if err := runMigrations(cfg.DatabaseURL); err != nil {
-		log.Fatalf("Failed to run migrations: %v", err)
+	if *printConfig {
+		if err := cfg.Dump(os.Stdout); err != nil {
+			log.Fatalf("Failed to print configuration: %v", err)
+		}
+		return
+	}
+	log.Printf("Configuration loaded (env: %s)", cfg.Env)
+
+	// Initialize database and apply or check migrations; the in-memory
+	// storage needs neither
+	if cfg.Storage == storage.SQL {
+		if err := database.Init(cfg); err != nil {
+			log.Fatalf("Failed to initialize database: %v", err)
+		}
+		if err := migrateSchema(cfg, cfg.DB.MigrateMode); err != nil {
+			log.Fatalf("Database schema is not ready: %v", err)
+		}
 	}
 
-	// Initialize repositories
-	questionRepo := repository.NewQuestionRepository()
-	answerRepo := repository.NewAnswerRepository()
-
-	// Initialize services
-	questionService := service.NewQuestionService(questionRepo)
-	answerService := service.NewAnswerService(answerRepo, questionRepo)
-
-	// Initialize handlers
-	questionHandler := handler.NewQuestionHandler(questionService)
-	answerHandler := handler.NewAnswerHandler(answerService)
-
-	// Setup routes
-	router := mux.NewRouter()
-
-	// Question routes
-	router.HandleFunc("/questions/", questionHandler.GetQuestions).Methods("GET")
-	router.HandleFunc("/questions/", questionHandler.CreateQuestion).Methods("POST")
-	router.HandleFunc("/questions/{id}", questionHandler.GetQuestion).Methods("GET")
-	router.HandleFunc("/questions/{id}", questionHandler.DeleteQuestion).Methods("DELETE")
-
-	// Answer routes
-	router.HandleFunc("/questions/{id}/answers/", answerHandler.CreateAnswer).Methods("POST")
-	router.HandleFunc("/answers/{id}", answerHandler.GetAnswer).Methods("GET")
-	router.HandleFunc("/answers/{id}", answerHandler.DeleteAnswer).Methods("DELETE")
+	// Wire the service and start its background workers
+	application, err := newApp(context.Background(), cfg)
+	if err != nil {
+		log.Fatalf("Failed to initialize the service: %v", err)
+	}
+	defer application.Close()
+	for name, stat := range application.stats {
+		expvar.Publish(name, expvar.Func(stat))
+	}
 
-	// Health check
-	router.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusOK)
-		w.Write([]byte("OK"))
-	}).Methods("GET")
+	// Start gRPC server
+	grpcListener, err := net.Listen("tcp", fmt.Sprintf(":%s", cfg.Server.GRPCPort))
+	if err != nil {
+		log.Fatalf("Failed to listen for gRPC: %v", err)
+	}
+	go func() {
+		log.Printf("gRPC server starting on :%s", cfg.Server.GRPCPort)
+		if err := application.grpcServer.Serve(grpcListener); err != nil {
+			log.Fatalf("gRPC server failed: %v", err)
+		}
+	}()
 
 	// Start server
-	addr := fmt.Sprintf(":%s", cfg.Port)
+	addr := fmt.Sprintf(":%s", cfg.Server.Port)
 	log.Printf("Server starting on %s", addr)
-	if err := http.ListenAndServe(addr, router); err != nil {
+	if err := http.ListenAndServe(addr, application.handler); err != nil {
 		log.Fatalf("Server failed to start: %v", err)
 	}
 }
 
-func runMigrations(databaseURL string) error {
-	db, err := goose.OpenDBWithDriver("postgres", databaseURL)
+// migrateSchema applies pending migrations ("auto") or only verifies that
+// none are pending ("check"), so a replica never runs against a schema it
+// does not expect. On PostgreSQL migrations get their own connections
+// without the statement timeout; SQLite has none and shares the pool, which
+// also keeps in-memory databases working.
+func migrateSchema(cfg *config.Config, mode string) error {
+	db, err := database.GetDB().DB()
 	if err != nil {
-		return fmt.Errorf("failed to open database for migrations: %w", err)
+		return err
 	}
-	defer db.Close()
-
-	if err := goose.Up(db, "migrations"); err != nil {
-		return fmt.Errorf("failed to run migrations: %w", err)
+	dialect := database.Dialect()
+	if dialect == config.DialectPostgres {
+		if db, err = database.OpenMigrations(cfg.DB.URL); err != nil {
+			return err
+		}
+		defer db.Close()
+	}
+	migrator, err := migrate.New(db, dialect, migrations.ForDialect(dialect))
+	if err != nil {
+		return err
 	}
 
-	log.Println("Migrations completed successfully")
+	switch mode {
+	case "auto":
+		results, err := migrator.Up(context.Background())
+		for _, result := range results {
+			log.Printf("Applied migration %s in %v", result.Name, result.Duration)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to run migrations: %w", err)
+		}
+		log.Println("Migrations completed successfully")
+	case "check":
+		if err := migrator.Check(context.Background()); err != nil {
+			return err
+		}
+		log.Println("Database schema is up to date")
+	default:
+		return fmt.Errorf("unknown MIGRATE_MODE %q, expected auto or check", mode)
+	}
 	return nil
 }
-
@@ -0,0 +1,117 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+type contextKey struct{}
+
+// ErrUnauthorized is returned when a request carries no valid token
+var ErrUnauthorized = errors.New("unauthorized")
+
+// Authenticator validates static API tokens. Each token identifies a user.
+// When no tokens are configured authentication is disabled and every request
+// is anonymous.
+type Authenticator struct {
+	tokens map[string]string
+}
+
+// NewAuthenticator creates an Authenticator from a "token:user_id,..." list
+func NewAuthenticator(spec string) *Authenticator {
+	tokens := make(map[string]string)
+	for _, pair := range strings.Split(spec, ",") {
+		token, userID, ok := strings.Cut(strings.TrimSpace(pair), ":")
+		if ok && token != "" && userID != "" {
+			tokens[token] = userID
+		}
+	}
+	return &Authenticator{tokens: tokens}
+}
+
+// Enabled reports whether tokens are required
+func (a *Authenticator) Enabled() bool {
+	return len(a.tokens) > 0
+}
+
+// Authenticate returns the user ID for a token
+func (a *Authenticator) Authenticate(token string) (string, error) {
+	userID, ok := a.tokens[token]
+	if !ok {
+		return "", ErrUnauthorized
+	}
+	return userID, nil
+}
+
+// Middleware rejects requests without a valid token when authentication is
+// enabled and stores the user ID in the request context. The token is read
+// from the Authorization header or, for WebSocket clients that cannot set
+// headers, from the access_token query parameter of the upgrade request to
+// /ws. Other routes ignore the parameter, so tokens stay out of access logs
+// and Referer headers.
+func (a *Authenticator) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !a.Enabled() || r.URL.Path == "/health" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		userID, err := a.Authenticate(tokenFromRequest(r))
+		if err != nil {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="qa-api"`)
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(WithUserID(r.Context(), userID)))
+	})
+}
+
+// RequireUsers restricts a handler to the users in a comma-separated list;
+// other users get 403 Forbidden. When authentication is disabled nobody can
+// be identified, so every request is refused unless openWithoutAuth is set
+// for development setups.
+func (a *Authenticator) RequireUsers(userIDs string, openWithoutAuth bool) func(http.Handler) http.Handler {
+	allowed := make(map[string]bool)
+	for _, userID := range strings.Split(userIDs, ",") {
+		if userID = strings.TrimSpace(userID); userID != "" {
+			allowed[userID] = true
+		}
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if a.Enabled() && !allowed[UserID(r.Context())] || !a.Enabled() && !openWithoutAuth {
+				http.Error(w, "Forbidden", http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// WithUserID returns a copy of ctx carrying the authenticated user ID
+func WithUserID(ctx context.Context, userID string) context.Context {
+	return context.WithValue(ctx, contextKey{}, userID)
+}
+
+// UserID returns the authenticated user ID, or an empty string for anonymous requests
+func UserID(ctx context.Context) string {
+	userID, _ := ctx.Value(contextKey{}).(string)
+	return userID
+}
+
+func tokenFromRequest(r *http.Request) string {
+	if header := r.Header.Get("Authorization"); header != "" {
+		if token, ok := strings.CutPrefix(header, "Bearer "); ok {
+			return strings.TrimSpace(token)
+		}
+		return ""
+	}
+	if r.URL.Path != "/ws" || !strings.EqualFold(r.Header.Get("Upgrade"), "websocket") {
+		return ""
+	}
+	return r.URL.Query().Get("access_token")
+}
@@ -0,0 +1,94 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthenticator_Middleware(t *testing.T) {
+	var userID string
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID = UserID(r.Context())
+	})
+
+	t.Run("disabled without tokens", func(t *testing.T) {
+		userID = "unset"
+		w := httptest.NewRecorder()
+		NewAuthenticator("").Middleware(next).ServeHTTP(w, httptest.NewRequest("GET", "/questions/", nil))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "", userID)
+	})
+
+	authenticator := NewAuthenticator("token-a:alice, token-b:bob")
+
+	t.Run("bearer token", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/questions/", nil)
+		req.Header.Set("Authorization", "Bearer token-b")
+		w := httptest.NewRecorder()
+		authenticator.Middleware(next).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "bob", userID)
+	})
+
+	t.Run("query token", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/ws?access_token=token-a", nil)
+		req.Header.Set("Upgrade", "websocket")
+		w := httptest.NewRecorder()
+		authenticator.Middleware(next).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "alice", userID)
+	})
+
+	t.Run("query token outside the WebSocket upgrade", func(t *testing.T) {
+		for _, target := range []string{"/questions/?access_token=token-a", "/ws?access_token=token-a"} {
+			w := httptest.NewRecorder()
+			authenticator.Middleware(next).ServeHTTP(w, httptest.NewRequest("GET", target, nil))
+
+			assert.Equal(t, http.StatusUnauthorized, w.Code, target)
+		}
+	})
+
+	t.Run("invalid token", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/questions/", nil)
+		req.Header.Set("Authorization", "Bearer nope")
+		w := httptest.NewRecorder()
+		authenticator.Middleware(next).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("health check is public", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		authenticator.Middleware(next).ServeHTTP(w, httptest.NewRequest("GET", "/health", nil))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+}
+
+func TestAuthenticator_RequireUsers(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	serve := func(a *Authenticator, userID string) int {
+		req := httptest.NewRequest("POST", "/admin/import", nil)
+		req = req.WithContext(WithUserID(req.Context(), userID))
+		w := httptest.NewRecorder()
+		a.RequireUsers("alice, carol", false)(next).ServeHTTP(w, req)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusForbidden, serve(NewAuthenticator(""), ""), "closed when authentication is disabled")
+
+	w := httptest.NewRecorder()
+	NewAuthenticator("").RequireUsers("alice", true)(next).ServeHTTP(w, httptest.NewRequest("POST", "/admin/import", nil))
+	assert.Equal(t, http.StatusOK, w.Code, "open without authentication on request")
+
+	authenticator := NewAuthenticator("token-a:alice,token-b:bob")
+	assert.Equal(t, http.StatusOK, serve(authenticator, "alice"))
+	assert.Equal(t, http.StatusForbidden, serve(authenticator, "bob"))
+	assert.Equal(t, http.StatusForbidden, serve(authenticator, ""))
+}
@@ -0,0 +1,176 @@
+package badges
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"qa-api/internal/events"
+	"qa-api/internal/models"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad(t *testing.T) {
+	rules, err := Load("")
+	require.NoError(t, err, "the built-in rules are valid")
+	assert.NotEmpty(t, rules)
+
+	dir := t.TempDir()
+	write := func(content string) string {
+		path := filepath.Join(dir, "badges.yaml")
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+		return path
+	}
+	rules, err = Load(write("badges:\n  - name: helper\n    title: Helper\n    metric: answers\n    tiers: [1, 5]\n"))
+	require.NoError(t, err)
+	assert.Equal(t, []Rule{{Name: "helper", Title: "Helper", Metric: "answers", Tiers: []int{1, 5}}}, rules)
+	assert.True(t, rules[0].Tiered())
+
+	for content, want := range map[string]string{
+		"badges:\n  - name: a\n    metric: views\n    tiers: [1]\n":                                                     `metric "views" is unknown`,
+		"badges:\n  - name: a\n    metric: answers\n    tiers: []\n":                                                    "tiers must not be empty",
+		"badges:\n  - name: a\n    metric: answers\n    tiers: [5, 5]\n":                                                "positive and ascending",
+		"badges:\n  - name: a\n    metric: answers\n    tiers: [1]\n  - name: a\n    metric: answers\n    tiers: [2]\n": "not unique",
+		"badges:\n  - metric: answers\n    tiers: [1]\n":                                                                "name must be 1-50 characters",
+		"badges:\n  - name: a\n    metric: answers\n    tiers: [1]\n    level: 2\n":                                     "field level not found",
+	} {
+		_, err := Load(write(content))
+		assert.ErrorContains(t, err, want)
+	}
+	_, err = Load(filepath.Join(dir, "missing.yaml"))
+	assert.Error(t, err)
+}
+
+func TestRule_Reached(t *testing.T) {
+	rule := Rule{Name: "solver", Metric: "accepted_answers", Tiers: []int{10, 50, 100}}
+	assert.Equal(t, 0, rule.Reached(models.Activity{AcceptedAnswers: 9}))
+	assert.Equal(t, 1, rule.Reached(models.Activity{AcceptedAnswers: 10}))
+	assert.Equal(t, 3, rule.Reached(models.Activity{AcceptedAnswers: 500}))
+}
+
+// fakeStore keeps awards like the primary key of badge_awards
+type fakeStore struct {
+	activity map[string]models.Activity
+	awards   map[string][]models.BadgeAward
+}
+
+func newFakeStore() *fakeStore {
+	return &fakeStore{activity: map[string]models.Activity{}, awards: map[string][]models.BadgeAward{}}
+}
+
+func (s *fakeStore) Activity(userID string) (models.Activity, error) {
+	return s.activity[userID], nil
+}
+
+func (s *fakeStore) Awards(userID string) ([]models.BadgeAward, error) {
+	return s.awards[userID], nil
+}
+
+func (s *fakeStore) Award(awards []models.BadgeAward) error {
+	for _, award := range awards {
+		s.awards[award.UserID] = append(s.awards[award.UserID], award)
+	}
+	return nil
+}
+
+func (s *fakeStore) UserIDs(afterID string, limit int) ([]string, error) {
+	var ids []string
+	for id := range s.activity {
+		if id > afterID {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	if len(ids) > limit {
+		ids = ids[:limit]
+	}
+	return ids, nil
+}
+
+// held lists the badges of a user as name:tier
+func (s *fakeStore) held(userID string) []string {
+	var result []string
+	for _, award := range s.awards[userID] {
+		result = append(result, fmt.Sprintf("%s:%d", award.Badge, award.Tier))
+	}
+	sort.Strings(result)
+	return result
+}
+
+var testRules = []Rule{
+	{Name: "first_answer", Metric: "answers", Tiers: []int{1}},
+	{Name: "solver", Metric: "accepted_answers", Tiers: []int{1, 3}},
+	{Name: "popular_question", Metric: "question_views", Tiers: []int{100}},
+}
+
+func TestEngine_Evaluate(t *testing.T) {
+	store := newFakeStore()
+	engine := NewEngine(testRules, store)
+
+	store.activity["bob"] = models.Activity{Answers: 1, AcceptedAnswers: 1}
+	awards, err := engine.Evaluate("bob")
+	require.NoError(t, err)
+	assert.Len(t, awards, 2)
+	assert.Equal(t, []string{"first_answer:1", "solver:1"}, store.held("bob"))
+
+	awards, err = engine.Evaluate("bob")
+	require.NoError(t, err)
+	assert.Empty(t, awards, "badges are awarded once")
+
+	store.activity["bob"] = models.Activity{Answers: 5, AcceptedAnswers: 4}
+	awards, err = engine.Evaluate("bob")
+	require.NoError(t, err)
+	assert.Equal(t, []models.BadgeAward{{UserID: "bob", Badge: "solver", Tier: 2, AwardedAt: awards[0].AwardedAt}}, awards,
+		"tiered badges are awarded once per tier")
+
+	store.activity["bob"] = models.Activity{}
+	awards, err = engine.Evaluate("bob")
+	require.NoError(t, err)
+	assert.Empty(t, awards)
+	assert.Len(t, store.held("bob"), 3, "badges are never taken back")
+}
+
+func event(t *testing.T, eventType string, payload interface{}) events.Event {
+	t.Helper()
+	data, err := json.Marshal(payload)
+	require.NoError(t, err)
+	return events.Event{ID: 1, Type: eventType, Payload: data}
+}
+
+func TestEngine_Handle(t *testing.T) {
+	store := newFakeStore()
+	engine := NewEngine(testRules, store)
+	store.activity["alice"] = models.Activity{QuestionViews: 100}
+	store.activity["bob"] = models.Activity{Answers: 1, AcceptedAnswers: 1}
+
+	require.NoError(t, engine.Handle(context.Background(), event(t, events.AnswerCreated, events.AnswerPayload{UserID: "carol"})))
+	require.NoError(t, engine.Handle(context.Background(), event(t, events.QuestionDeleted, events.QuestionPayload{UserID: "alice"})))
+	assert.Empty(t, store.awards, "deletions award nothing")
+
+	require.NoError(t, engine.Handle(context.Background(), event(t, events.QuestionViewed, events.ViewPayload{UserID: "alice", ViewCount: 100})))
+	assert.Equal(t, []string{"popular_question:1"}, store.held("alice"))
+	require.NoError(t, engine.Handle(context.Background(), event(t, events.AnswerAccepted, events.AcceptPayload{UserID: "bob", AcceptedBy: "alice"})))
+	assert.Equal(t, []string{"first_answer:1", "solver:1"}, store.held("bob"))
+
+	assert.Error(t, engine.Handle(context.Background(), events.Event{Type: events.VoteCast, Payload: json.RawMessage(`[]`)}))
+}
+
+func TestEngine_Backfill(t *testing.T) {
+	store := newFakeStore()
+	for i := 0; i < backfillBatch+5; i++ {
+		store.activity[fmt.Sprintf("user-%04d", i)] = models.Activity{Answers: 1}
+	}
+	engine := NewEngine(testRules, store)
+
+	awarded, err := engine.Backfill(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, backfillBatch+5, awarded, "every batch is evaluated")
+	awarded, err = engine.Backfill(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, awarded)
+}
@@ -0,0 +1,156 @@
+package badges
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"qa-api/internal/events"
+	"qa-api/internal/models"
+	"time"
+)
+
+// Store provides the activity of users and keeps their badges. Award must
+// ignore badges the user already holds, since events arrive at least once
+// and evaluations may run concurrently.
+type Store interface {
+	Activity(userID string) (models.Activity, error)
+	Awards(userID string) ([]models.BadgeAward, error)
+	Award(awards []models.BadgeAward) error
+	// UserIDs returns up to limit user IDs greater than afterID, ordered
+	UserIDs(afterID string, limit int) ([]string, error)
+}
+
+// backfillBatch is how many users Backfill reads at a time
+const backfillBatch = 500
+
+// Engine awards the badges whose rules the activity of a user satisfies
+type Engine struct {
+	rules []Rule
+	store Store
+}
+
+// NewEngine creates a new Engine
+func NewEngine(rules []Rule, store Store) *Engine {
+	return &Engine{rules: rules, store: store}
+}
+
+// Rules returns the rules the engine evaluates
+func (e *Engine) Rules() []Rule {
+	return e.rules
+}
+
+// Evaluate awards the badges a user has earned and not received yet and
+// returns them
+func (e *Engine) Evaluate(userID string) ([]models.BadgeAward, error) {
+	activity, err := e.store.Activity(userID)
+	if err != nil {
+		return nil, err
+	}
+	held, err := e.store.Awards(userID)
+	if err != nil {
+		return nil, err
+	}
+	type tier struct {
+		badge string
+		tier  int
+	}
+	holds := make(map[tier]bool, len(held))
+	for _, award := range held {
+		holds[tier{award.Badge, award.Tier}] = true
+	}
+
+	now := time.Now()
+	awards := []models.BadgeAward{}
+	for _, rule := range e.rules {
+		for t := 1; t <= rule.Reached(activity); t++ {
+			if !holds[tier{rule.Name, t}] {
+				awards = append(awards, models.BadgeAward{UserID: userID, Badge: rule.Name, Tier: t, AwardedAt: now})
+			}
+		}
+	}
+	if len(awards) == 0 {
+		return awards, nil
+	}
+	return awards, e.store.Award(awards)
+}
+
+// Handle evaluates the users involved in an event; it is an events.Handler
+func (e *Engine) Handle(ctx context.Context, event events.Event) error {
+	userIDs, err := involved(event)
+	if err != nil {
+		return err
+	}
+	for _, userID := range userIDs {
+		if _, err := e.Evaluate(userID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Backfill evaluates every user, e.g. for activity from before the badges
+// or a rule was introduced, and returns the number of badges awarded
+func (e *Engine) Backfill(ctx context.Context) (int, error) {
+	awarded := 0
+	afterID := ""
+	for {
+		userIDs, err := e.store.UserIDs(afterID, backfillBatch)
+		if err != nil {
+			return awarded, err
+		}
+		for _, userID := range userIDs {
+			awards, err := e.Evaluate(userID)
+			if err != nil {
+				return awarded, err
+			}
+			awarded += len(awards)
+			afterID = userID
+		}
+		if len(userIDs) < backfillBatch {
+			return awarded, nil
+		}
+		if err := ctx.Err(); err != nil {
+			return awarded, err
+		}
+	}
+}
+
+// involved returns the users whose activity an event changes. Deletions
+// change nothing: badges are never taken back.
+func involved(event events.Event) ([]string, error) {
+	var users []string
+	var err error
+	switch event.Type {
+	case events.QuestionCreated:
+		var payload events.QuestionPayload
+		err = json.Unmarshal(event.Payload, &payload)
+		users = []string{payload.UserID}
+	case events.QuestionViewed:
+		var payload events.ViewPayload
+		err = json.Unmarshal(event.Payload, &payload)
+		users = []string{payload.UserID}
+	case events.AnswerCreated:
+		var payload events.AnswerPayload
+		err = json.Unmarshal(event.Payload, &payload)
+		users = []string{payload.UserID}
+	case events.AnswerAccepted:
+		var payload events.AcceptPayload
+		err = json.Unmarshal(event.Payload, &payload)
+		users = []string{payload.UserID, payload.AcceptedBy}
+	case events.VoteCast:
+		var payload events.VotePayload
+		err = json.Unmarshal(event.Payload, &payload)
+		users = []string{payload.UserID, payload.VoterID}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s payload: %w", event.Type, err)
+	}
+
+	result := make([]string, 0, len(users))
+	for _, userID := range users {
+		if userID != "" {
+			result = append(result, userID)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,118 @@
+// Package badges awards badges to users. The rules are declared in YAML
+// (badges.yaml holds the built-in ones) and evaluated against the activity
+// of the users involved in each domain event.
+package badges
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"io"
+	"os"
+	"qa-api/internal/models"
+
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed badges.yaml
+var builtin []byte
+
+// Metrics maps the metric names usable in rules to their value
+var Metrics = map[string]func(models.Activity) int{
+	"questions":        func(a models.Activity) int { return a.Questions },
+	"answers":          func(a models.Activity) int { return a.Answers },
+	"accepted_answers": func(a models.Activity) int { return a.AcceptedAnswers },
+	"votes_cast":       func(a models.Activity) int { return a.VotesCast },
+	"question_views":   func(a models.Activity) int { return a.QuestionViews },
+	"answer_score":     func(a models.Activity) int { return a.AnswerScore },
+	"reputation":       func(a models.Activity) int { return a.Reputation },
+}
+
+// Rule declares a badge: it is awarded when Metric reaches a tier, once per
+// tier. Tiers are ascending thresholds; most badges have a single one.
+type Rule struct {
+	Name        string `yaml:"name" json:"name"`
+	Title       string `yaml:"title" json:"title"`
+	Description string `yaml:"description" json:"description"`
+	Metric      string `yaml:"metric" json:"metric"`
+	Tiers       []int  `yaml:"tiers" json:"tiers"`
+}
+
+// Tiered reports whether the badge can be awarded more than once
+func (r Rule) Tiered() bool {
+	return len(r.Tiers) > 1
+}
+
+// Reached returns how many tiers of the rule the activity reaches
+func (r Rule) Reached(activity models.Activity) int {
+	value := Metrics[r.Metric](activity)
+	reached := 0
+	for _, threshold := range r.Tiers {
+		if value >= threshold {
+			reached++
+		}
+	}
+	return reached
+}
+
+// Thresholds returns the tiers of the rules on metric
+func Thresholds(rules []Rule, metric string) []int {
+	var thresholds []int
+	for _, rule := range rules {
+		if rule.Metric == metric {
+			thresholds = append(thresholds, rule.Tiers...)
+		}
+	}
+	return thresholds
+}
+
+// Load reads the rules from the YAML file at path, or the built-in ones when
+// path is empty
+func Load(path string) ([]Rule, error) {
+	data := builtin
+	source := "built-in badge rules"
+	if path != "" {
+		var err error
+		if data, err = os.ReadFile(path); err != nil {
+			return nil, fmt.Errorf("failed to read badge rules: %w", err)
+		}
+		source = "badge rules file " + path
+	}
+	rules, err := parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", source, err)
+	}
+	return rules, nil
+}
+
+func parse(data []byte) ([]Rule, error) {
+	var parsed struct {
+		Badges []Rule `yaml:"badges"`
+	}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&parsed); err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	for i, rule := range parsed.Badges {
+		switch {
+		case rule.Name == "" || len(rule.Name) > 50:
+			return nil, fmt.Errorf("badges[%d].name must be 1-50 characters", i)
+		case seen[rule.Name]:
+			return nil, fmt.Errorf("badges[%d].name %q is not unique", i, rule.Name)
+		case Metrics[rule.Metric] == nil:
+			return nil, fmt.Errorf("badges.%s.metric %q is unknown", rule.Name, rule.Metric)
+		case len(rule.Tiers) == 0:
+			return nil, fmt.Errorf("badges.%s.tiers must not be empty", rule.Name)
+		}
+		for j, threshold := range rule.Tiers {
+			if threshold < 1 || (j > 0 && threshold <= rule.Tiers[j-1]) {
+				return nil, fmt.Errorf("badges.%s.tiers must be positive and ascending", rule.Name)
+			}
+		}
+		seen[rule.Name] = true
+	}
+	return parsed.Badges, nil
+}
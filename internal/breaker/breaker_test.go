@@ -0,0 +1,77 @@
+package breaker
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestBreaker(threshold int, cooldown time.Duration) (*Breaker, *time.Time) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	b := New(threshold, cooldown)
+	b.now = func() time.Time { return now }
+	return b, &now
+}
+
+func TestBreaker_OpensAfterConsecutiveFailures(t *testing.T) {
+	b, _ := newTestBreaker(3, time.Second)
+
+	b.Failure()
+	b.Failure()
+	b.Success()
+	b.Failure()
+	b.Failure()
+	assert.Equal(t, Closed, b.State(), "a success resets the failure count")
+	assert.NoError(t, b.Allow())
+
+	b.Failure()
+	assert.Equal(t, Open, b.State())
+	assert.ErrorIs(t, b.Allow(), ErrOpen)
+	assert.Equal(t, time.Second, b.RetryAfter())
+}
+
+func TestBreaker_ProbesAfterCooldown(t *testing.T) {
+	b, now := newTestBreaker(1, time.Second)
+	var transitions []string
+	b.OnChange = func(from, to State) { transitions = append(transitions, from.String()+">"+to.String()) }
+
+	b.Failure()
+	*now = now.Add(time.Second)
+
+	assert.NoError(t, b.Allow(), "the first call after the cooldown probes")
+	assert.Equal(t, HalfOpen, b.State())
+	assert.ErrorIs(t, b.Allow(), ErrOpen, "only one probe at a time")
+
+	b.Failure()
+	assert.Equal(t, Open, b.State(), "a failed probe reopens")
+	assert.Equal(t, time.Second, b.RetryAfter())
+
+	*now = now.Add(time.Second)
+	assert.NoError(t, b.Allow())
+	b.Success()
+	assert.Equal(t, Closed, b.State())
+	assert.Equal(t, []string{"closed>open", "open>half-open", "half-open>open", "open>half-open", "half-open>closed"}, transitions)
+}
+
+func TestBreaker_Middleware(t *testing.T) {
+	b, _ := newTestBreaker(1, 10*time.Second)
+	handler := b.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	serve := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		return w
+	}
+
+	assert.Equal(t, http.StatusOK, serve("/questions/").Code)
+
+	b.Failure()
+	w := serve("/questions/")
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "10", w.Header().Get("Retry-After"))
+	assert.Equal(t, http.StatusOK, serve("/health").Code, "the health check does not need the database")
+}
@@ -0,0 +1,57 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLRU_EvictsLeastRecentlyUsed(t *testing.T) {
+	c := NewLRU[int, string](2, time.Minute)
+	c.Set(1, "one")
+	c.Set(2, "two")
+	c.Get(1)
+	c.Set(3, "three")
+
+	_, ok := c.Get(2)
+	assert.False(t, ok, "2 was least recently used")
+	value, ok := c.Get(1)
+	assert.True(t, ok)
+	assert.Equal(t, "one", value)
+
+	stats := c.Stats()
+	assert.Equal(t, int64(1), stats.Evictions)
+	assert.Equal(t, int64(2), stats.Hits)
+	assert.Equal(t, int64(1), stats.Misses)
+	assert.Equal(t, 2, stats.Size)
+}
+
+func TestLRU_ExpiresAfterTTL(t *testing.T) {
+	now := time.Now()
+	c := NewLRU[int, string](10, time.Second)
+	c.now = func() time.Time { return now }
+
+	c.Set(1, "one")
+	_, ok := c.Get(1)
+	assert.True(t, ok)
+
+	now = now.Add(2 * time.Second)
+	_, ok = c.Get(1)
+	assert.False(t, ok)
+	assert.Equal(t, 0, c.Stats().Size)
+}
+
+func TestLRU_DeleteAndPurge(t *testing.T) {
+	c := NewLRU[int, string](10, time.Minute)
+	c.Set(1, "one")
+	c.Set(2, "two")
+
+	c.Delete(1)
+	_, ok := c.Get(1)
+	assert.False(t, ok)
+
+	c.Purge()
+	_, ok = c.Get(2)
+	assert.False(t, ok)
+}
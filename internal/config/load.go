@@ -0,0 +1,324 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// redacted replaces secrets in dumps
+const redacted = "[REDACTED]"
+
+// setting is a leaf of the configuration tree, described by its struct tags
+type setting struct {
+	key        string // dotted path in the config file and flag name
+	env        string
+	def        string
+	secret     bool
+	requiredIn string
+	help       string
+	value      reflect.Value
+}
+
+// settings lists the leaves of cfg in declaration order
+func settings(cfg *Config) []setting {
+	var list []setting
+	var walk func(prefix string, v reflect.Value)
+	walk = func(prefix string, v reflect.Value) {
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			key := prefix + field.Tag.Get("config")
+			if field.Type.Kind() == reflect.Struct {
+				walk(key+".", v.Field(i))
+				continue
+			}
+			list = append(list, setting{
+				key:        key,
+				env:        field.Tag.Get("env"),
+				def:        field.Tag.Get("default"),
+				secret:     field.Tag.Get("secret") == "true",
+				requiredIn: field.Tag.Get("required"),
+				help:       field.Tag.Get("help"),
+				value:      v.Field(i),
+			})
+		}
+	}
+	walk("", reflect.ValueOf(cfg).Elem())
+	return list
+}
+
+// set parses raw into the setting according to its type
+func (s setting) set(raw string) error {
+	switch s.value.Interface().(type) {
+	case string:
+		s.value.SetString(raw)
+	case int:
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return fmt.Errorf("must be an integer, got %q", raw)
+		}
+		s.value.SetInt(int64(n))
+	case bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("must be true or false, got %q", raw)
+		}
+		s.value.SetBool(b)
+	case time.Duration:
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return fmt.Errorf("must be a duration like 30s or 5m, got %q", raw)
+		}
+		s.value.SetInt(int64(d))
+	default:
+		return fmt.Errorf("unsupported type %s", s.value.Type())
+	}
+	return nil
+}
+
+// dumpValue returns the value for dumps, with secrets redacted. Passwords
+// in URLs are masked so the rest of the address stays visible.
+func (s setting) dumpValue() interface{} {
+	value := s.value.Interface()
+	if d, ok := value.(time.Duration); ok {
+		return d.String()
+	}
+	if !s.secret || s.value.String() == "" {
+		return value
+	}
+
+	parts := strings.Split(s.value.String(), ",")
+	for i, part := range parts {
+		if u, err := url.Parse(strings.TrimSpace(part)); err == nil && u.User != nil && u.Host != "" {
+			parts[i] = u.Redacted()
+		} else {
+			parts[i] = redacted
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// Load builds the configuration in layers, each overriding the previous one:
+// the defaults, the config file, environment variables and command-line
+// flags. The file is named by -config or CONFIG_FILE; YAML and TOML are
+// supported. Any variable VAR can instead be read from the file named by
+// VAR_FILE, for secrets mounted as files. Secrets have no flags, since the
+// command line is visible to other processes. In production every setting
+// marked required must be set explicitly. When flags is nil no flags are
+// parsed.
+func Load(flags *flag.FlagSet, args []string) (*Config, error) {
+	cfg := &Config{}
+	list := settings(cfg)
+	for _, s := range list {
+		if s.def != "" {
+			if err := s.set(s.def); err != nil {
+				return nil, fmt.Errorf("default of %s: %w", s.key, err)
+			}
+		}
+	}
+
+	configFile := os.Getenv("CONFIG_FILE")
+	flagValues := make(map[string]string)
+	if flags != nil {
+		flags.StringVar(&configFile, "config", configFile, "read settings from this YAML or TOML file (CONFIG_FILE)")
+		for _, s := range list {
+			if s.secret {
+				continue
+			}
+			key := s.key
+			usage := s.help
+			if s.env != "" {
+				usage += " (" + s.env + ")"
+			}
+			flags.Func(key, usage, func(value string) error {
+				flagValues[key] = value
+				return nil
+			})
+		}
+		if err := flags.Parse(args); err != nil {
+			return nil, err
+		}
+	}
+
+	var errs []string
+	explicit := make(map[string]bool)
+	apply := func(s setting, raw, source string) {
+		if err := s.set(raw); err != nil {
+			errs = append(errs, fmt.Sprintf("%s (%s): %v", s.key, source, err))
+			return
+		}
+		explicit[s.key] = true
+	}
+
+	if configFile != "" {
+		fileValues, err := readFile(configFile)
+		if err != nil {
+			return nil, err
+		}
+		known := make(map[string]bool)
+		for _, s := range list {
+			known[s.key] = true
+			if raw, ok := fileValues[s.key]; ok {
+				apply(s, raw, configFile)
+			}
+		}
+		for _, key := range sortedKeys(fileValues) {
+			if !known[key] {
+				errs = append(errs, fmt.Sprintf("%s (%s): unknown setting", key, configFile))
+			}
+		}
+	}
+
+	for _, s := range list {
+		if s.env == "" {
+			continue
+		}
+		raw, source, err := lookupEnv(s.env)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", s.key, err))
+		} else if source != "" {
+			apply(s, raw, source)
+		}
+	}
+
+	for _, s := range list {
+		if raw, ok := flagValues[s.key]; ok {
+			apply(s, raw, "-"+s.key)
+		}
+	}
+	if err := errorList(errs); err != nil {
+		return nil, err
+	}
+
+	if cfg.Env == EnvProduction {
+		for _, s := range list {
+			if s.requiredIn == EnvProduction && !explicit[s.key] {
+				errs = append(errs, fmt.Sprintf("%s: must be set in production (%s, %s_FILE, the config file or -%s)", s.key, s.env, s.env, s.key))
+			}
+		}
+		if err := errorList(errs); err != nil {
+			return nil, err
+		}
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// lookupEnv reads a variable or, through VAR_FILE, the file holding it. An
+// empty variable counts as unset. It returns the source for error messages.
+func lookupEnv(name string) (string, string, error) {
+	value := os.Getenv(name)
+	file := os.Getenv(name + "_FILE")
+	switch {
+	case value != "" && file != "":
+		return "", "", fmt.Errorf("both %s and %s_FILE are set", name, name)
+	case file != "":
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return "", "", fmt.Errorf("%s_FILE: %w", name, err)
+		}
+		return strings.TrimRight(string(data), "\r\n"), name + "_FILE", nil
+	case value != "":
+		return value, name, nil
+	default:
+		return "", "", nil
+	}
+}
+
+// readFile reads a YAML or TOML config file, chosen by extension, into
+// dotted keys and their values as text
+func readFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	tree := make(map[string]interface{})
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".toml":
+		err = toml.Unmarshal(data, &tree)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &tree)
+	default:
+		return nil, fmt.Errorf("config file %s: unsupported format, use .yaml, .yml or .toml", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("config file %s: %w", path, err)
+	}
+
+	values := make(map[string]string)
+	var flatten func(prefix string, node map[string]interface{})
+	flatten = func(prefix string, node map[string]interface{}) {
+		for key, value := range node {
+			switch value := value.(type) {
+			case map[string]interface{}:
+				flatten(prefix+key+".", value)
+			case []interface{}:
+				// lists stand for comma-separated settings
+				items := make([]string, len(value))
+				for i, item := range value {
+					items[i] = fmt.Sprint(item)
+				}
+				values[prefix+key] = strings.Join(items, ",")
+			case nil:
+			default:
+				values[prefix+key] = fmt.Sprint(value)
+			}
+		}
+	}
+	flatten("", tree)
+	return values, nil
+}
+
+// Redacted returns the effective configuration as nested maps keyed like the
+// config file, with secrets redacted
+func (c *Config) Redacted() map[string]interface{} {
+	root := make(map[string]interface{})
+	for _, s := range settings(c) {
+		node := root
+		path := strings.Split(s.key, ".")
+		for _, part := range path[:len(path)-1] {
+			child, ok := node[part].(map[string]interface{})
+			if !ok {
+				child = make(map[string]interface{})
+				node[part] = child
+			}
+			node = child
+		}
+		node[path[len(path)-1]] = s.dumpValue()
+	}
+	return root
+}
+
+// Dump writes the effective configuration as YAML with secrets redacted
+func (c *Config) Dump(w io.Writer) error {
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(c.Redacted()); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -1,41 +1,55 @@
 package database
 
 import (
+	"context"
 	"fmt"
 	"log"
-	"time"
+	"qa-api/internal/breaker"
 	"qa-api/internal/config"
-	"qa-api/internal/models"
+	"time"
 
-	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 )
 
 var DB *gorm.DB
 
+// Replicas routes reads to the read replicas; nil when none are configured
+var Replicas *ReplicaSet
+
+// Breaker fails statements fast while the primary is unreachable
+var Breaker *breaker.Breaker
+
 // Init initializes the database connection with retry logic
 func Init(cfg *config.Config) error {
 	var err error
-	maxRetries := 10
-	retryDelay := 2 * time.Second
+	maxRetries := cfg.DB.Retry.Attempts
+	if maxRetries < 1 {
+		maxRetries = 1
+	}
+	backoff := Backoff{Initial: cfg.DB.Retry.InitialDelay, Max: cfg.DB.Retry.MaxDelay}
 
 	for i := 0; i < maxRetries; i++ {
-		DB, err = gorm.Open(postgres.Open(cfg.DatabaseURL), &gorm.Config{
-			Logger: logger.Default.LogMode(logger.Info),
-		})
+		gormConfig := &gorm.Config{
+			Logger: logger.Default.LogMode(logLevel(cfg.DB.LogLevel)),
+		}
+		if cfg.DB.Dialect() == config.DialectSQLite {
+			DB, err = openSQLite(cfg.DB.URL, cfg, gormConfig)
+		} else {
+			DB, err = openPostgres(cfg.DB.URL, cfg, gormConfig)
+		}
 		if err == nil {
-			// Auto-migrate models (as a fallback, but we use goose migrations)
-			if err := DB.AutoMigrate(&models.Question{}, &models.Answer{}); err != nil {
-				log.Printf("Warning: auto-migrate failed: %v", err)
-			}
-
+			// The schema is managed by the goose migrations only (see package migrate)
 			log.Println("Database connection established")
-			return nil
+			if err := initBreaker(cfg, backoff); err != nil {
+				return err
+			}
+			return initReplicas(cfg)
 		}
 
 		if i < maxRetries-1 {
-			log.Printf("Failed to connect to database (attempt %d/%d): %v. Retrying in %v...", i+1, maxRetries, err, retryDelay)
+			retryDelay := backoff.Delay(i)
+			log.Printf("Failed to connect to database (attempt %d/%d): %v. Retrying in %v...", i+1, maxRetries, err, retryDelay.Round(time.Millisecond))
 			time.Sleep(retryDelay)
 		}
 	}
@@ -43,11 +57,94 @@ func Init(cfg *config.Config) error {
 	return fmt.Errorf("failed to connect to database after %d attempts: %w", maxRetries, err)
 }
 
+// initBreaker guards the primary with a circuit breaker and reconnects in the
+// background whenever it opens
+func initBreaker(cfg *config.Config, backoff Backoff) error {
+	opened := make(chan struct{}, 1)
+	Breaker = breaker.New(cfg.DB.Breaker.Threshold, cfg.DB.Breaker.Cooldown)
+	Breaker.OnChange = func(from, to breaker.State) {
+		log.Printf("Database circuit breaker %s -> %s", from, to)
+		if to == breaker.Open {
+			select {
+			case opened <- struct{}{}:
+			default:
+			}
+		}
+	}
+	if err := registerBreaker(DB, Breaker); err != nil {
+		return err
+	}
+
+	sqlDB, err := DB.DB()
+	if err != nil {
+		return err
+	}
+	go reconnect(context.Background(), sqlDB, Breaker, opened, backoff)
+	return nil
+}
+
+// logLevel maps DB_LOG_LEVEL to a GORM log level; SQL is logged at "info"
+func logLevel(level string) logger.LogLevel {
+	switch level {
+	case "silent":
+		return logger.Silent
+	case "error":
+		return logger.Error
+	case "warn":
+		return logger.Warn
+	default:
+		return logger.Info
+	}
+}
+
+// initReplicas opens the read replicas and checks them once, so reads can
+// use the healthy ones right away
+func initReplicas(cfg *config.Config) error {
+	Replicas = nil
+	if cfg.DB.Replicas.URLs == "" {
+		return nil
+	}
+	replicas, err := openReplicas(cfg)
+	if err != nil {
+		return err
+	}
+	Replicas = newReplicaSet(DB, replicas, cfg.DB.Replicas.MaxLag, cfg.DB.Replicas.StickyWindow)
+	Replicas.Check(context.Background())
+	log.Printf("Routing reads to %d read replicas", len(replicas))
+	return nil
+}
+
 // GetDB returns the database instance
 func GetDB() *gorm.DB {
 	return DB
 }
 
+// Dialect returns the SQL dialect of the database, config.DialectPostgres
+// or config.DialectSQLite
+func Dialect() string {
+	if DB != nil && DB.Dialector.Name() == "sqlite" {
+		return config.DialectSQLite
+	}
+	return config.DialectPostgres
+}
+
+// Reader returns the connection for a read of the given questions: a healthy
+// replica, or the primary if there is none or the questions were just written
+func Reader(questionIDs ...int) *gorm.DB {
+	if Replicas == nil {
+		return DB
+	}
+	return Replicas.Reader(questionIDs...)
+}
+
+// MarkWritten keeps reads of the questions on the primary for the sticky
+// window; without IDs it applies to every question
+func MarkWritten(questionIDs ...int) {
+	if Replicas != nil {
+		Replicas.MarkWritten(questionIDs...)
+	}
+}
+
 
 
 
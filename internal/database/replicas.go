@@ -0,0 +1,248 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+	"qa-api/internal/config"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// replicaLagQuery reports how far a replica's replay is behind the primary.
+// A replica that has replayed everything it received is not lagging, even if
+// the primary has been idle for a while; the primary itself reports 0.
+const replicaLagQuery = `SELECT CASE
+	WHEN NOT pg_is_in_recovery() OR pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0
+	ELSE COALESCE(EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp()), 0)
+END`
+
+// ReplicaStatus is the last observed state of a read replica
+type ReplicaStatus struct {
+	Name       string    `json:"name"`
+	Healthy    bool      `json:"healthy"`
+	LagSeconds float64   `json:"lag_seconds"`
+	Error      string    `json:"error,omitempty"`
+	CheckedAt  time.Time `json:"checked_at"`
+}
+
+// replica is a read-only copy of the primary database
+type replica struct {
+	db *gorm.DB
+
+	mu     sync.Mutex
+	status ReplicaStatus
+}
+
+// ReplicaSet routes reads to healthy replicas and everything else to the
+// primary. Questions written within the sticky window are read from the
+// primary, so a client always sees its own writes despite replication lag.
+type ReplicaSet struct {
+	primary  *gorm.DB
+	replicas []*replica
+	maxLag   time.Duration
+	sticky   *stickiness
+	next     atomic.Uint64
+	check    func(ctx context.Context, db *gorm.DB) (time.Duration, error)
+}
+
+// newReplicaSet creates a ReplicaSet; replicas start unhealthy until checked
+func newReplicaSet(primary *gorm.DB, replicas []*replica, maxLag, stickyWindow time.Duration) *ReplicaSet {
+	return &ReplicaSet{
+		primary:  primary,
+		replicas: replicas,
+		maxLag:   maxLag,
+		sticky:   newStickiness(stickyWindow),
+		check:    replicaLag,
+	}
+}
+
+// Reader returns the connection for a read of the given questions; a read
+// without question IDs (a listing) sticks to the primary after any write
+func (s *ReplicaSet) Reader(questionIDs ...int) *gorm.DB {
+	if len(s.replicas) == 0 || s.sticky.sticky(questionIDs, time.Now()) {
+		return s.primary
+	}
+
+	start := s.next.Add(1)
+	for i := range s.replicas {
+		r := s.replicas[(start+uint64(i))%uint64(len(s.replicas))]
+		r.mu.Lock()
+		healthy := r.status.Healthy
+		r.mu.Unlock()
+		if healthy {
+			return r.db
+		}
+	}
+	return s.primary
+}
+
+// MarkWritten sends reads of the questions to the primary for the sticky
+// window; without IDs every question is treated as written
+func (s *ReplicaSet) MarkWritten(questionIDs ...int) {
+	s.sticky.mark(questionIDs, time.Now())
+}
+
+// Check probes every replica once, updating its health and lag
+func (s *ReplicaSet) Check(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, r := range s.replicas {
+		wg.Add(1)
+		go func(r *replica) {
+			defer wg.Done()
+			checkCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+			defer cancel()
+			lag, err := s.check(checkCtx, r.db)
+
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			wasHealthy := r.status.Healthy
+			r.status.CheckedAt = time.Now()
+			r.status.Error = ""
+			switch {
+			case err != nil:
+				r.status.Healthy = false
+				r.status.Error = err.Error()
+			case lag > s.maxLag:
+				r.status.Healthy = false
+				r.status.LagSeconds = lag.Seconds()
+				r.status.Error = fmt.Sprintf("lag %v exceeds %v", lag.Round(time.Millisecond), s.maxLag)
+			default:
+				r.status.Healthy = true
+				r.status.LagSeconds = lag.Seconds()
+			}
+			if wasHealthy != r.status.Healthy {
+				if r.status.Healthy {
+					log.Printf("Read replica %s is healthy, routing reads to it", r.status.Name)
+				} else {
+					log.Printf("Read replica %s is unhealthy, falling back to the primary: %s", r.status.Name, r.status.Error)
+				}
+			}
+		}(r)
+	}
+	wg.Wait()
+}
+
+// Monitor checks the replicas every interval until ctx is cancelled
+func (s *ReplicaSet) Monitor(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.Check(ctx)
+		}
+	}
+}
+
+// Stats returns the status of every replica
+func (s *ReplicaSet) Stats() []ReplicaStatus {
+	stats := make([]ReplicaStatus, 0, len(s.replicas))
+	for _, r := range s.replicas {
+		r.mu.Lock()
+		stats = append(stats, r.status)
+		r.mu.Unlock()
+	}
+	return stats
+}
+
+// replicaLag pings a replica and measures its replication lag
+func replicaLag(ctx context.Context, db *gorm.DB) (time.Duration, error) {
+	var seconds float64
+	if err := db.WithContext(ctx).Raw(replicaLagQuery).Scan(&seconds).Error; err != nil {
+		return 0, err
+	}
+	return time.Duration(seconds * float64(time.Second)), nil
+}
+
+// openReplicas connects to the comma-separated replica URLs with the same
+// pool settings as the primary. Connections are opened lazily, so a replica
+// that is down does not block startup.
+func openReplicas(cfg *config.Config) ([]*replica, error) {
+	var replicas []*replica
+	for i, dsn := range strings.Split(cfg.DB.Replicas.URLs, ",") {
+		dsn = strings.TrimSpace(dsn)
+		if dsn == "" {
+			continue
+		}
+		db, err := openPostgres(dsn, cfg, &gorm.Config{
+			Logger:               logger.Default.LogMode(logLevel(cfg.DB.LogLevel)),
+			DisableAutomaticPing: true,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to open read replica %d: %w", i+1, err)
+		}
+		replicas = append(replicas, &replica{db: db, status: ReplicaStatus{Name: replicaName(dsn, i)}})
+	}
+	return replicas, nil
+}
+
+// replicaName identifies a replica in logs and metrics without its credentials
+func replicaName(dsn string, index int) string {
+	if u, err := url.Parse(dsn); err == nil && u.Host != "" {
+		return u.Host + u.Path
+	}
+	return fmt.Sprintf("replica-%d", index+1)
+}
+
+// stickiness remembers recent writes for the read-your-writes window
+type stickiness struct {
+	window time.Duration
+
+	mu        sync.Mutex
+	anyUntil  time.Time // a question was written: listings stick
+	allUntil  time.Time // a write not tied to particular questions: everything sticks
+	questions map[int]time.Time
+}
+
+func newStickiness(window time.Duration) *stickiness {
+	return &stickiness{window: window, questions: make(map[int]time.Time)}
+}
+
+func (s *stickiness) mark(questionIDs []int, now time.Time) {
+	if s.window <= 0 {
+		return
+	}
+	until := now.Add(s.window)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.anyUntil = until
+	if len(questionIDs) == 0 {
+		s.allUntil = until
+		return
+	}
+	for id, expires := range s.questions {
+		if !now.Before(expires) {
+			delete(s.questions, id)
+		}
+	}
+	for _, id := range questionIDs {
+		s.questions[id] = until
+	}
+}
+
+func (s *stickiness) sticky(questionIDs []int, now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if now.Before(s.allUntil) {
+		return true
+	}
+	if len(questionIDs) == 0 {
+		return now.Before(s.anyUntil)
+	}
+	for _, id := range questionIDs {
+		if now.Before(s.questions[id]) {
+			return true
+		}
+	}
+	return false
+}
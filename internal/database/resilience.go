@@ -0,0 +1,227 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"net"
+	"qa-api/internal/breaker"
+	"qa-api/internal/config"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/stdlib"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// ErrUnavailable is returned without touching the database while the
+// circuit breaker is open
+var ErrUnavailable = fmt.Errorf("database is unavailable: %w", breaker.ErrOpen)
+
+// Backoff computes exponentially growing retry delays with jitter, so
+// instances restarted together do not retry in lockstep
+type Backoff struct {
+	Initial time.Duration
+	Max     time.Duration
+}
+
+// Delay returns the wait before retry number attempt (starting at 0): a
+// random duration between half and all of Initial*2^attempt, capped at Max
+func (b Backoff) Delay(attempt int) time.Duration {
+	delay := b.Initial
+	for i := 0; i < attempt && delay < b.Max; i++ {
+		delay *= 2
+	}
+	if delay > b.Max {
+		delay = b.Max
+	}
+	if delay <= 0 {
+		return 0
+	}
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
+
+// openPostgres opens a pooled connection with the configured pool limits and
+// statement timeout; the timeout is a session parameter of every connection
+func openPostgres(dsn string, cfg *config.Config, gormConfig *gorm.Config) (*gorm.DB, error) {
+	connConfig, err := pgx.ParseConfig(dsn)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.DB.StatementTimeout > 0 {
+		connConfig.RuntimeParams["statement_timeout"] = strconv.FormatInt(cfg.DB.StatementTimeout.Milliseconds(), 10)
+	}
+
+	sqlDB := stdlib.OpenDB(*connConfig)
+	sqlDB.SetMaxOpenConns(cfg.DB.Pool.MaxOpen)
+	sqlDB.SetMaxIdleConns(cfg.DB.Pool.MaxIdle)
+	sqlDB.SetConnMaxLifetime(cfg.DB.Pool.MaxLifetime)
+	sqlDB.SetConnMaxIdleTime(cfg.DB.Pool.MaxIdleTime)
+
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), gormConfig)
+	if err != nil {
+		sqlDB.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// OpenMigrations opens a separate PostgreSQL connection pool for schema
+// migrations. Migrations and their backfills may run far longer than the
+// statement timeout of the main pool, so it is switched off here.
+func OpenMigrations(dsn string) (*sql.DB, error) {
+	connConfig, err := pgx.ParseConfig(dsn)
+	if err != nil {
+		return nil, err
+	}
+	connConfig.RuntimeParams["statement_timeout"] = "0"
+	return stdlib.OpenDB(*connConfig), nil
+}
+
+// registerBreaker makes every statement on db go through the breaker: while
+// it is open statements fail with ErrUnavailable, and statements that cannot
+// reach the server count as failures. Transactions begin outside the
+// callbacks, so the connection pool is wrapped to count them too.
+func registerBreaker(db *gorm.DB, b *breaker.Breaker) error {
+	pool := &breakerPool{ConnPool: db.ConnPool, breaker: b}
+	db.ConnPool = pool
+	db.Statement.ConnPool = pool
+
+	before := func(db *gorm.DB) {
+		allowed := b.Allow() == nil
+		if !allowed {
+			db.AddError(ErrUnavailable)
+		}
+		db.InstanceSet("breaker:allowed", allowed)
+	}
+	after := func(db *gorm.DB) {
+		if allowed, _ := db.InstanceGet("breaker:allowed"); allowed != true {
+			return
+		}
+		if isConnectionError(db.Error) {
+			b.Failure()
+		} else {
+			b.Success()
+		}
+	}
+
+	callbacks := db.Callback()
+	for _, err := range []error{
+		callbacks.Create().Before("*").Register("breaker:before_create", before),
+		callbacks.Create().After("*").Register("breaker:after_create", after),
+		callbacks.Query().Before("*").Register("breaker:before_query", before),
+		callbacks.Query().After("*").Register("breaker:after_query", after),
+		callbacks.Update().Before("*").Register("breaker:before_update", before),
+		callbacks.Update().After("*").Register("breaker:after_update", after),
+		callbacks.Delete().Before("*").Register("breaker:before_delete", before),
+		callbacks.Delete().After("*").Register("breaker:after_delete", after),
+		callbacks.Row().Before("*").Register("breaker:before_row", before),
+		callbacks.Row().After("*").Register("breaker:after_row", after),
+		callbacks.Raw().Before("*").Register("breaker:before_raw", before),
+		callbacks.Raw().After("*").Register("breaker:after_raw", after),
+	} {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// breakerPool passes the beginning of transactions through the breaker
+type breakerPool struct {
+	gorm.ConnPool
+	breaker *breaker.Breaker
+}
+
+// BeginTx begins a transaction unless the breaker is open, and counts a
+// connection that cannot be obtained as a failure
+func (p *breakerPool) BeginTx(ctx context.Context, opts *sql.TxOptions) (gorm.ConnPool, error) {
+	beginner, ok := p.ConnPool.(gorm.TxBeginner)
+	if !ok {
+		return nil, gorm.ErrInvalidTransaction
+	}
+	if err := p.breaker.Allow(); err != nil {
+		return nil, ErrUnavailable
+	}
+
+	tx, err := beginner.BeginTx(ctx, opts)
+	if isConnectionError(err) {
+		p.breaker.Failure()
+	} else {
+		p.breaker.Success()
+	}
+	if err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// GetDBConn returns the wrapped *sql.DB for gorm.DB.DB
+func (p *breakerPool) GetDBConn() (*sql.DB, error) {
+	if db, ok := p.ConnPool.(*sql.DB); ok {
+		return db, nil
+	}
+	return nil, gorm.ErrInvalidDB
+}
+
+// isConnectionError reports whether err means the server could not be
+// reached, as opposed to a failed query
+func isConnectionError(err error) bool {
+	if err == nil || errors.Is(err, gorm.ErrRecordNotFound) || errors.Is(err, ErrUnavailable) {
+		return false
+	}
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, net.ErrClosed) {
+		return true
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		// connection exceptions, shutdowns, "starting up" and too many connections
+		return strings.HasPrefix(pgErr.Code, "08") || strings.HasPrefix(pgErr.Code, "57P") || pgErr.Code == "53300"
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) || pgconn.SafeToRetry(err)
+}
+
+// reconnect pings the primary with backoff whenever the breaker opens and
+// closes it as soon as the database answers again. database/sql replaces
+// broken pooled connections by itself; the ping only detects the recovery.
+func reconnect(ctx context.Context, db *sql.DB, b *breaker.Breaker, opened <-chan struct{}, backoff Backoff) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-opened:
+		}
+
+		for attempt := 0; b.State() != breaker.Closed; attempt++ {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(backoff.Delay(attempt)):
+			}
+
+			pingCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+			err := db.PingContext(pingCtx)
+			cancel()
+			if err == nil {
+				b.Success()
+				log.Println("Database connection restored")
+				break
+			}
+			log.Printf("Database still unavailable (attempt %d): %v", attempt+1, err)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"net"
+	"qa-api/internal/breaker"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestBackoff_Delay(t *testing.T) {
+	backoff := Backoff{Initial: 100 * time.Millisecond, Max: time.Second}
+
+	for attempt, ceiling := range []time.Duration{100, 200, 400, 800, 1000, 1000} {
+		ceiling *= time.Millisecond
+		for i := 0; i < 20; i++ {
+			delay := backoff.Delay(attempt)
+			assert.GreaterOrEqual(t, delay, ceiling/2, "attempt %d", attempt)
+			assert.LessOrEqual(t, delay, ceiling, "attempt %d", attempt)
+		}
+	}
+	assert.Equal(t, time.Duration(0), Backoff{}.Delay(3))
+}
+
+func TestIsConnectionError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"no error", nil, false},
+		{"not found", gorm.ErrRecordNotFound, false},
+		{"breaker open", ErrUnavailable, false},
+		{"cancelled by the client", context.Canceled, false},
+		{"unique violation", &pgconn.PgError{Code: "23505"}, false},
+		{"statement timeout", &pgconn.PgError{Code: "57014"}, false},
+		{"bad connection", fmt.Errorf("query: %w", driver.ErrBadConn), true},
+		{"refused", &net.OpError{Op: "dial", Err: fmt.Errorf("connection refused")}, true},
+		{"admin shutdown", &pgconn.PgError{Code: "57P01"}, true},
+		{"starting up", &pgconn.PgError{Code: "57P03"}, true},
+		{"too many connections", &pgconn.PgError{Code: "53300"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, isConnectionError(tt.err))
+		})
+	}
+}
+
+// unreachablePool fails to begin transactions like a database that is down
+type unreachablePool struct {
+	gorm.ConnPool
+}
+
+func (unreachablePool) BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error) {
+	return nil, driver.ErrBadConn
+}
+
+func TestBreakerPool(t *testing.T) {
+	t.Run("failed begins open the breaker", func(t *testing.T) {
+		pool := &breakerPool{ConnPool: unreachablePool{}, breaker: breaker.New(2, time.Minute)}
+
+		for i := 0; i < 2; i++ {
+			_, err := pool.BeginTx(context.Background(), nil)
+			assert.ErrorIs(t, err, driver.ErrBadConn)
+		}
+		_, err := pool.BeginTx(context.Background(), nil)
+		assert.ErrorIs(t, err, ErrUnavailable)
+	})
+
+	t.Run("transactions and DB keep working", func(t *testing.T) {
+		db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+		require.NoError(t, err)
+		require.NoError(t, registerBreaker(db, breaker.New(1, time.Minute)))
+
+		sqlDB, err := db.DB()
+		require.NoError(t, err)
+		defer sqlDB.Close()
+		assert.NoError(t, db.Transaction(func(tx *gorm.DB) error {
+			return tx.Exec("SELECT 1").Error
+		}))
+	})
+}
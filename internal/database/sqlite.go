@@ -0,0 +1,70 @@
+package database
+
+import (
+	"net/url"
+	"qa-api/internal/config"
+	"strings"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+)
+
+// SQLiteDSN turns a sqlite:PATH (or sqlite:///PATH, file:PATH) URL into a
+// driver DSN. Foreign keys are enforced, so answers and deliveries cascade
+// like on PostgreSQL; transactions take the write lock up front and wait for
+// it instead of failing with "database is locked"; times are written in a
+// format that sorts correctly as text.
+func SQLiteDSN(databaseURL string) string {
+	path := databaseURL
+	for _, prefix := range []string{"sqlite://", "sqlite:", "file:"} {
+		if strings.HasPrefix(path, prefix) {
+			path = strings.TrimPrefix(path, prefix)
+			break
+		}
+	}
+	path, rawQuery, _ := strings.Cut(path, "?")
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		query = url.Values{}
+	}
+	for _, pragma := range []string{"foreign_keys(1)", "busy_timeout(5000)", "journal_mode(WAL)"} {
+		query.Add("_pragma", pragma)
+	}
+	if query.Get("_txlock") == "" {
+		query.Set("_txlock", "immediate")
+	}
+	if query.Get("_time_format") == "" {
+		query.Set("_time_format", "sqlite")
+	}
+	return "file:" + path + "?" + query.Encode()
+}
+
+// openSQLite opens the SQLite database at the URL. An in-memory database
+// lives in a single connection and is gone once it closes, so the pool keeps
+// exactly that connection open for good, whatever the pool settings say.
+func openSQLite(databaseURL string, cfg *config.Config, gormConfig *gorm.Config) (*gorm.DB, error) {
+	db, err := gorm.Open(sqlite.Open(SQLiteDSN(databaseURL)), gormConfig)
+	if err != nil {
+		return nil, err
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	if strings.Contains(databaseURL, ":memory:") || strings.Contains(databaseURL, "mode=memory") {
+		sqlDB.SetMaxOpenConns(1)
+		sqlDB.SetMaxIdleConns(1)
+		sqlDB.SetConnMaxLifetime(0)
+		sqlDB.SetConnMaxIdleTime(0)
+	} else {
+		sqlDB.SetMaxOpenConns(cfg.DB.Pool.MaxOpen)
+		sqlDB.SetMaxIdleConns(cfg.DB.Pool.MaxIdle)
+		sqlDB.SetConnMaxLifetime(cfg.DB.Pool.MaxLifetime)
+		sqlDB.SetConnMaxIdleTime(cfg.DB.Pool.MaxIdleTime)
+	}
+	if err := sqlDB.Ping(); err != nil {
+		sqlDB.Close()
+		return nil, err
+	}
+	return db, nil
+}
@@ -0,0 +1,52 @@
+package database
+
+import (
+	"net/url"
+	"qa-api/internal/config"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestSQLiteDSN(t *testing.T) {
+	for databaseURL, path := range map[string]string{
+		"sqlite:qa.db":               "qa.db",
+		"sqlite:///var/lib/qa/qa.db": "/var/lib/qa/qa.db",
+		"file:qa.db":                 "qa.db",
+		"sqlite::memory:":            ":memory:",
+	} {
+		dsn := SQLiteDSN(databaseURL)
+		gotPath, rawQuery, _ := strings.Cut(strings.TrimPrefix(dsn, "file:"), "?")
+		assert.Equal(t, path, gotPath, databaseURL)
+		query, err := url.ParseQuery(rawQuery)
+		require.NoError(t, err)
+		assert.Contains(t, query["_pragma"], "foreign_keys(1)", "cascades need foreign keys")
+		assert.Equal(t, "immediate", query.Get("_txlock"))
+	}
+
+	query, err := url.ParseQuery(strings.SplitN(SQLiteDSN("sqlite:qa.db?_txlock=deferred&mode=ro"), "?", 2)[1])
+	require.NoError(t, err)
+	assert.Equal(t, "deferred", query.Get("_txlock"), "parameters in the URL win")
+	assert.Equal(t, "ro", query.Get("mode"))
+}
+
+func TestOpenSQLite_MemoryOutlivesPoolLimits(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.DB.Pool.MaxOpen = 10
+	cfg.DB.Pool.MaxIdle = 0
+	cfg.DB.Pool.MaxLifetime = time.Millisecond
+	cfg.DB.Pool.MaxIdleTime = time.Millisecond
+	db, err := openSQLite("sqlite::memory:", cfg, &gorm.Config{})
+	require.NoError(t, err)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	defer sqlDB.Close()
+
+	require.NoError(t, db.Exec("CREATE TABLE kept (id INTEGER)").Error)
+	time.Sleep(10 * time.Millisecond)
+	assert.NoError(t, db.Exec("INSERT INTO kept (id) VALUES (1)").Error, "the connection holding the database is never closed")
+}
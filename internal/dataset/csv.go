@@ -0,0 +1,214 @@
+package dataset
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// csvColumns is the header written on export; imports may omit the optional
+// columns and list them in any order
+var csvColumns = []string{
+	"question_id", "question_text", "question_created_at",
+	"answer_id", "answer_user_id", "answer_text", "answer_created_at",
+}
+
+// csvRow is a single parsed CSV row
+type csvRow struct {
+	line     int
+	key      string
+	question Question
+	answer   *Answer
+	err      error
+}
+
+type csvReader struct {
+	reader  *csv.Reader
+	columns map[string]int
+	pending *csvRow
+}
+
+func newCSVReader(r io.Reader) (*csvReader, error) {
+	reader := csv.NewReader(r)
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, errors.New("CSV header is missing")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV header: %w", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))
+		if !isCSVColumn(name) {
+			return nil, fmt.Errorf("unknown CSV column %q", name)
+		}
+		if _, dup := columns[name]; dup {
+			return nil, fmt.Errorf("duplicate CSV column %q", name)
+		}
+		columns[name] = i
+	}
+	if _, ok := columns["question_text"]; !ok {
+		return nil, errors.New("CSV column \"question_text\" is required")
+	}
+
+	reader.FieldsPerRecord = len(header)
+	return &csvReader{reader: reader, columns: columns}, nil
+}
+
+// Next reads the rows of the next question
+func (r *csvReader) Next() (Record, error) {
+	first, err := r.row()
+	if err != nil {
+		return Record{}, err
+	}
+
+	record := Record{Line: first.line, Question: first.question, Err: first.err}
+	if first.answer != nil {
+		record.Question.Answers = append(record.Question.Answers, *first.answer)
+	}
+	if first.key == "" {
+		return record, nil
+	}
+
+	for {
+		row, err := r.row()
+		if err == io.EOF {
+			return record, nil
+		}
+		if err != nil {
+			return Record{}, err
+		}
+		if row.key != first.key {
+			r.pending = row
+			return record, nil
+		}
+
+		switch {
+		case record.Err != nil:
+		case row.err != nil:
+			record.Err = fmt.Errorf("line %d: %w", row.line, row.err)
+		case row.question.Text != "" && record.Question.Text != "" && row.question.Text != record.Question.Text:
+			record.Err = fmt.Errorf("line %d: question_text differs from line %d", row.line, first.line)
+		}
+		if record.Question.Text == "" {
+			record.Question.Text = row.question.Text
+		}
+		if row.answer != nil {
+			record.Question.Answers = append(record.Question.Answers, *row.answer)
+		}
+	}
+}
+
+// row returns the lookahead row or parses the next one
+func (r *csvReader) row() (*csvRow, error) {
+	if r.pending != nil {
+		row := r.pending
+		r.pending = nil
+		return row, nil
+	}
+
+	fields, err := r.reader.Read()
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		return &csvRow{line: parseErr.StartLine, err: parseErr.Err}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	line, _ := r.reader.FieldPos(0)
+
+	row := &csvRow{line: line, key: r.field(fields, "question_id")}
+	row.question.Text = r.field(fields, "question_text")
+	row.question.CreatedAt, row.err = parseTime(r.field(fields, "question_created_at"), "question_created_at")
+
+	userID, text := r.field(fields, "answer_user_id"), r.field(fields, "answer_text")
+	answerCreatedAt := r.field(fields, "answer_created_at")
+	if userID != "" || text != "" || answerCreatedAt != "" {
+		answer := &Answer{UserID: userID, Text: text}
+		createdAt, err := parseTime(answerCreatedAt, "answer_created_at")
+		if row.err == nil {
+			row.err = err
+		}
+		answer.CreatedAt = createdAt
+		row.answer = answer
+	}
+	return row, nil
+}
+
+func (r *csvReader) field(fields []string, column string) string {
+	if i, ok := r.columns[column]; ok {
+		return fields[i]
+	}
+	return ""
+}
+
+func parseTime(value, column string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return nil, fmt.Errorf("%s must be an RFC 3339 timestamp", column)
+	}
+	return &t, nil
+}
+
+func isCSVColumn(name string) bool {
+	for _, column := range csvColumns {
+		if column == name {
+			return true
+		}
+	}
+	return false
+}
+
+type csvWriter struct {
+	out    io.Writer
+	writer *csv.Writer
+}
+
+func newCSVWriter(w io.Writer) (*csvWriter, error) {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvColumns); err != nil {
+		return nil, err
+	}
+	return &csvWriter{out: w, writer: writer}, nil
+}
+
+// Write writes a row per answer, or a single row for a question without answers
+func (w *csvWriter) Write(q Question) error {
+	question := []string{strconv.Itoa(q.ID), q.Text, formatTime(q.CreatedAt)}
+	if len(q.Answers) == 0 {
+		return w.writer.Write(append(question, "", "", "", ""))
+	}
+	for _, a := range q.Answers {
+		row := append(question[:3:3], strconv.Itoa(a.ID), a.UserID, a.Text, formatTime(a.CreatedAt))
+		if err := w.writer.Write(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Flush writes out buffered rows
+func (w *csvWriter) Flush() error {
+	w.writer.Flush()
+	if err := w.writer.Error(); err != nil {
+		return err
+	}
+	flushUnderlying(w.out)
+	return nil
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339Nano)
+}
@@ -0,0 +1,180 @@
+// Package dataset reads and writes questions with their answers in the bulk
+// import and export formats: newline-delimited JSON and CSV.
+//
+// NDJSON holds one question per line with its answers nested:
+//
+//	{"text":"...","answers":[{"user_id":"...","text":"..."}]}
+//
+// CSV holds one answer per row. Consecutive rows with the same question_id
+// belong to the same question; a row with empty answer columns is a question
+// without answers. The question_id only groups rows within the file.
+package dataset
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"qa-api/internal/models"
+	"qa-api/internal/validation"
+	"time"
+)
+
+// Supported formats
+const (
+	FormatNDJSON = "ndjson"
+	FormatCSV    = "csv"
+)
+
+// Content types of the supported formats
+const (
+	ContentTypeNDJSON = "application/x-ndjson"
+	ContentTypeCSV    = "text/csv"
+)
+
+// maxLineBytes limits a single NDJSON line
+const maxLineBytes = 1 << 20
+
+// Question is a question as it appears in an import or export file.
+// IDs are informational: imported rows always get new IDs.
+type Question struct {
+	ID        int        `json:"id,omitempty"`
+	Text      string     `json:"text" validate:"required,max=2000,nocontrol"`
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+	Answers   []Answer   `json:"answers,omitempty"`
+}
+
+// Answer is an answer nested in a Question
+type Answer struct {
+	ID        int        `json:"id,omitempty"`
+	UserID    string     `json:"user_id" validate:"required,userid"`
+	Text      string     `json:"text" validate:"required,max=10000,nocontrol"`
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+}
+
+// Validate normalizes and checks the question and its answers. Answer
+// fields are reported as answers[i].field.
+func (q *Question) Validate() validation.Errors {
+	errs := validation.Struct(q)
+	for i := range q.Answers {
+		for _, fieldErr := range validation.Struct(&q.Answers[i]) {
+			fieldErr.Field = fmt.Sprintf("answers[%d].%s", i, fieldErr.Field)
+			errs = append(errs, fieldErr)
+		}
+	}
+	return errs
+}
+
+// Model converts the question to a model ready to be inserted
+func (q *Question) Model() models.Question {
+	question := models.Question{Text: q.Text}
+	if q.CreatedAt != nil {
+		question.CreatedAt = *q.CreatedAt
+	}
+	for _, a := range q.Answers {
+		answer := models.Answer{UserID: a.UserID, Text: a.Text}
+		if a.CreatedAt != nil {
+			answer.CreatedAt = *a.CreatedAt
+		}
+		question.Answers = append(question.Answers, answer)
+	}
+	return question
+}
+
+// FromModel converts a question with its answers to the file representation
+func FromModel(question models.Question) Question {
+	createdAt := question.CreatedAt
+	q := Question{ID: question.ID, Text: question.Text, CreatedAt: &createdAt}
+	for _, a := range question.Answers {
+		answerCreatedAt := a.CreatedAt
+		q.Answers = append(q.Answers, Answer{ID: a.ID, UserID: a.UserID, Text: a.Text, CreatedAt: &answerCreatedAt})
+	}
+	return q
+}
+
+// Record is a question read from an import file. Err is set when the rows
+// could not be parsed; reading may continue with the next record.
+type Record struct {
+	Line     int
+	Question Question
+	Err      error
+}
+
+// Reader reads questions from an import file. Next returns io.EOF after the
+// last record; any other error means the rest of the file cannot be read.
+type Reader interface {
+	Next() (Record, error)
+}
+
+// Writer writes questions to an export file. Flush pushes buffered output
+// to the underlying writer and flushes it too when it is an http.Flusher.
+type Writer interface {
+	Write(q Question) error
+	Flush() error
+}
+
+// NewReader creates a Reader for the format
+func NewReader(format string, r io.Reader) (Reader, error) {
+	switch format {
+	case FormatNDJSON:
+		return newNDJSONReader(r), nil
+	case FormatCSV:
+		reader, err := newCSVReader(r)
+		if err != nil {
+			return nil, err
+		}
+		return reader, nil
+	default:
+		return nil, fmt.Errorf("unknown format %q", format)
+	}
+}
+
+// NewWriter creates a Writer for the format
+func NewWriter(format string, w io.Writer) (Writer, error) {
+	switch format {
+	case FormatNDJSON:
+		return newNDJSONWriter(w), nil
+	case FormatCSV:
+		writer, err := newCSVWriter(w)
+		if err != nil {
+			return nil, err
+		}
+		return writer, nil
+	default:
+		return nil, fmt.Errorf("unknown format %q", format)
+	}
+}
+
+// ContentType returns the content type of a format
+func ContentType(format string) string {
+	if format == FormatCSV {
+		return ContentTypeCSV + "; charset=utf-8"
+	}
+	return ContentTypeNDJSON
+}
+
+// FormatOf returns the format of a content type, or an empty string when it
+// is not supported
+func FormatOf(contentType string) string {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return ""
+	}
+	switch mediaType {
+	case ContentTypeNDJSON, "application/jsonl", "application/x-jsonlines":
+		return FormatNDJSON
+	case ContentTypeCSV:
+		return FormatCSV
+	}
+	return ""
+}
+
+// flusher is implemented by http.ResponseWriter
+type flusher interface {
+	Flush()
+}
+
+func flushUnderlying(w io.Writer) {
+	if f, ok := w.(flusher); ok {
+		f.Flush()
+	}
+}
@@ -0,0 +1,185 @@
+package dataset
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readAll(t *testing.T, reader Reader) []Record {
+	t.Helper()
+	var records []Record
+	for {
+		record, err := reader.Next()
+		if err == io.EOF {
+			return records
+		}
+		require.NoError(t, err)
+		records = append(records, record)
+	}
+}
+
+func TestNDJSONReader(t *testing.T) {
+	input := `{"text":"What is Go?","answers":[{"user_id":"alice","text":"A language"}]}
+
+{"text":"Broken"
+{"text":"Extra","bogus":1}
+{"text":"No answers"}
+`
+	reader, err := NewReader(FormatNDJSON, strings.NewReader(input))
+	require.NoError(t, err)
+	records := readAll(t, reader)
+	require.Len(t, records, 4)
+
+	assert.Equal(t, 1, records[0].Line)
+	assert.NoError(t, records[0].Err)
+	assert.Equal(t, "What is Go?", records[0].Question.Text)
+	assert.Equal(t, []Answer{{UserID: "alice", Text: "A language"}}, records[0].Question.Answers)
+
+	assert.Equal(t, 3, records[1].Line, "blank lines are skipped but counted")
+	assert.Error(t, records[1].Err)
+	assert.Equal(t, 4, records[2].Line)
+	assert.ErrorContains(t, records[2].Err, "bogus")
+	assert.Equal(t, 5, records[3].Line)
+	assert.NoError(t, records[3].Err)
+}
+
+func TestNDJSONReaderLineTooLong(t *testing.T) {
+	input := `{"text":"ok"}` + "\n" + `{"text":"` + strings.Repeat("a", maxLineBytes) + `"}` + "\n"
+	reader, err := NewReader(FormatNDJSON, strings.NewReader(input))
+	require.NoError(t, err)
+
+	_, err = reader.Next()
+	require.NoError(t, err)
+	_, err = reader.Next()
+	assert.EqualError(t, err, "line 2 exceeds 1048576 bytes")
+}
+
+func TestCSVReader(t *testing.T) {
+	input := "answer_user_id,question_text,question_id,answer_text\n" +
+		"alice,What is Go?,q1,A language\n" +
+		"bob,,q1,\"A \"\"fun\"\"\nlanguage\"\n" +
+		",No answers,,\n" +
+		"carol,Mismatch,q2,One\n" +
+		"dave,Other text,q2,Two\n" +
+		"too,few\n" +
+		"erin,Last,q3,Done\n"
+	reader, err := NewReader(FormatCSV, strings.NewReader(input))
+	require.NoError(t, err)
+	records := readAll(t, reader)
+	require.Len(t, records, 5)
+
+	assert.Equal(t, 2, records[0].Line)
+	assert.NoError(t, records[0].Err)
+	assert.Equal(t, "What is Go?", records[0].Question.Text)
+	assert.Equal(t, []Answer{
+		{UserID: "alice", Text: "A language"},
+		{UserID: "bob", Text: "A \"fun\"\nlanguage"},
+	}, records[0].Question.Answers)
+
+	assert.Equal(t, 5, records[1].Line, "quoted line breaks are counted")
+	assert.Equal(t, "No answers", records[1].Question.Text)
+	assert.Empty(t, records[1].Question.Answers)
+
+	assert.Equal(t, 6, records[2].Line)
+	assert.EqualError(t, records[2].Err, "line 7: question_text differs from line 6")
+
+	assert.Equal(t, 8, records[3].Line)
+	assert.Error(t, records[3].Err)
+
+	assert.Equal(t, 9, records[4].Line)
+	assert.NoError(t, records[4].Err)
+}
+
+func TestCSVReaderHeader(t *testing.T) {
+	for input, message := range map[string]string{
+		"":                            "CSV header is missing",
+		"question_text,colour\n":      `unknown CSV column "colour"`,
+		"answer_text\n":               `CSV column "question_text" is required`,
+		"question_text,question_text": `duplicate CSV column "question_text"`,
+	} {
+		_, err := NewReader(FormatCSV, strings.NewReader(input))
+		assert.EqualError(t, err, message)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	createdAt := time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC)
+	questions := []Question{
+		{ID: 1, Text: "What is Go?", CreatedAt: &createdAt, Answers: []Answer{
+			{ID: 10, UserID: "alice", Text: "A language,\nwith \"quotes\"", CreatedAt: &createdAt},
+			{ID: 11, UserID: "bob", Text: "Fun", CreatedAt: &createdAt},
+		}},
+		{ID: 2, Text: "Unanswered", CreatedAt: &createdAt},
+	}
+
+	for _, format := range []string{FormatNDJSON, FormatCSV} {
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+			writer, err := NewWriter(format, &buf)
+			require.NoError(t, err)
+			for _, q := range questions {
+				require.NoError(t, writer.Write(q))
+			}
+			require.NoError(t, writer.Flush())
+
+			reader, err := NewReader(format, &buf)
+			require.NoError(t, err)
+			records := readAll(t, reader)
+			require.Len(t, records, len(questions))
+			for i, record := range records {
+				require.NoError(t, record.Err)
+				want := questions[i]
+				if format == FormatCSV {
+					// CSV IDs only group rows and are not read back
+					want = withoutIDs(want)
+				}
+				assertSameQuestion(t, want, record.Question)
+			}
+		})
+	}
+}
+
+func withoutIDs(q Question) Question {
+	q.ID = 0
+	q.Answers = append([]Answer(nil), q.Answers...)
+	for i := range q.Answers {
+		q.Answers[i].ID = 0
+	}
+	return q
+}
+
+// assertSameQuestion compares through JSON so equal instants in different
+// time representations match
+func assertSameQuestion(t *testing.T, want, got Question) {
+	t.Helper()
+	wantJSON, err := json.Marshal(want)
+	require.NoError(t, err)
+	gotJSON, err := json.Marshal(got)
+	require.NoError(t, err)
+	assert.JSONEq(t, string(wantJSON), string(gotJSON))
+}
+
+func TestQuestionValidate(t *testing.T) {
+	q := Question{Text: " ", Answers: []Answer{{UserID: "alice", Text: "ok"}, {UserID: "bad id", Text: ""}}}
+	errs := q.Validate()
+
+	var fields []string
+	for _, fieldErr := range errs {
+		fields = append(fields, fieldErr.Field)
+	}
+	assert.Equal(t, []string{"text", "answers[1].user_id", "answers[1].text"}, fields)
+}
+
+func TestFormatOf(t *testing.T) {
+	assert.Equal(t, FormatNDJSON, FormatOf("application/x-ndjson"))
+	assert.Equal(t, FormatNDJSON, FormatOf("application/jsonl"))
+	assert.Equal(t, FormatCSV, FormatOf("text/csv; charset=utf-8"))
+	assert.Equal(t, "", FormatOf("application/json"))
+}
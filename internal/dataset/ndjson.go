@@ -0,0 +1,77 @@
+package dataset
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+type ndjsonReader struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+func newNDJSONReader(r io.Reader) *ndjsonReader {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineBytes)
+	return &ndjsonReader{scanner: scanner}
+}
+
+// Next decodes the next non-blank line
+func (r *ndjsonReader) Next() (Record, error) {
+	for r.scanner.Scan() {
+		r.line++
+		data := bytes.TrimSpace(r.scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+
+		record := Record{Line: r.line}
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&record.Question); err != nil {
+			record.Err = fmt.Errorf("invalid JSON: %w", err)
+		} else if decoder.More() {
+			record.Err = errors.New("invalid JSON: unexpected data after object")
+		}
+		return record, nil
+	}
+
+	if err := r.scanner.Err(); err != nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			return Record{}, fmt.Errorf("line %d exceeds %d bytes", r.line+1, maxLineBytes)
+		}
+		return Record{}, err
+	}
+	return Record{}, io.EOF
+}
+
+type ndjsonWriter struct {
+	out     io.Writer
+	buf     *bufio.Writer
+	encoder *json.Encoder
+}
+
+func newNDJSONWriter(w io.Writer) *ndjsonWriter {
+	buf := bufio.NewWriter(w)
+	encoder := json.NewEncoder(buf)
+	encoder.SetEscapeHTML(false)
+	return &ndjsonWriter{out: w, buf: buf, encoder: encoder}
+}
+
+// Write encodes the question on its own line
+func (w *ndjsonWriter) Write(q Question) error {
+	return w.encoder.Encode(q)
+}
+
+// Flush writes out buffered lines
+func (w *ndjsonWriter) Flush() error {
+	if err := w.buf.Flush(); err != nil {
+		return err
+	}
+	flushUnderlying(w.out)
+	return nil
+}
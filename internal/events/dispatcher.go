@@ -0,0 +1,116 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+)
+
+// Store provides access to pending outbox events
+type Store interface {
+	// ClaimDue returns up to limit undelivered events whose next attempt is due
+	// and hides them from other dispatchers for the lease duration.
+	ClaimDue(limit int, lease time.Duration) ([]Event, error)
+	MarkDispatched(id int64) error
+	MarkFailed(id int64, attempts int, nextAttemptAt time.Time, lastError string) error
+}
+
+// Dispatcher polls the outbox and delivers events to sinks with
+// at-least-once semantics. Failed events are retried with exponential backoff.
+type Dispatcher struct {
+	store Store
+	sinks []Sink
+
+	PollInterval time.Duration
+	BatchSize    int
+	Lease        time.Duration
+	BaseBackoff  time.Duration
+	MaxBackoff   time.Duration
+}
+
+// NewDispatcher creates a new Dispatcher with default settings
+func NewDispatcher(store Store, sinks ...Sink) *Dispatcher {
+	return &Dispatcher{
+		store:        store,
+		sinks:        sinks,
+		PollInterval: time.Second,
+		BatchSize:    100,
+		Lease:        30 * time.Second,
+		BaseBackoff:  time.Second,
+		MaxBackoff:   5 * time.Minute,
+	}
+}
+
+// Run polls the outbox until ctx is cancelled
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		// Drain the backlog before waiting for the next tick
+		for {
+			n, err := d.DispatchOnce(ctx)
+			if err != nil {
+				log.Printf("Outbox dispatch failed: %v", err)
+				break
+			}
+			if n < d.BatchSize {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DispatchOnce delivers a single batch of due events and returns its size
+func (d *Dispatcher) DispatchOnce(ctx context.Context) (int, error) {
+	batch, err := d.store.ClaimDue(d.BatchSize, d.Lease)
+	if err != nil {
+		return 0, fmt.Errorf("failed to claim outbox events: %w", err)
+	}
+
+	for _, event := range batch {
+		if err := d.deliver(ctx, event); err != nil {
+			attempts := event.Attempts + 1
+			next := time.Now().Add(d.backoff(attempts))
+			log.Printf("Failed to deliver event %d (%s), attempt %d: %v", event.ID, event.Type, attempts, err)
+			if err := d.store.MarkFailed(event.ID, attempts, next, err.Error()); err != nil {
+				return 0, fmt.Errorf("failed to mark event %d as failed: %w", event.ID, err)
+			}
+			continue
+		}
+
+		if err := d.store.MarkDispatched(event.ID); err != nil {
+			return 0, fmt.Errorf("failed to mark event %d as dispatched: %w", event.ID, err)
+		}
+	}
+
+	return len(batch), nil
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, event Event) error {
+	for _, sink := range d.sinks {
+		if err := sink.Publish(ctx, event); err != nil {
+			return fmt.Errorf("sink %s: %w", sink.Name(), err)
+		}
+	}
+	return nil
+}
+
+// backoff returns the delay before the given attempt number is retried
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	delay := d.BaseBackoff
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= d.MaxBackoff {
+			return d.MaxBackoff
+		}
+	}
+	return delay
+}
@@ -0,0 +1,150 @@
+package events
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryStore is an in-memory implementation of Store
+type memoryStore struct {
+	mu         sync.Mutex
+	pending    map[int64]Event
+	dispatched []int64
+	failed     map[int64]int
+}
+
+func newMemoryStore(events ...Event) *memoryStore {
+	store := &memoryStore{pending: map[int64]Event{}, failed: map[int64]int{}}
+	for _, e := range events {
+		store.pending[e.ID] = e
+	}
+	return store
+}
+
+func (s *memoryStore) ClaimDue(limit int, lease time.Duration) ([]Event, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var batch []Event
+	for _, e := range s.pending {
+		if len(batch) == limit {
+			break
+		}
+		batch = append(batch, e)
+	}
+	return batch, nil
+}
+
+func (s *memoryStore) MarkDispatched(id int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.pending, id)
+	s.dispatched = append(s.dispatched, id)
+	return nil
+}
+
+func (s *memoryStore) MarkFailed(id int64, attempts int, nextAttemptAt time.Time, lastError string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e := s.pending[id]
+	e.Attempts = attempts
+	s.pending[id] = e
+	s.failed[id] = attempts
+	return nil
+}
+
+// flakySink fails the first n deliveries
+type flakySink struct {
+	failures  int
+	delivered []int64
+}
+
+func (s *flakySink) Name() string { return "flaky" }
+
+func (s *flakySink) Publish(ctx context.Context, event Event) error {
+	if s.failures > 0 {
+		s.failures--
+		return errors.New("sink unavailable")
+	}
+	s.delivered = append(s.delivered, event.ID)
+	return nil
+}
+
+func TestDispatcher_DispatchOnce(t *testing.T) {
+	t.Run("delivers to all sinks", func(t *testing.T) {
+		store := newMemoryStore(Event{ID: 1, Type: AnswerCreated, QuestionID: 7})
+		bus := NewInProcessSink()
+		var received []Event
+		bus.Subscribe(func(ctx context.Context, event Event) error {
+			received = append(received, event)
+			return nil
+		})
+		sink := &flakySink{}
+
+		n, err := NewDispatcher(store, bus, sink).DispatchOnce(context.Background())
+
+		require.NoError(t, err)
+		assert.Equal(t, 1, n)
+		assert.Equal(t, []int64{1}, store.dispatched)
+		assert.Equal(t, []int64{1}, sink.delivered)
+		require.Len(t, received, 1)
+		assert.Equal(t, 7, received[0].QuestionID)
+	})
+
+	t.Run("retries failed events", func(t *testing.T) {
+		store := newMemoryStore(Event{ID: 1, Type: QuestionCreated})
+		sink := &flakySink{failures: 2}
+		dispatcher := NewDispatcher(store, sink)
+
+		for i := 0; i < 3; i++ {
+			_, err := dispatcher.DispatchOnce(context.Background())
+			require.NoError(t, err)
+		}
+
+		assert.Equal(t, 2, store.failed[1])
+		assert.Equal(t, []int64{1}, store.dispatched)
+		assert.Equal(t, []int64{1}, sink.delivered)
+	})
+}
+
+func TestDispatcher_Backoff(t *testing.T) {
+	dispatcher := NewDispatcher(newMemoryStore())
+	dispatcher.BaseBackoff = time.Second
+	dispatcher.MaxBackoff = 10 * time.Second
+
+	assert.Equal(t, time.Second, dispatcher.backoff(1))
+	assert.Equal(t, 4*time.Second, dispatcher.backoff(3))
+	assert.Equal(t, 10*time.Second, dispatcher.backoff(10))
+}
+
+func TestFileSink_Publish(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	sink, err := NewFileSink(path)
+	require.NoError(t, err)
+
+	require.NoError(t, sink.Publish(context.Background(), Event{ID: 1, Type: QuestionCreated, Payload: json.RawMessage(`{"id":1}`)}))
+	require.NoError(t, sink.Publish(context.Background(), Event{ID: 2, Type: AnswerCreated, Payload: json.RawMessage(`{"id":5}`)}))
+	require.NoError(t, sink.Close())
+
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+
+	var types []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var event Event
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &event))
+		types = append(types, event.Type)
+	}
+	assert.Equal(t, []string{QuestionCreated, AnswerCreated}, types)
+}
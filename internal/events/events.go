@@ -0,0 +1,215 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"qa-api/internal/models"
+	"time"
+)
+
+// Domain event types
+const (
+	QuestionCreated = "question.created"
+	QuestionDeleted = "question.deleted"
+	QuestionUpdated = "question.updated"
+	QuestionViewed  = "question.viewed"
+	AnswerCreated   = "answer.created"
+	AnswerDeleted   = "answer.deleted"
+	AnswerAccepted  = "answer.accepted"
+	VoteCast        = "vote.cast"
+)
+
+// NotifyChannel is the PostgreSQL channel notified with the ID of every
+// committed outbox event
+const NotifyChannel = "qa_events"
+
+// Aggregate types
+const (
+	AggregateQuestion = "question"
+	AggregateAnswer   = "answer"
+)
+
+// Event is a domain event delivered to sinks
+type Event struct {
+	ID            int64           `json:"id"`
+	Type          string          `json:"type"`
+	AggregateType string          `json:"aggregate_type"`
+	AggregateID   int             `json:"aggregate_id"`
+	QuestionID    int             `json:"question_id"`
+	Payload       json.RawMessage `json:"payload"`
+	OccurredAt    time.Time       `json:"occurred_at"`
+	Attempts      int             `json:"-"`
+}
+
+// QuestionPayload is the payload of question events
+type QuestionPayload struct {
+	ID        int       `json:"id"`
+	UserID    string    `json:"user_id,omitempty"`
+	Text      string    `json:"text"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// AnswerPayload is the payload of answer events
+type AnswerPayload struct {
+	ID         int       `json:"id"`
+	QuestionID int       `json:"question_id"`
+	UserID     string    `json:"user_id"`
+	Text       string    `json:"text"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// ViewPayload is the payload of QuestionViewed; UserID is the author of the
+// question and ViewCount its views so far
+type ViewPayload struct {
+	QuestionID int    `json:"question_id"`
+	UserID     string `json:"user_id"`
+	ViewCount  int    `json:"view_count"`
+}
+
+// VotePayload is the payload of VoteCast. Value is the vote now in place
+// and Previous the one it replaced, 0 meaning none; retracting a vote casts 0.
+type VotePayload struct {
+	AnswerID   int    `json:"answer_id"`
+	QuestionID int    `json:"question_id"`
+	UserID     string `json:"user_id"`
+	VoterID    string `json:"voter_id"`
+	Value      int    `json:"value"`
+	Previous   int    `json:"previous"`
+}
+
+// AcceptPayload is the payload of AnswerAccepted. The Previous fields
+// describe the answer that was accepted before, if any.
+type AcceptPayload struct {
+	AnswerID         int    `json:"answer_id"`
+	QuestionID       int    `json:"question_id"`
+	UserID           string `json:"user_id"`
+	AcceptedBy       string `json:"accepted_by"`
+	PreviousAnswerID int    `json:"previous_answer_id,omitempty"`
+	PreviousUserID   string `json:"previous_user_id,omitempty"`
+}
+
+// NewQuestionEvent builds an outbox record for a question event
+func NewQuestionEvent(eventType string, question *models.Question) (*models.OutboxEvent, error) {
+	payload := QuestionPayload{
+		ID:        question.ID,
+		UserID:    question.UserID,
+		Text:      question.Text,
+		CreatedAt: question.CreatedAt,
+	}
+	return newOutboxEvent(eventType, AggregateQuestion, question.ID, question.ID, payload)
+}
+
+// NewAnswerEvent builds an outbox record for an answer event
+func NewAnswerEvent(eventType string, answer *models.Answer) (*models.OutboxEvent, error) {
+	payload := AnswerPayload{
+		ID:         answer.ID,
+		QuestionID: answer.QuestionID,
+		UserID:     answer.UserID,
+		Text:       answer.Text,
+		CreatedAt:  answer.CreatedAt,
+	}
+	return newOutboxEvent(eventType, AggregateAnswer, answer.ID, answer.QuestionID, payload)
+}
+
+// NewCreatedEvents builds the QuestionCreated and AnswerCreated records of
+// questions inserted together with their answers, in insertion order
+func NewCreatedEvents(questions []models.Question) ([]*models.OutboxEvent, error) {
+	var records []*models.OutboxEvent
+	for i := range questions {
+		record, err := NewQuestionEvent(QuestionCreated, &questions[i])
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+		for j := range questions[i].Answers {
+			record, err := NewAnswerEvent(AnswerCreated, &questions[i].Answers[j])
+			if err != nil {
+				return nil, err
+			}
+			records = append(records, record)
+		}
+	}
+	return records, nil
+}
+
+// NewViewEvent builds an outbox record for a view of a question
+func NewViewEvent(question *models.Question) (*models.OutboxEvent, error) {
+	payload := ViewPayload{
+		QuestionID: question.ID,
+		UserID:     question.UserID,
+		ViewCount:  question.ViewCount,
+	}
+	return newOutboxEvent(QuestionViewed, AggregateQuestion, question.ID, question.ID, payload)
+}
+
+// NewVoteEvent builds an outbox record for a vote on an answer
+func NewVoteEvent(answer *models.Answer, voterID string, value, previous int) (*models.OutboxEvent, error) {
+	payload := VotePayload{
+		AnswerID:   answer.ID,
+		QuestionID: answer.QuestionID,
+		UserID:     answer.UserID,
+		VoterID:    voterID,
+		Value:      value,
+		Previous:   previous,
+	}
+	return newOutboxEvent(VoteCast, AggregateAnswer, answer.ID, answer.QuestionID, payload)
+}
+
+// NewAcceptEvent builds an outbox record for the acceptance of an answer;
+// previous is the answer accepted before, or nil
+func NewAcceptEvent(answer *models.Answer, acceptedBy string, previous *models.Answer) (*models.OutboxEvent, error) {
+	payload := AcceptPayload{
+		AnswerID:   answer.ID,
+		QuestionID: answer.QuestionID,
+		UserID:     answer.UserID,
+		AcceptedBy: acceptedBy,
+	}
+	if previous != nil {
+		payload.PreviousAnswerID = previous.ID
+		payload.PreviousUserID = previous.UserID
+	}
+	return newOutboxEvent(AnswerAccepted, AggregateAnswer, answer.ID, answer.QuestionID, payload)
+}
+
+// UserID returns the author of the answer an answer event is about, or an
+// empty string for other events
+func (e Event) UserID() string {
+	if e.AggregateType != AggregateAnswer {
+		return ""
+	}
+	var payload AnswerPayload
+	if err := json.Unmarshal(e.Payload, &payload); err != nil {
+		return ""
+	}
+	return payload.UserID
+}
+
+// FromOutbox converts a stored outbox record into an Event
+func FromOutbox(record models.OutboxEvent) Event {
+	return Event{
+		ID:            record.ID,
+		Type:          record.EventType,
+		AggregateType: record.AggregateType,
+		AggregateID:   record.AggregateID,
+		QuestionID:    record.QuestionID,
+		Payload:       json.RawMessage(record.Payload),
+		OccurredAt:    record.CreatedAt,
+		Attempts:      record.Attempts,
+	}
+}
+
+func newOutboxEvent(eventType, aggregateType string, aggregateID, questionID int, payload interface{}) (*models.OutboxEvent, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s payload: %w", eventType, err)
+	}
+
+	return &models.OutboxEvent{
+		EventType:     eventType,
+		AggregateType: aggregateType,
+		AggregateID:   aggregateID,
+		QuestionID:    questionID,
+		Payload:       string(data),
+		NextAttemptAt: time.Now(),
+	}, nil
+}
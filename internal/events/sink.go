@@ -0,0 +1,101 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// Sink receives events from the dispatcher. Delivery is at-least-once, so
+// sinks must tolerate duplicates (use Event.ID for deduplication).
+type Sink interface {
+	Name() string
+	Publish(ctx context.Context, event Event) error
+}
+
+// Handler processes an event delivered through an InProcessSink
+type Handler func(ctx context.Context, event Event) error
+
+// InProcessSink fans events out to handlers registered in the same process
+type InProcessSink struct {
+	mu       sync.RWMutex
+	handlers []Handler
+}
+
+// NewInProcessSink creates a new InProcessSink
+func NewInProcessSink() *InProcessSink {
+	return &InProcessSink{}
+}
+
+// Name returns the sink name
+func (s *InProcessSink) Name() string {
+	return "in-process"
+}
+
+// Subscribe registers a handler for all events
+func (s *InProcessSink) Subscribe(handler Handler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers = append(s.handlers, handler)
+}
+
+// Publish calls every handler; the event is retried if any handler fails
+func (s *InProcessSink) Publish(ctx context.Context, event Event) error {
+	s.mu.RLock()
+	handlers := make([]Handler, len(s.handlers))
+	copy(handlers, s.handlers)
+	s.mu.RUnlock()
+
+	var errs []error
+	for _, handler := range handlers {
+		if err := handler(ctx, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// FileSink appends events as JSON lines to a file
+type FileSink struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// NewFileSink opens (or creates) the file at path for appending
+func NewFileSink(path string) (*FileSink, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open events file: %w", err)
+	}
+	return &FileSink{file: file}, nil
+}
+
+// Name returns the sink name
+func (s *FileSink) Name() string {
+	return "file"
+}
+
+// Publish writes the event as a single JSON line and syncs the file
+func (s *FileSink) Publish(ctx context.Context, event Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.file.Write(data); err != nil {
+		return err
+	}
+	return s.file.Sync()
+}
+
+// Close closes the underlying file
+func (s *FileSink) Close() error {
+	return s.file.Close()
+}
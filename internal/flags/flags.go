@@ -0,0 +1,356 @@
+// Package flags provides feature flags and runtime settings that are
+// reloaded from a file without restarting the service.
+package flags
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// Known feature flags
+const (
+	Voting = "voting"
+)
+
+// Known lists the flags the code checks; they are reported even when the
+// file does not mention them
+var Known = []string{Voting}
+
+// Known runtime settings
+const (
+	GraphQLMaxDepth      = "graphql_max_depth"
+	GraphQLMaxComplexity = "graphql_max_complexity"
+)
+
+// positive lists the runtime settings that must be positive integers. A
+// limit of 0 would switch the limit off, so such files are rejected.
+var positive = []string{GraphQLMaxDepth, GraphQLMaxComplexity}
+
+// Checker reports whether a feature is enabled for a user. Handlers and
+// services depend on it rather than on the Store.
+type Checker interface {
+	Enabled(name, userID string) bool
+}
+
+// Flag is the rule of a feature flag. A disabled flag is off for everyone.
+// An enabled one is on for the listed users and for Rollout percent of the
+// others, picked by a stable hash of the user ID; without Rollout it is on
+// for everyone, including anonymous requests.
+type Flag struct {
+	Enabled bool     `yaml:"enabled" toml:"enabled" json:"enabled"`
+	Rollout *int     `yaml:"rollout" toml:"rollout" json:"rollout,omitempty"`
+	Users   []string `yaml:"users" toml:"users" json:"users,omitempty"`
+}
+
+// file is the layout of the flags file
+type file struct {
+	Flags  map[string]Flag        `yaml:"flags" toml:"flags"`
+	Values map[string]interface{} `yaml:"values" toml:"values"`
+}
+
+// snapshot is an immutable version of the file
+type snapshot struct {
+	flags    map[string]Flag
+	values   map[string]interface{}
+	loadedAt time.Time
+	modTime  time.Time
+	size     int64
+}
+
+// Store holds the current flags. Readers never block on a reload: a new
+// snapshot replaces the old one only after it parsed and validated.
+type Store struct {
+	path string
+
+	mu        sync.RWMutex
+	current   *snapshot
+	lastError string
+	listeners []func()
+}
+
+// NewStore loads the flags file at path. An empty path gives a store with
+// every flag off that never reloads.
+func NewStore(path string) (*Store, error) {
+	s := &Store{path: path, current: &snapshot{loadedAt: time.Now()}}
+	if path == "" {
+		return s, nil
+	}
+	if err := s.Reload(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Reload re-reads the file. On error the previous flags stay in effect.
+func (s *Store) Reload() error {
+	if s.path == "" {
+		return nil
+	}
+	next, err := load(s.path)
+
+	s.mu.Lock()
+	if err != nil {
+		s.lastError = err.Error()
+		s.mu.Unlock()
+		return err
+	}
+	s.current = next
+	s.lastError = ""
+	listeners := append([]func(){}, s.listeners...)
+	s.mu.Unlock()
+
+	for _, listener := range listeners {
+		listener()
+	}
+	return nil
+}
+
+// OnReload registers fn to run after every successful reload
+func (s *Store) OnReload(fn func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.listeners = append(s.listeners, fn)
+}
+
+// Watch reloads the file on SIGHUP and, when interval is positive, whenever
+// its modification time or size changes, until ctx is cancelled
+func (s *Store) Watch(ctx context.Context, interval time.Duration) {
+	if s.path == "" {
+		return
+	}
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	var tick <-chan time.Time
+	if interval > 0 {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			s.reloadAndLog("SIGHUP")
+		case <-tick:
+			if s.changed() {
+				s.reloadAndLog("file change")
+			}
+		}
+	}
+}
+
+func (s *Store) reloadAndLog(reason string) {
+	if err := s.Reload(); err != nil {
+		log.Printf("Failed to reload feature flags on %s, keeping the previous ones: %v", reason, err)
+		return
+	}
+	log.Printf("Reloaded feature flags from %s on %s", s.path, reason)
+}
+
+// changed reports whether the file differs from the loaded snapshot. A file
+// that is missing for a moment, as during an atomic replace, is not a change.
+func (s *Store) changed() bool {
+	info, err := os.Stat(s.path)
+	if err != nil {
+		return false
+	}
+	current := s.snapshot()
+	return !info.ModTime().Equal(current.modTime) || info.Size() != current.size
+}
+
+func (s *Store) snapshot() *snapshot {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.current
+}
+
+// Enabled reports whether the flag is on for the user; userID may be empty
+// for anonymous requests. Unknown flags are off.
+func (s *Store) Enabled(name, userID string) bool {
+	flag, ok := s.snapshot().flags[name]
+	if !ok {
+		return false
+	}
+	return flag.enabledFor(name, userID)
+}
+
+func (f Flag) enabledFor(name, userID string) bool {
+	if !f.Enabled {
+		return false
+	}
+	for _, user := range f.Users {
+		if user == userID {
+			return true
+		}
+	}
+	if f.Rollout == nil || *f.Rollout >= 100 {
+		return true
+	}
+	if userID == "" {
+		return false
+	}
+	return bucket(name, userID) < *f.Rollout
+}
+
+// bucket places a user in 0-99 for a flag. Hashing the flag name too gives
+// every flag its own cohort, so the same users are not always first.
+func bucket(name, userID string) int {
+	h := fnv.New32a()
+	h.Write([]byte(name + ":" + userID))
+	return int(h.Sum32() % 100)
+}
+
+// Int returns the runtime setting name, or def if it is not set or not an integer
+func (s *Store) Int(name string, def int) int {
+	value, ok := s.snapshot().values[name]
+	if !ok {
+		return def
+	}
+	n, err := strconv.Atoi(fmt.Sprint(value))
+	if err != nil {
+		return def
+	}
+	return n
+}
+
+// Duration returns the runtime setting name, or def if it is not set or not a duration
+func (s *Store) Duration(name string, def time.Duration) time.Duration {
+	value, ok := s.snapshot().values[name]
+	if !ok {
+		return def
+	}
+	d, err := time.ParseDuration(fmt.Sprint(value))
+	if err != nil {
+		return def
+	}
+	return d
+}
+
+// FlagStatus is a flag as reported by Status
+type FlagStatus struct {
+	Flag
+	EnabledForUser *bool `json:"enabled_for_user,omitempty"`
+}
+
+// Status is the state of the store for the admin endpoint
+type Status struct {
+	Path      string                 `json:"path"`
+	LoadedAt  time.Time              `json:"loaded_at"`
+	LastError string                 `json:"last_error,omitempty"`
+	Flags     map[string]FlagStatus  `json:"flags"`
+	Values    map[string]interface{} `json:"values"`
+}
+
+// Status describes every known and configured flag; when userID is not
+// empty it also evaluates each flag for that user
+func (s *Store) Status(userID string) Status {
+	s.mu.RLock()
+	current, lastError := s.current, s.lastError
+	s.mu.RUnlock()
+
+	status := Status{
+		Path:      s.path,
+		LoadedAt:  current.loadedAt,
+		LastError: lastError,
+		Flags:     make(map[string]FlagStatus),
+		Values:    make(map[string]interface{}),
+	}
+	for _, name := range Known {
+		status.Flags[name] = FlagStatus{}
+	}
+	for name, flag := range current.flags {
+		status.Flags[name] = FlagStatus{Flag: flag}
+	}
+	if userID != "" {
+		for name, flag := range status.Flags {
+			enabled := flag.enabledFor(name, userID)
+			flag.EnabledForUser = &enabled
+			status.Flags[name] = flag
+		}
+	}
+	for name, value := range current.values {
+		status.Values[name] = value
+	}
+	return status
+}
+
+// load parses and validates a YAML or TOML flags file
+func load(path string) (*snapshot, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read feature flags: %w", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read feature flags: %w", err)
+	}
+
+	var parsed file
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".toml":
+		var meta toml.MetaData
+		meta, err = toml.Decode(string(data), &parsed)
+		if err == nil && len(meta.Undecoded()) > 0 {
+			err = fmt.Errorf("unknown key %s", meta.Undecoded()[0])
+		}
+	case ".yaml", ".yml":
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		if err = decoder.Decode(&parsed); err == io.EOF {
+			err = nil
+		}
+	default:
+		return nil, fmt.Errorf("feature flags file %s: unsupported format, use .yaml, .yml or .toml", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("feature flags file %s: %w", path, err)
+	}
+
+	names := make([]string, 0, len(parsed.Flags))
+	for name := range parsed.Flags {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if rollout := parsed.Flags[name].Rollout; rollout != nil && (*rollout < 0 || *rollout > 100) {
+			return nil, fmt.Errorf("feature flags file %s: flags.%s.rollout must be between 0 and 100, got %d", path, name, *rollout)
+		}
+	}
+	for _, name := range positive {
+		value, ok := parsed.Values[name]
+		if !ok {
+			continue
+		}
+		if n, err := strconv.Atoi(fmt.Sprint(value)); err != nil || n < 1 {
+			return nil, fmt.Errorf("feature flags file %s: values.%s must be a positive integer, got %v", path, name, value)
+		}
+	}
+
+	return &snapshot{
+		flags:    parsed.Flags,
+		values:   parsed.Values,
+		loadedAt: time.Now(),
+		modTime:  info.ModTime(),
+		size:     info.Size(),
+	}, nil
+}
@@ -0,0 +1,130 @@
+package flags
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testFlags = `
+flags:
+  voting:
+    enabled: true
+    rollout: 30
+    users: [alice]
+  beta:
+    enabled: false
+    users: [alice]
+  dark_mode:
+    enabled: true
+values:
+  graphql_max_depth: 5
+  sync_timeout: 3s
+`
+
+func writeFlags(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+}
+
+func newTestStore(t *testing.T, content string) (*Store, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "flags.yaml")
+	writeFlags(t, path, content)
+	store, err := NewStore(path)
+	require.NoError(t, err)
+	return store, path
+}
+
+func TestStore_Enabled(t *testing.T) {
+	store, _ := newTestStore(t, testFlags)
+
+	assert.True(t, store.Enabled(Voting, "alice"), "listed users are always in")
+	assert.False(t, store.Enabled(Voting, ""), "anonymous requests are outside a partial rollout")
+	assert.False(t, store.Enabled("beta", "alice"), "a disabled flag is off even for listed users")
+	assert.True(t, store.Enabled("dark_mode", ""), "without rollout an enabled flag is on for everyone")
+	assert.False(t, store.Enabled("unknown", "alice"))
+
+	enabled := 0
+	for i := 0; i < 1000; i++ {
+		userID := fmt.Sprintf("user-%d", i)
+		if store.Enabled(Voting, userID) {
+			enabled++
+		}
+		assert.Equal(t, store.Enabled(Voting, userID), store.Enabled(Voting, userID), "stable per user")
+	}
+	assert.InDelta(t, 300, enabled, 60)
+}
+
+func TestStore_Values(t *testing.T) {
+	store, _ := newTestStore(t, testFlags)
+
+	assert.Equal(t, 5, store.Int("graphql_max_depth", 10))
+	assert.Equal(t, 10, store.Int("graphql_max_complexity", 10))
+	assert.Equal(t, 3*time.Second, store.Duration("sync_timeout", time.Second))
+	assert.Equal(t, time.Second, store.Duration("graphql_max_depth", time.Second), "not a duration")
+}
+
+func TestStore_ReloadKeepsFlagsOnError(t *testing.T) {
+	store, path := newTestStore(t, testFlags)
+	reloads := 0
+	store.OnReload(func() { reloads++ })
+
+	writeFlags(t, path, "flags:\n  voting:\n    enabled: true\n    rollout: 150\n")
+	assert.ErrorContains(t, store.Reload(), "flags.voting.rollout must be between 0 and 100")
+	assert.True(t, store.Enabled(Voting, "alice"))
+	assert.Contains(t, store.Status("").LastError, "rollout")
+
+	writeFlags(t, path, "flags:\n  voting:\n    enabeld: true\n")
+	assert.ErrorContains(t, store.Reload(), "enabeld", "typos are rejected")
+
+	writeFlags(t, path, "values:\n  graphql_max_depth: 0\n")
+	assert.ErrorContains(t, store.Reload(), "values.graphql_max_depth must be a positive integer, got 0", "limits cannot be switched off")
+	assert.Equal(t, 5, store.Int(GraphQLMaxDepth, 10))
+
+	writeFlags(t, path, "flags:\n  voting:\n    enabled: false\n")
+	require.NoError(t, store.Reload())
+	assert.False(t, store.Enabled(Voting, "alice"))
+	assert.Empty(t, store.Status("").LastError)
+	assert.Equal(t, 1, reloads)
+}
+
+func TestStore_WatchReloadsChangedFile(t *testing.T) {
+	store, path := newTestStore(t, testFlags)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go store.Watch(ctx, 10*time.Millisecond)
+
+	writeFlags(t, path, "flags:\n  voting:\n    enabled: false\n")
+	assert.Eventually(t, func() bool { return !store.Enabled(Voting, "alice") }, 2*time.Second, 10*time.Millisecond)
+}
+
+func TestStore_TOML(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "flags.toml")
+	writeFlags(t, path, "[flags.voting]\nenabled = true\nrollout = 0\nusers = [\"bob\"]\n")
+	store, err := NewStore(path)
+	require.NoError(t, err)
+
+	assert.True(t, store.Enabled(Voting, "bob"))
+	assert.False(t, store.Enabled(Voting, "alice"))
+}
+
+func TestStore_Status(t *testing.T) {
+	store, err := NewStore("")
+	require.NoError(t, err)
+	status := store.Status("alice")
+	require.Contains(t, status.Flags, Voting, "known flags are listed even without a file")
+	assert.False(t, *status.Flags[Voting].EnabledForUser)
+
+	store, _ = newTestStore(t, testFlags)
+	status = store.Status("")
+	assert.Nil(t, status.Flags[Voting].EnabledForUser)
+	assert.Equal(t, 30, *status.Flags[Voting].Rollout)
+	assert.Contains(t, status.Flags, "dark_mode")
+}
@@ -0,0 +1,287 @@
+package graphapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"qa-api/internal/auth"
+	"qa-api/internal/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// fakeStore implements QuestionReader, AnswerReader and the services, and
+// counts the batched reads
+type fakeStore struct {
+	questions []models.Question
+	answers   []models.Answer
+
+	answerBatches   [][]int
+	questionBatches [][]int
+}
+
+func (s *fakeStore) GetPage(afterID, limit int) ([]models.Question, error) {
+	var page []models.Question
+	for _, q := range s.questions {
+		if q.ID > afterID && len(page) < limit {
+			page = append(page, q)
+		}
+	}
+	return page, nil
+}
+
+func (s *fakeStore) GetByIDs(ids []int) ([]models.Question, error) {
+	s.questionBatches = append(s.questionBatches, ids)
+	var result []models.Question
+	for _, q := range s.questions {
+		for _, id := range ids {
+			if q.ID == id {
+				result = append(result, q)
+			}
+		}
+	}
+	return result, nil
+}
+
+func (s *fakeStore) GetByQuestionIDs(ids []int, afterID, limit int) ([]models.Answer, error) {
+	s.answerBatches = append(s.answerBatches, ids)
+	var result []models.Answer
+	counts := make(map[int]int)
+	for _, a := range s.answers {
+		for _, id := range ids {
+			if a.QuestionID == id && a.ID > afterID && counts[id] < limit {
+				counts[id]++
+				result = append(result, a)
+			}
+		}
+	}
+	return result, nil
+}
+
+func (s *fakeStore) GetByUserIDs(userIDs []string, afterID, limit int) ([]models.Answer, error) {
+	var result []models.Answer
+	counts := make(map[string]int)
+	for _, a := range s.answers {
+		for _, id := range userIDs {
+			if a.UserID == id && a.ID > afterID && counts[id] < limit {
+				counts[id]++
+				result = append(result, a)
+			}
+		}
+	}
+	return result, nil
+}
+
+func (s *fakeStore) CreateQuestion(userID, text string) (*models.Question, error) {
+	return &models.Question{ID: 10, UserID: userID, Text: text}, nil
+}
+
+func (s *fakeStore) GetAllQuestions() ([]models.Question, error) { return s.questions, nil }
+
+func (s *fakeStore) GetAllQuestionsWithAnswers() ([]models.Question, error) { return s.questions, nil }
+
+func (s *fakeStore) GetQuestionByID(id int) (*models.Question, error) { return nil, nil }
+
+func (s *fakeStore) DeleteQuestion(id, version int) error {
+	if version != 1 {
+		return errors.New("version mismatch")
+	}
+	return nil
+}
+
+func (s *fakeStore) RecordView(id int, viewer string) error { return nil }
+
+func (s *fakeStore) CreateAnswer(questionID int, userID, text string) (*models.Answer, error) {
+	return &models.Answer{ID: 20, QuestionID: questionID, UserID: userID, Text: text}, nil
+}
+
+func (s *fakeStore) GetAnswerByID(id int) (*models.Answer, error) {
+	for i := range s.answers {
+		if s.answers[i].ID == id {
+			return &s.answers[i], nil
+		}
+	}
+	if id < 0 {
+		return nil, errors.New("connection refused")
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (s *fakeStore) DeleteAnswer(id, version int) error { return nil }
+
+type graphQLResponse struct {
+	Data   map[string]interface{}   `json:"data"`
+	Errors []map[string]interface{} `json:"errors"`
+}
+
+func newTestStore() *fakeStore {
+	return &fakeStore{
+		questions: []models.Question{{ID: 1, Text: "Q1"}, {ID: 2, Text: "Q2"}, {ID: 3, Text: "Q3"}},
+		answers: []models.Answer{
+			{ID: 1, QuestionID: 1, UserID: "alice", Text: "A1"},
+			{ID: 2, QuestionID: 2, UserID: "bob", Text: "A2"},
+			{ID: 3, QuestionID: 2, UserID: "alice", Text: "A3"},
+		},
+	}
+}
+
+func execute(t *testing.T, store *fakeStore, limits Limits, query string, variables map[string]interface{}) graphQLResponse {
+	return executeAs(t, store, "", limits, query, variables)
+}
+
+// executeAs runs a query on behalf of an authenticated user; an empty userID
+// runs it anonymously
+func executeAs(t *testing.T, store *fakeStore, userID string, limits Limits, query string, variables map[string]interface{}) graphQLResponse {
+	schema, err := NewSchema(store, store, store)
+	require.NoError(t, err)
+	handler := NewHandler(schema, store, store, limits)
+
+	body, _ := json.Marshal(Request{Query: query, Variables: variables})
+	req := httptest.NewRequest("POST", "/graphql", bytes.NewReader(body))
+	if userID != "" {
+		req = req.WithContext(auth.WithUserID(req.Context(), userID))
+	}
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp graphQLResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	return resp
+}
+
+func TestQuery_BatchesAnswers(t *testing.T) {
+	store := newTestStore()
+	resp := execute(t, store, Limits{}, `{
+		questions(first: 2) {
+			edges { node { id answers { edges { node { id user { id } question { text } } } } } }
+			pageInfo { hasNextPage endCursor }
+		}
+	}`, nil)
+
+	require.Empty(t, resp.Errors)
+	questions := resp.Data["questions"].(map[string]interface{})
+	assert.Len(t, questions["edges"], 2)
+	assert.Equal(t, true, questions["pageInfo"].(map[string]interface{})["hasNextPage"])
+
+	// One query for the answers of both questions, one for the parent questions
+	assert.Equal(t, [][]int{{1, 2}}, store.answerBatches)
+	assert.Len(t, store.questionBatches, 1)
+}
+
+func TestQuery_CursorPagination(t *testing.T) {
+	store := newTestStore()
+	first := execute(t, store, Limits{}, `{ questions(first: 2) { pageInfo { endCursor } } }`, nil)
+	cursor := first.Data["questions"].(map[string]interface{})["pageInfo"].(map[string]interface{})["endCursor"]
+
+	resp := execute(t, store, Limits{}, `query($after: String) { questions(after: $after) { edges { node { text } } pageInfo { hasNextPage } } }`,
+		map[string]interface{}{"after": cursor})
+
+	require.Empty(t, resp.Errors)
+	edges := resp.Data["questions"].(map[string]interface{})["edges"].([]interface{})
+	require.Len(t, edges, 1)
+	assert.Equal(t, "Q3", edges[0].(map[string]interface{})["node"].(map[string]interface{})["text"])
+}
+
+func TestQuery_AnswerPagination(t *testing.T) {
+	store := newTestStore()
+	resp := execute(t, store, Limits{}, `{ user(id: "alice") { answers(first: 1) { edges { node { id } } pageInfo { hasNextPage endCursor } } } }`, nil)
+	require.Empty(t, resp.Errors)
+	answers := resp.Data["user"].(map[string]interface{})["answers"].(map[string]interface{})
+	pageInfo := answers["pageInfo"].(map[string]interface{})
+	assert.Equal(t, true, pageInfo["hasNextPage"])
+
+	resp = execute(t, store, Limits{}, `query($after: String) { user(id: "alice") { answers(first: 1, after: $after) { edges { node { id } } pageInfo { hasNextPage } } } }`,
+		map[string]interface{}{"after": pageInfo["endCursor"]})
+	require.Empty(t, resp.Errors)
+	answers = resp.Data["user"].(map[string]interface{})["answers"].(map[string]interface{})
+	edges := answers["edges"].([]interface{})
+	require.Len(t, edges, 1)
+	assert.Equal(t, "3", edges[0].(map[string]interface{})["node"].(map[string]interface{})["id"])
+	assert.Equal(t, false, answers["pageInfo"].(map[string]interface{})["hasNextPage"])
+}
+
+func TestQuery_Answer(t *testing.T) {
+	resp := execute(t, newTestStore(), Limits{}, `{ answer(id: "99") { id } }`, nil)
+	require.Empty(t, resp.Errors)
+	assert.Nil(t, resp.Data["answer"], "a missing answer is null")
+
+	resp = execute(t, newTestStore(), Limits{}, `{ answer(id: "-1") { id } }`, nil)
+	require.Len(t, resp.Errors, 1, "read errors are reported")
+	assert.Equal(t, "connection refused", resp.Errors[0]["message"])
+}
+
+func TestMutation_CreateAnswer(t *testing.T) {
+	resp := execute(t, newTestStore(), Limits{}, `mutation { createAnswer(questionId: "2", userId: "carol", text: "Hi") { id userId question { text } } }`, nil)
+
+	require.Empty(t, resp.Errors)
+	answer := resp.Data["createAnswer"].(map[string]interface{})
+	assert.Equal(t, "carol", answer["userId"])
+	assert.Equal(t, "Q2", answer["question"].(map[string]interface{})["text"])
+
+	// The authenticated user is the author whatever userId says
+	resp = executeAs(t, newTestStore(), "dave", Limits{}, `mutation { createAnswer(questionId: "2", userId: "carol", text: "Hi") { userId } }`, nil)
+	require.Empty(t, resp.Errors)
+	assert.Equal(t, "dave", resp.Data["createAnswer"].(map[string]interface{})["userId"])
+}
+
+func TestMutation_DeleteQuestion(t *testing.T) {
+	t.Run("version is required", func(t *testing.T) {
+		resp := execute(t, newTestStore(), Limits{}, `mutation { deleteQuestion(id: "1") }`, nil)
+		require.Len(t, resp.Errors, 1)
+		assert.Contains(t, resp.Errors[0]["message"], "version")
+	})
+
+	t.Run("stale version", func(t *testing.T) {
+		resp := execute(t, newTestStore(), Limits{}, `mutation { deleteQuestion(id: "1", version: 2) }`, nil)
+		require.Len(t, resp.Errors, 1)
+		assert.Equal(t, "version mismatch", resp.Errors[0]["message"])
+	})
+
+	t.Run("current version", func(t *testing.T) {
+		resp := execute(t, newTestStore(), Limits{}, `mutation { deleteQuestion(id: "1", version: 1) }`, nil)
+		require.Empty(t, resp.Errors)
+		assert.Equal(t, true, resp.Data["deleteQuestion"])
+	})
+}
+
+func TestLimits(t *testing.T) {
+	deep := `{ questions { edges { node { answers { edges { node { question { answers { edges { node { id } } } } } } } } } } }`
+
+	t.Run("depth", func(t *testing.T) {
+		resp := execute(t, newTestStore(), Limits{MaxDepth: 5}, deep, nil)
+		require.Len(t, resp.Errors, 1)
+		assert.Contains(t, resp.Errors[0]["message"], "depth")
+	})
+
+	t.Run("complexity", func(t *testing.T) {
+		resp := execute(t, newTestStore(), Limits{MaxComplexity: 1000}, deep, nil)
+		require.Len(t, resp.Errors, 1)
+		assert.Contains(t, resp.Errors[0]["message"], "complexity")
+	})
+
+	t.Run("variables cannot cancel out complexity", func(t *testing.T) {
+		query := `query($small: Int, $big: Int) {
+			a: questions(first: $small) { edges { node { answers { edges { node { id } } } } } }
+			b: questions(first: $big) { edges { node { answers { edges { node { id } } } } } }
+		}`
+		for _, variables := range []map[string]interface{}{
+			{"small": -1000000, "big": 100},
+			{"small": 100, "big": 1e300},
+		} {
+			resp := execute(t, newTestStore(), Limits{MaxComplexity: 5000}, query, variables)
+			require.Len(t, resp.Errors, 1, variables)
+			assert.Contains(t, resp.Errors[0]["message"], "complexity")
+		}
+	})
+
+	t.Run("within limits", func(t *testing.T) {
+		resp := execute(t, newTestStore(), Limits{MaxDepth: 10, MaxComplexity: 1000}, `{ questions(first: 5) { edges { node { id } } } }`, nil)
+		assert.Empty(t, resp.Errors)
+	})
+}
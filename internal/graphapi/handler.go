@@ -0,0 +1,100 @@
+package graphapi
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"sync"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+)
+
+// Handler serves GraphQL queries over HTTP
+type Handler struct {
+	schema         graphql.Schema
+	questionReader QuestionReader
+	answerReader   AnswerReader
+
+	mu     sync.RWMutex
+	limits Limits
+}
+
+// NewHandler creates a new Handler
+func NewHandler(schema graphql.Schema, questionReader QuestionReader, answerReader AnswerReader, limits Limits) *Handler {
+	return &Handler{
+		schema:         schema,
+		questionReader: questionReader,
+		answerReader:   answerReader,
+		limits:         limits,
+	}
+}
+
+// SetLimits replaces the query limits, for settings reloaded at runtime
+func (h *Handler) SetLimits(limits Limits) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.limits = limits
+}
+
+func (h *Handler) currentLimits() Limits {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.limits
+}
+
+// Request represents a GraphQL request body
+type Request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// ServeHTTP handles GET and POST /graphql
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req Request
+	if r.Method == http.MethodGet {
+		req.Query = r.URL.Query().Get("query")
+		req.OperationName = r.URL.Query().Get("operationName")
+		if vars := r.URL.Query().Get("variables"); vars != "" {
+			if err := json.Unmarshal([]byte(vars), &req.Variables); err != nil {
+				http.Error(w, "Invalid variables", http.StatusBadRequest)
+				return
+			}
+		}
+	} else if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	doc, err := parser.Parse(parser.ParseParams{Source: source.NewSource(&source.Source{Body: []byte(req.Query)})})
+	if err != nil {
+		writeResult(w, &graphql.Result{Errors: gqlerrors.FormatErrors(err)})
+		return
+	}
+	if err := h.currentLimits().check(doc, req.OperationName, req.Variables); err != nil {
+		writeResult(w, &graphql.Result{Errors: gqlerrors.FormatErrors(err)})
+		return
+	}
+
+	// Loaders are per request, so cached rows never leak between clients
+	ctx := withLoaders(r.Context(), newLoaders(h.questionReader, h.answerReader))
+	result := graphql.Do(graphql.Params{
+		Schema:         h.schema,
+		RequestString:  req.Query,
+		OperationName:  req.OperationName,
+		VariableValues: req.Variables,
+		Context:        ctx,
+	})
+	if result.HasErrors() {
+		log.Printf("GraphQL errors: %v", result.Errors)
+	}
+	writeResult(w, result)
+}
+
+func writeResult(w http.ResponseWriter, result *graphql.Result) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
@@ -0,0 +1,138 @@
+package graphapi
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+// connectionFields are the fields returning pages of up to `first` nodes;
+// their children are counted once per requested node
+var connectionFields = map[string]bool{
+	"questions": true,
+	"answers":   true,
+}
+
+// Limits bounds the size of a query before it is executed
+type Limits struct {
+	MaxDepth      int
+	MaxComplexity int
+}
+
+// check computes the depth and complexity of the selected operation and
+// rejects it when a limit is exceeded
+func (l Limits) check(doc *ast.Document, operationName string, variables map[string]interface{}) error {
+	a := &analyzer{
+		fragments: make(map[string]*ast.FragmentDefinition),
+		variables: variables,
+	}
+
+	var operation *ast.OperationDefinition
+	for _, def := range doc.Definitions {
+		switch def := def.(type) {
+		case *ast.FragmentDefinition:
+			a.fragments[def.Name.Value] = def
+		case *ast.OperationDefinition:
+			if operation == nil && (operationName == "" || (def.Name != nil && def.Name.Value == operationName)) {
+				operation = def
+			}
+		}
+	}
+	if operation == nil {
+		return nil
+	}
+
+	depth, complexity := a.selectionSet(operation.SelectionSet, map[string]bool{})
+	if l.MaxDepth > 0 && depth > l.MaxDepth {
+		return fmt.Errorf("query depth %d exceeds the limit of %d", depth, l.MaxDepth)
+	}
+	if l.MaxComplexity > 0 && complexity > l.MaxComplexity {
+		return fmt.Errorf("query complexity %d exceeds the limit of %d", complexity, l.MaxComplexity)
+	}
+	return nil
+}
+
+type analyzer struct {
+	fragments map[string]*ast.FragmentDefinition
+	variables map[string]interface{}
+}
+
+// selectionSet returns the depth and complexity of a selection set. visiting
+// guards against fragment cycles.
+func (a *analyzer) selectionSet(set *ast.SelectionSet, visiting map[string]bool) (int, int) {
+	if set == nil {
+		return 0, 0
+	}
+
+	maxDepth, total := 0, 0
+	for _, selection := range set.Selections {
+		var depth, cost int
+		switch sel := selection.(type) {
+		case *ast.Field:
+			// Introspection is bounded by the schema itself
+			if strings.HasPrefix(sel.Name.Value, "__") {
+				continue
+			}
+			childDepth, childCost := a.selectionSet(sel.SelectionSet, visiting)
+			depth = childDepth + 1
+			cost = 1 + a.multiplier(sel)*childCost
+		case *ast.InlineFragment:
+			depth, cost = a.selectionSet(sel.SelectionSet, visiting)
+		case *ast.FragmentSpread:
+			name := sel.Name.Value
+			fragment, ok := a.fragments[name]
+			if !ok || visiting[name] {
+				continue
+			}
+			visiting[name] = true
+			depth, cost = a.selectionSet(fragment.SelectionSet, visiting)
+			delete(visiting, name)
+		}
+		if depth > maxDepth {
+			maxDepth = depth
+		}
+		total += cost
+	}
+	return maxDepth, total
+}
+
+// multiplier returns how many times the children of a field are resolved
+func (a *analyzer) multiplier(field *ast.Field) int {
+	if !connectionFields[field.Name.Value] {
+		return 1
+	}
+	for _, arg := range field.Arguments {
+		if arg.Name.Value != "first" {
+			continue
+		}
+		switch value := arg.Value.(type) {
+		case *ast.IntValue:
+			if n, err := strconv.ParseFloat(value.Value, 64); err == nil {
+				return pageSize(n)
+			}
+		case *ast.Variable:
+			switch n := a.variables[value.Name.Value].(type) {
+			case float64:
+				return pageSize(n)
+			case int:
+				return pageSize(float64(n))
+			}
+		}
+	}
+	return defaultPageSize
+}
+
+// pageSize clamps a requested page size to 1..maxPageSize, so neither a
+// negative nor a huge value can shrink or overflow the complexity. Values
+// out of range are rejected when the query runs.
+func pageSize(n float64) int {
+	if n < 1 {
+		return 1
+	}
+	if n > maxPageSize {
+		return maxPageSize
+	}
+	return int(n)
+}
@@ -0,0 +1,145 @@
+package graphapi
+
+import (
+	"context"
+	"qa-api/internal/models"
+	"sync"
+)
+
+// batchLoader collects keys requested while a GraphQL level is resolved and
+// fetches them with a single call when the first result is needed
+type batchLoader[K comparable, V any] struct {
+	mu      sync.Mutex
+	fetch   func(keys []K) (map[K]V, error)
+	pending []K
+	queued  map[K]bool
+	cache   map[K]V
+	errs    map[K]error
+}
+
+func newBatchLoader[K comparable, V any](fetch func(keys []K) (map[K]V, error)) *batchLoader[K, V] {
+	return &batchLoader[K, V]{
+		fetch:  fetch,
+		queued: make(map[K]bool),
+		cache:  make(map[K]V),
+		errs:   make(map[K]error),
+	}
+}
+
+// Load queues key and returns a thunk resolving to its value. graphql-go
+// resolves thunks after the whole level is collected, which turns N loads into
+// one fetch.
+func (l *batchLoader[K, V]) Load(key K) func() (V, error) {
+	l.mu.Lock()
+	_, cached := l.cache[key]
+	_, failed := l.errs[key]
+	if !cached && !failed && !l.queued[key] {
+		l.queued[key] = true
+		l.pending = append(l.pending, key)
+	}
+	l.mu.Unlock()
+
+	return func() (V, error) {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		if l.queued[key] {
+			l.dispatch()
+		}
+		return l.cache[key], l.errs[key]
+	}
+}
+
+// dispatch fetches all pending keys; the caller holds the lock
+func (l *batchLoader[K, V]) dispatch() {
+	keys := l.pending
+	l.pending = nil
+	for _, key := range keys {
+		delete(l.queued, key)
+	}
+
+	values, err := l.fetch(keys)
+	for _, key := range keys {
+		if err != nil {
+			l.errs[key] = err
+			continue
+		}
+		l.cache[key] = values[key]
+	}
+}
+
+// pageKey identifies a page of answers of one question or user. Fields
+// selected with the same arguments share a fetch.
+type pageKey[K comparable] struct {
+	owner   K
+	afterID int
+	limit   int
+}
+
+// loaders holds the per-request batch loaders
+type loaders struct {
+	questions     *batchLoader[int, *models.Question]
+	answersByQ    *batchLoader[pageKey[int], []models.Answer]
+	answersByUser *batchLoader[pageKey[string], []models.Answer]
+}
+
+func newLoaders(questionReader QuestionReader, answerReader AnswerReader) *loaders {
+	return &loaders{
+		questions: newBatchLoader(func(ids []int) (map[int]*models.Question, error) {
+			questions, err := questionReader.GetByIDs(ids)
+			if err != nil {
+				return nil, err
+			}
+			result := make(map[int]*models.Question, len(questions))
+			for i := range questions {
+				result[questions[i].ID] = &questions[i]
+			}
+			return result, nil
+		}),
+		answersByQ:    newBatchLoader(answerPages(answerReader.GetByQuestionIDs, func(a models.Answer) int { return a.QuestionID })),
+		answersByUser: newBatchLoader(answerPages(answerReader.GetByUserIDs, func(a models.Answer) string { return a.UserID })),
+	}
+}
+
+// answerPages returns a batch fetch of pages of answers: one read per
+// distinct page, grouping the answers by owner
+func answerPages[K comparable](
+	read func(owners []K, afterID, limit int) ([]models.Answer, error),
+	owner func(models.Answer) K,
+) func(keys []pageKey[K]) (map[pageKey[K]][]models.Answer, error) {
+	return func(keys []pageKey[K]) (map[pageKey[K]][]models.Answer, error) {
+		type page struct{ afterID, limit int }
+		var pages []page
+		owners := make(map[page][]K)
+		for _, key := range keys {
+			p := page{key.afterID, key.limit}
+			if _, ok := owners[p]; !ok {
+				pages = append(pages, p)
+			}
+			owners[p] = append(owners[p], key.owner)
+		}
+
+		result := make(map[pageKey[K]][]models.Answer, len(keys))
+		for _, p := range pages {
+			answers, err := read(owners[p], p.afterID, p.limit)
+			if err != nil {
+				return nil, err
+			}
+			for _, answer := range answers {
+				key := pageKey[K]{owner: owner(answer), afterID: p.afterID, limit: p.limit}
+				result[key] = append(result[key], answer)
+			}
+		}
+		return result, nil
+	}
+}
+
+type loadersKey struct{}
+
+func withLoaders(ctx context.Context, l *loaders) context.Context {
+	return context.WithValue(ctx, loadersKey{}, l)
+}
+
+func loadersFrom(ctx context.Context) *loaders {
+	return ctx.Value(loadersKey{}).(*loaders)
+}
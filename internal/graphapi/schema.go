@@ -0,0 +1,448 @@
+package graphapi
+
+import (
+	"encoding/base64"
+	"errors"
+	"qa-api/internal/auth"
+	"qa-api/internal/models"
+	"qa-api/internal/service"
+	"strconv"
+	"strings"
+
+	"github.com/graphql-go/graphql"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// QuestionReader provides the batched question reads used by resolvers
+type QuestionReader interface {
+	GetPage(afterID, limit int) ([]models.Question, error)
+	GetByIDs(ids []int) ([]models.Question, error)
+}
+
+// AnswerReader provides the batched answer reads used by resolvers. Each
+// returns up to limit answers per question or user after afterID.
+type AnswerReader interface {
+	GetByQuestionIDs(questionIDs []int, afterID, limit int) ([]models.Answer, error)
+	GetByUserIDs(userIDs []string, afterID, limit int) ([]models.Answer, error)
+}
+
+// user is the GraphQL User. Users have no table of their own yet; a user is
+// identified by the user_id of their answers.
+type user struct {
+	ID string
+}
+
+// pageArgs are the arguments of every connection field
+var pageArgs = graphql.FieldConfigArgument{
+	"first": &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: defaultPageSize},
+	"after": &graphql.ArgumentConfig{Type: graphql.String},
+}
+
+// versionedArgs are the arguments of mutations guarded by optimistic locking
+var versionedArgs = graphql.FieldConfigArgument{
+	"id":      &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+	"version": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)},
+}
+
+// NewSchema builds the GraphQL schema. Queries read through the batch
+// loaders; mutations are mapped onto the existing services.
+func NewSchema(questionService service.QuestionServiceInterface, answerService service.AnswerServiceInterface, questionReader QuestionReader) (graphql.Schema, error) {
+	pageInfoType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "PageInfo",
+		Fields: graphql.Fields{
+			"hasNextPage": &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean)},
+			"endCursor":   &graphql.Field{Type: graphql.String},
+		},
+	})
+
+	questionType := graphql.NewObject(graphql.ObjectConfig{Name: "Question", Fields: graphql.Fields{}})
+	answerType := graphql.NewObject(graphql.ObjectConfig{Name: "Answer", Fields: graphql.Fields{}})
+	userType := graphql.NewObject(graphql.ObjectConfig{Name: "User", Fields: graphql.Fields{}})
+
+	questionConnection := newConnectionType("Question", questionType, pageInfoType)
+	answerConnection := newConnectionType("Answer", answerType, pageInfoType)
+
+	questionType.AddFieldConfig("id", &graphql.Field{Type: graphql.NewNonNull(graphql.ID)})
+	questionType.AddFieldConfig("text", &graphql.Field{Type: graphql.NewNonNull(graphql.String)})
+	questionType.AddFieldConfig("version", &graphql.Field{Type: graphql.NewNonNull(graphql.Int)})
+	questionType.AddFieldConfig("createdAt", &graphql.Field{
+		Type: graphql.NewNonNull(graphql.DateTime),
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return p.Source.(*models.Question).CreatedAt, nil
+		},
+	})
+	questionType.AddFieldConfig("answers", &graphql.Field{
+		Type: graphql.NewNonNull(answerConnection),
+		Args: pageArgs,
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			first, afterID, err := parsePageArgs(p.Args)
+			if err != nil {
+				return nil, err
+			}
+			thunk := loadersFrom(p.Context).answersByQ.Load(pageKey[int]{p.Source.(*models.Question).ID, afterID, first + 1})
+			return func() (interface{}, error) {
+				answers, err := thunk()
+				if err != nil {
+					return nil, err
+				}
+				return answerConnectionOf(answers, first), nil
+			}, nil
+		},
+	})
+
+	answerType.AddFieldConfig("id", &graphql.Field{Type: graphql.NewNonNull(graphql.ID)})
+	answerType.AddFieldConfig("questionId", &graphql.Field{
+		Type: graphql.NewNonNull(graphql.ID),
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return p.Source.(*models.Answer).QuestionID, nil
+		},
+	})
+	answerType.AddFieldConfig("userId", &graphql.Field{
+		Type: graphql.NewNonNull(graphql.String),
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return p.Source.(*models.Answer).UserID, nil
+		},
+	})
+	answerType.AddFieldConfig("text", &graphql.Field{Type: graphql.NewNonNull(graphql.String)})
+	answerType.AddFieldConfig("version", &graphql.Field{Type: graphql.NewNonNull(graphql.Int)})
+	answerType.AddFieldConfig("createdAt", &graphql.Field{
+		Type: graphql.NewNonNull(graphql.DateTime),
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return p.Source.(*models.Answer).CreatedAt, nil
+		},
+	})
+	answerType.AddFieldConfig("question", &graphql.Field{
+		Type: questionType,
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			thunk := loadersFrom(p.Context).questions.Load(p.Source.(*models.Answer).QuestionID)
+			return func() (interface{}, error) {
+				return nullableQuestion(thunk())
+			}, nil
+		},
+	})
+	answerType.AddFieldConfig("user", &graphql.Field{
+		Type: graphql.NewNonNull(userType),
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return &user{ID: p.Source.(*models.Answer).UserID}, nil
+		},
+	})
+
+	userType.AddFieldConfig("id", &graphql.Field{
+		Type: graphql.NewNonNull(graphql.ID),
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return p.Source.(*user).ID, nil
+		},
+	})
+	userType.AddFieldConfig("answers", &graphql.Field{
+		Type: graphql.NewNonNull(answerConnection),
+		Args: pageArgs,
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			first, afterID, err := parsePageArgs(p.Args)
+			if err != nil {
+				return nil, err
+			}
+			thunk := loadersFrom(p.Context).answersByUser.Load(pageKey[string]{p.Source.(*user).ID, afterID, first + 1})
+			return func() (interface{}, error) {
+				answers, err := thunk()
+				if err != nil {
+					return nil, err
+				}
+				return answerConnectionOf(answers, first), nil
+			}, nil
+		},
+	})
+
+	queryType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"questions": &graphql.Field{
+				Type: graphql.NewNonNull(questionConnection),
+				Args: pageArgs,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					first, afterID, err := parsePageArgs(p.Args)
+					if err != nil {
+						return nil, err
+					}
+					questions, err := questionReader.GetPage(afterID, first+1)
+					if err != nil {
+						return nil, err
+					}
+					nodes := make([]interface{}, len(questions))
+					ids := make([]int, len(questions))
+					for i := range questions {
+						nodes[i] = &questions[i]
+						ids[i] = questions[i].ID
+					}
+					return newConnection(nodes, ids, first), nil
+				},
+			},
+			"question": &graphql.Field{
+				Type: questionType,
+				Args: graphql.FieldConfigArgument{
+					"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					id, err := parseID(p.Args["id"])
+					if err != nil {
+						return nil, err
+					}
+					thunk := loadersFrom(p.Context).questions.Load(id)
+					return func() (interface{}, error) {
+						return nullableQuestion(thunk())
+					}, nil
+				},
+			},
+			"answer": &graphql.Field{
+				Type: answerType,
+				Args: graphql.FieldConfigArgument{
+					"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					id, err := parseID(p.Args["id"])
+					if err != nil {
+						return nil, err
+					}
+					answer, err := answerService.GetAnswerByID(id)
+					if errors.Is(err, gorm.ErrRecordNotFound) {
+						return nil, nil
+					}
+					if err != nil {
+						return nil, err
+					}
+					return answer, nil
+				},
+			},
+			"user": &graphql.Field{
+				Type: graphql.NewNonNull(userType),
+				Args: graphql.FieldConfigArgument{
+					"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return &user{ID: p.Args["id"].(string)}, nil
+				},
+			},
+		},
+	})
+
+	mutationType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Mutation",
+		Fields: graphql.Fields{
+			"createQuestion": &graphql.Field{
+				Type: graphql.NewNonNull(questionType),
+				Args: graphql.FieldConfigArgument{
+					"text": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				},
+				// The authenticated user, if any, is the author
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return questionService.CreateQuestion(auth.UserID(p.Context), p.Args["text"].(string))
+				},
+			},
+			"deleteQuestion": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.Boolean),
+				Args: versionedArgs,
+				// The question must still be at the version the client last read
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					id, version, err := parseVersionedArgs(p.Args)
+					if err != nil {
+						return nil, err
+					}
+					if err := questionService.DeleteQuestion(id, version); err != nil {
+						return nil, err
+					}
+					return true, nil
+				},
+			},
+			"createAnswer": &graphql.Field{
+				Type: graphql.NewNonNull(answerType),
+				Args: graphql.FieldConfigArgument{
+					"questionId": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+					"userId":     &graphql.ArgumentConfig{Type: graphql.String},
+					"text":       &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				},
+				// The authenticated user is the author; userId only names
+				// the author when the request is not authenticated
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					questionID, err := parseID(p.Args["questionId"])
+					if err != nil {
+						return nil, err
+					}
+					userID := auth.UserID(p.Context)
+					if userID == "" {
+						userID, _ = p.Args["userId"].(string)
+					}
+					return answerService.CreateAnswer(questionID, userID, p.Args["text"].(string))
+				},
+			},
+			"deleteAnswer": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.Boolean),
+				Args: versionedArgs,
+				// The answer must still be at the version the client last read
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					id, version, err := parseVersionedArgs(p.Args)
+					if err != nil {
+						return nil, err
+					}
+					if err := answerService.DeleteAnswer(id, version); err != nil {
+						return nil, err
+					}
+					return true, nil
+				},
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{
+		Query:    queryType,
+		Mutation: mutationType,
+	})
+}
+
+// connection is the resolved value of a *Connection type
+type connection struct {
+	Edges    []edge   `json:"edges"`
+	PageInfo pageInfo `json:"pageInfo"`
+}
+
+type edge struct {
+	Cursor string      `json:"cursor"`
+	Node   interface{} `json:"node"`
+}
+
+type pageInfo struct {
+	HasNextPage bool    `json:"hasNextPage"`
+	EndCursor   *string `json:"endCursor"`
+}
+
+func newConnectionType(name string, nodeType *graphql.Object, pageInfoType *graphql.Object) *graphql.Object {
+	edgeType := graphql.NewObject(graphql.ObjectConfig{
+		Name: name + "Edge",
+		Fields: graphql.Fields{
+			"cursor": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.String),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(edge).Cursor, nil
+				},
+			},
+			"node": &graphql.Field{
+				Type: graphql.NewNonNull(nodeType),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(edge).Node, nil
+				},
+			},
+		},
+	})
+
+	return graphql.NewObject(graphql.ObjectConfig{
+		Name: name + "Connection",
+		Fields: graphql.Fields{
+			"edges": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(edgeType))),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(*connection).Edges, nil
+				},
+			},
+			"pageInfo": &graphql.Field{
+				Type: graphql.NewNonNull(pageInfoType),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(*connection).PageInfo, nil
+				},
+			},
+		},
+	})
+}
+
+// newConnection builds a page from up to first+1 nodes ordered by ID
+func newConnection(nodes []interface{}, ids []int, first int) *connection {
+	conn := &connection{Edges: []edge{}}
+	if len(nodes) > first {
+		nodes = nodes[:first]
+		conn.PageInfo.HasNextPage = true
+	}
+	for i, node := range nodes {
+		conn.Edges = append(conn.Edges, edge{Cursor: encodeCursor(ids[i]), Node: node})
+	}
+	if len(conn.Edges) > 0 {
+		end := conn.Edges[len(conn.Edges)-1].Cursor
+		conn.PageInfo.EndCursor = &end
+	}
+	return conn
+}
+
+// answerConnectionOf builds a page from up to first+1 answers loaded by a
+// batch loader
+func answerConnectionOf(answers []models.Answer, first int) *connection {
+	nodes := make([]interface{}, len(answers))
+	ids := make([]int, len(answers))
+	for i := range answers {
+		nodes[i] = &answers[i]
+		ids[i] = answers[i].ID
+	}
+	return newConnection(nodes, ids, first)
+}
+
+func parsePageArgs(args map[string]interface{}) (int, int, error) {
+	first, _ := args["first"].(int)
+	if first <= 0 || first > maxPageSize {
+		return 0, 0, errors.New("first must be between 1 and 100")
+	}
+
+	afterID := 0
+	if after, ok := args["after"].(string); ok && after != "" {
+		id, err := decodeCursor(after)
+		if err != nil {
+			return 0, 0, err
+		}
+		afterID = id
+	}
+	return first, afterID, nil
+}
+
+func nullableQuestion(question *models.Question, err error) (interface{}, error) {
+	if err != nil || question == nil {
+		return nil, err
+	}
+	return question, nil
+}
+
+func parseID(value interface{}) (int, error) {
+	s, _ := value.(string)
+	id, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, errors.New("invalid ID")
+	}
+	return id, nil
+}
+
+// parseVersionedArgs reads the ID and the expected version of versionedArgs
+func parseVersionedArgs(args map[string]interface{}) (int, int, error) {
+	id, err := parseID(args["id"])
+	if err != nil {
+		return 0, 0, err
+	}
+	version, _ := args["version"].(int)
+	if version <= 0 {
+		return 0, 0, errors.New("invalid version")
+	}
+	return id, version, nil
+}
+
+const cursorPrefix = "cursor:"
+
+func encodeCursor(id int) string {
+	return base64.StdEncoding.EncodeToString([]byte(cursorPrefix + strconv.Itoa(id)))
+}
+
+func decodeCursor(cursor string) (int, error) {
+	data, err := base64.StdEncoding.DecodeString(cursor)
+	if err == nil && strings.HasPrefix(string(data), cursorPrefix) {
+		if id, err := strconv.Atoi(strings.TrimPrefix(string(data), cursorPrefix)); err == nil {
+			return id, nil
+		}
+	}
+	return 0, errors.New("invalid cursor")
+}
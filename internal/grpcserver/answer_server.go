@@ -0,0 +1,70 @@
+package grpcserver
+
+import (
+	"context"
+	qav1 "qa-api/api/qa/v1"
+	"qa-api/internal/auth"
+	"qa-api/internal/models"
+	"qa-api/internal/service"
+
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// AnswerServer implements qav1.AnswerServiceServer on top of the answer service
+type AnswerServer struct {
+	qav1.UnimplementedAnswerServiceServer
+	answerService service.AnswerServiceInterface
+}
+
+// NewAnswerServer creates a new AnswerServer
+func NewAnswerServer(answerService service.AnswerServiceInterface) *AnswerServer {
+	return &AnswerServer{
+		answerService: answerService,
+	}
+}
+
+// CreateAnswer creates a new answer for a question, authored by the
+// authenticated user; user_id only counts when authentication is disabled
+func (s *AnswerServer) CreateAnswer(ctx context.Context, req *qav1.CreateAnswerRequest) (*qav1.Answer, error) {
+	userID := auth.UserID(ctx)
+	if userID == "" {
+		userID = req.GetUserId()
+	}
+	answer, err := s.answerService.CreateAnswer(int(req.GetQuestionId()), userID, req.GetText())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toAnswerMessage(answer), nil
+}
+
+// GetAnswer retrieves an answer by ID
+func (s *AnswerServer) GetAnswer(ctx context.Context, req *qav1.GetAnswerRequest) (*qav1.Answer, error) {
+	answer, err := s.answerService.GetAnswerByID(int(req.GetId()))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toAnswerMessage(answer), nil
+}
+
+// DeleteAnswer deletes an answer by ID if it is still at the requested version
+func (s *AnswerServer) DeleteAnswer(ctx context.Context, req *qav1.DeleteAnswerRequest) (*emptypb.Empty, error) {
+	if req.GetVersion() <= 0 {
+		return nil, errVersionRequired
+	}
+	if err := s.answerService.DeleteAnswer(int(req.GetId()), int(req.GetVersion())); err != nil {
+		return nil, toStatus(err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func toAnswerMessage(answer *models.Answer) *qav1.Answer {
+	return &qav1.Answer{
+		Id:         int64(answer.ID),
+		QuestionId: int64(answer.QuestionID),
+		UserId:     answer.UserID,
+		Text:       answer.Text,
+		CreatedAt:  timestamppb.New(answer.CreatedAt),
+		Version:    int64(answer.Version),
+	}
+}
@@ -0,0 +1,41 @@
+package grpcserver
+
+import (
+	"errors"
+	"log"
+	"qa-api/internal/validation"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+)
+
+// errVersionRequired is returned by deletes that do not name the version
+// they expect, the counterpart of a missing If-Match header
+var errVersionRequired = status.Error(codes.InvalidArgument, "version is required")
+
+// toStatus maps service errors to gRPC status codes, following the same
+// rules as the HTTP handlers
+func toStatus(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return status.Error(codes.NotFound, "not found")
+	}
+	var invalid validation.Errors
+	if errors.As(err, &invalid) {
+		return status.Error(codes.InvalidArgument, invalid.Error())
+	}
+
+	msg := err.Error()
+	switch {
+	case strings.HasSuffix(msg, "not found"):
+		return status.Error(codes.NotFound, msg)
+	case strings.HasSuffix(msg, "cannot be empty"):
+		return status.Error(codes.InvalidArgument, msg)
+	case msg == "version mismatch":
+		return status.Error(codes.Aborted, msg)
+	default:
+		log.Printf("gRPC internal error: %v", err)
+		return status.Error(codes.Internal, "internal server error")
+	}
+}
@@ -0,0 +1,82 @@
+package grpcserver
+
+import (
+	"context"
+	qav1 "qa-api/api/qa/v1"
+	"qa-api/internal/auth"
+	"qa-api/internal/models"
+	"qa-api/internal/service"
+
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// QuestionServer implements qav1.QuestionServiceServer on top of the question service
+type QuestionServer struct {
+	qav1.UnimplementedQuestionServiceServer
+	questionService service.QuestionServiceInterface
+}
+
+// NewQuestionServer creates a new QuestionServer
+func NewQuestionServer(questionService service.QuestionServiceInterface) *QuestionServer {
+	return &QuestionServer{
+		questionService: questionService,
+	}
+}
+
+// CreateQuestion creates a new question authored by the authenticated user
+func (s *QuestionServer) CreateQuestion(ctx context.Context, req *qav1.CreateQuestionRequest) (*qav1.Question, error) {
+	question, err := s.questionService.CreateQuestion(auth.UserID(ctx), req.GetText())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toQuestionMessage(question), nil
+}
+
+// ListQuestions streams all questions
+func (s *QuestionServer) ListQuestions(req *qav1.ListQuestionsRequest, stream qav1.QuestionService_ListQuestionsServer) error {
+	questions, err := s.questionService.GetAllQuestions()
+	if err != nil {
+		return toStatus(err)
+	}
+
+	for i := range questions {
+		if err := stream.Send(toQuestionMessage(&questions[i])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetQuestion retrieves a question by ID with its answers
+func (s *QuestionServer) GetQuestion(ctx context.Context, req *qav1.GetQuestionRequest) (*qav1.Question, error) {
+	question, err := s.questionService.GetQuestionByID(int(req.GetId()))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toQuestionMessage(question), nil
+}
+
+// DeleteQuestion deletes a question by ID if it is still at the requested version
+func (s *QuestionServer) DeleteQuestion(ctx context.Context, req *qav1.DeleteQuestionRequest) (*emptypb.Empty, error) {
+	if req.GetVersion() <= 0 {
+		return nil, errVersionRequired
+	}
+	if err := s.questionService.DeleteQuestion(int(req.GetId()), int(req.GetVersion())); err != nil {
+		return nil, toStatus(err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func toQuestionMessage(question *models.Question) *qav1.Question {
+	msg := &qav1.Question{
+		Id:        int64(question.ID),
+		Text:      question.Text,
+		CreatedAt: timestamppb.New(question.CreatedAt),
+		Version:   int64(question.Version),
+	}
+	for i := range question.Answers {
+		msg.Answers = append(msg.Answers, toAnswerMessage(&question.Answers[i]))
+	}
+	return msg
+}
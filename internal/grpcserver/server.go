@@ -0,0 +1,28 @@
+package grpcserver
+
+import (
+	qav1 "qa-api/api/qa/v1"
+	"qa-api/internal/auth"
+	"qa-api/internal/service"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+)
+
+// New creates a gRPC server exposing the question and answer services,
+// with logging and authentication interceptors, health checks and reflection
+func New(questionService service.QuestionServiceInterface, answerService service.AnswerServiceInterface, authenticator *auth.Authenticator) *grpc.Server {
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(loggingUnaryInterceptor, authUnaryInterceptor(authenticator)),
+		grpc.ChainStreamInterceptor(loggingStreamInterceptor, authStreamInterceptor(authenticator)),
+	)
+
+	qav1.RegisterQuestionServiceServer(server, NewQuestionServer(questionService))
+	qav1.RegisterAnswerServiceServer(server, NewAnswerServer(answerService))
+	healthpb.RegisterHealthServer(server, health.NewServer())
+	reflection.Register(server)
+
+	return server
+}
@@ -0,0 +1,201 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	qav1 "qa-api/api/qa/v1"
+	"qa-api/internal/auth"
+	"qa-api/internal/models"
+	"qa-api/internal/validation"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"gorm.io/gorm"
+)
+
+// stubQuestionService is a fixed implementation of QuestionServiceInterface
+type stubQuestionService struct {
+	questions []models.Question
+}
+
+func (s *stubQuestionService) CreateQuestion(userID, text string) (*models.Question, error) {
+	if text == "" {
+		return nil, errors.New("question text cannot be empty")
+	}
+	if strings.ContainsRune(text, 0) {
+		return nil, validation.Errors{{Field: "text", Code: "control_characters", Message: "must not contain control characters"}}
+	}
+	return &models.Question{ID: 3, UserID: userID, Text: text}, nil
+}
+
+func (s *stubQuestionService) GetAllQuestions() ([]models.Question, error) {
+	return s.questions, nil
+}
+
+func (s *stubQuestionService) GetAllQuestionsWithAnswers() ([]models.Question, error) {
+	return s.questions, nil
+}
+
+func (s *stubQuestionService) GetQuestionByID(id int) (*models.Question, error) {
+	for i := range s.questions {
+		if s.questions[i].ID == id {
+			return &s.questions[i], nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (s *stubQuestionService) DeleteQuestion(id, version int) error {
+	question, err := s.GetQuestionByID(id)
+	if err != nil {
+		return errors.New("question not found")
+	}
+	if question.Version != version {
+		return errors.New("version mismatch")
+	}
+	return nil
+}
+
+func (s *stubQuestionService) RecordView(id int, viewer string) error {
+	return nil
+}
+
+// stubAnswerService is a fixed implementation of AnswerServiceInterface
+type stubAnswerService struct{}
+
+func (s *stubAnswerService) CreateAnswer(questionID int, userID, text string) (*models.Answer, error) {
+	return &models.Answer{ID: 1, QuestionID: questionID, UserID: userID, Text: text}, nil
+}
+
+func (s *stubAnswerService) GetAnswerByID(id int) (*models.Answer, error) {
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (s *stubAnswerService) DeleteAnswer(id, version int) error {
+	return errors.New("connection refused")
+}
+
+func newTestClient(t *testing.T, tokens string) *grpc.ClientConn {
+	questions := &stubQuestionService{questions: []models.Question{
+		{ID: 1, Text: "First", Version: 2, Answers: []models.Answer{{ID: 5, QuestionID: 1, Text: "Answer"}}},
+		{ID: 2, Text: "Second"},
+	}}
+	server := New(questions, &stubAnswerService{}, auth.NewAuthenticator(tokens))
+
+	listener := bufconn.Listen(1 << 20)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func TestQuestionServer(t *testing.T) {
+	client := qav1.NewQuestionServiceClient(newTestClient(t, ""))
+	ctx := context.Background()
+
+	t.Run("get question with answers", func(t *testing.T) {
+		question, err := client.GetQuestion(ctx, &qav1.GetQuestionRequest{Id: 1})
+
+		require.NoError(t, err)
+		assert.Equal(t, "First", question.GetText())
+		assert.Equal(t, int64(2), question.GetVersion())
+		require.Len(t, question.GetAnswers(), 1)
+		assert.Equal(t, int64(5), question.GetAnswers()[0].GetId())
+	})
+
+	t.Run("stream questions", func(t *testing.T) {
+		stream, err := client.ListQuestions(ctx, &qav1.ListQuestionsRequest{})
+		require.NoError(t, err)
+
+		var ids []int64
+		for {
+			question, err := stream.Recv()
+			if err == io.EOF {
+				break
+			}
+			require.NoError(t, err)
+			ids = append(ids, question.GetId())
+		}
+		assert.Equal(t, []int64{1, 2}, ids)
+	})
+
+	t.Run("status codes", func(t *testing.T) {
+		_, err := client.GetQuestion(ctx, &qav1.GetQuestionRequest{Id: 99})
+		assert.Equal(t, codes.NotFound, status.Code(err))
+
+		_, err = client.CreateQuestion(ctx, &qav1.CreateQuestionRequest{})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+		_, err = client.CreateQuestion(ctx, &qav1.CreateQuestionRequest{Text: "a\x00b"})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		assert.Equal(t, "text: must not contain control characters", status.Convert(err).Message())
+
+		_, err = client.DeleteQuestion(ctx, &qav1.DeleteQuestionRequest{Id: 99, Version: 1})
+		assert.Equal(t, codes.NotFound, status.Code(err))
+	})
+
+	t.Run("deletes check the version", func(t *testing.T) {
+		_, err := client.DeleteQuestion(ctx, &qav1.DeleteQuestionRequest{Id: 1})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+		_, err = client.DeleteQuestion(ctx, &qav1.DeleteQuestionRequest{Id: 1, Version: 1})
+		assert.Equal(t, codes.Aborted, status.Code(err))
+
+		_, err = client.DeleteQuestion(ctx, &qav1.DeleteQuestionRequest{Id: 1, Version: 2})
+		assert.NoError(t, err)
+	})
+}
+
+func TestAnswerServer(t *testing.T) {
+	client := qav1.NewAnswerServiceClient(newTestClient(t, ""))
+	ctx := context.Background()
+
+	answer, err := client.CreateAnswer(ctx, &qav1.CreateAnswerRequest{QuestionId: 1, UserId: "user-1", Text: "Yes"})
+	require.NoError(t, err)
+	assert.Equal(t, "user-1", answer.GetUserId())
+
+	_, err = client.DeleteAnswer(ctx, &qav1.DeleteAnswerRequest{Id: 1})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = client.DeleteAnswer(ctx, &qav1.DeleteAnswerRequest{Id: 1, Version: 1})
+	assert.Equal(t, codes.Internal, status.Code(err))
+}
+
+func TestAuthInterceptor(t *testing.T) {
+	client := qav1.NewQuestionServiceClient(newTestClient(t, "secret:user-1"))
+
+	_, err := client.GetQuestion(context.Background(), &qav1.GetQuestionRequest{Id: 1})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	stream, err := client.ListQuestions(context.Background(), &qav1.ListQuestionsRequest{})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer secret")
+	_, err = client.GetQuestion(ctx, &qav1.GetQuestionRequest{Id: 1})
+	assert.NoError(t, err)
+
+	answers := qav1.NewAnswerServiceClient(newTestClient(t, "secret:user-1"))
+	answer, err := answers.CreateAnswer(ctx, &qav1.CreateAnswerRequest{QuestionId: 1, UserId: "mallory", Text: "Yes"})
+	require.NoError(t, err)
+	assert.Equal(t, "user-1", answer.GetUserId(), "the authenticated user is the author")
+}
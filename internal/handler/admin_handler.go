@@ -0,0 +1,131 @@
+package handler
+
+import (
+	"encoding/json"
+	"log"
+	"mime"
+	"net/http"
+	"qa-api/internal/dataset"
+	"qa-api/internal/service"
+	"strconv"
+	"strings"
+)
+
+// AdminHandler handles HTTP requests for administrative bulk operations
+type AdminHandler struct {
+	datasetService service.DatasetServiceInterface
+}
+
+// NewAdminHandler creates a new AdminHandler
+func NewAdminHandler(datasetService service.DatasetServiceInterface) *AdminHandler {
+	return &AdminHandler{
+		datasetService: datasetService,
+	}
+}
+
+// Import handles POST /admin/import. The format is taken from ?format= or
+// the Content-Type; ?dry_run=true validates without inserting anything.
+func (h *AdminHandler) Import(w http.ResponseWriter, r *http.Request) {
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = dataset.FormatOf(r.Header.Get("Content-Type"))
+	}
+	if format != dataset.FormatNDJSON && format != dataset.FormatCSV {
+		http.Error(w, "Content-Type must be application/x-ndjson or text/csv", http.StatusUnsupportedMediaType)
+		return
+	}
+
+	dryRun := false
+	if value := r.URL.Query().Get("dry_run"); value != "" {
+		var err error
+		if dryRun, err = strconv.ParseBool(value); err != nil {
+			http.Error(w, "Invalid query: dry_run must be a boolean", http.StatusBadRequest)
+			return
+		}
+	}
+
+	reader, err := dataset.NewReader(format, r.Body)
+	if err != nil {
+		http.Error(w, "Invalid import: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	report, err := h.datasetService.Import(r.Context(), reader, dryRun)
+	status := http.StatusOK
+	switch {
+	case err != nil:
+		log.Printf("Error importing questions: %v", err)
+		status = http.StatusInternalServerError
+	case report.Error != "":
+		status = http.StatusBadRequest
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(report)
+}
+
+// Export handles GET /admin/export. The format is taken from ?format= or
+// the Accept header and defaults to NDJSON. The body is streamed page by page.
+func (h *AdminHandler) Export(w http.ResponseWriter, r *http.Request) {
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = exportFormat(r.Header.Get("Accept"))
+	}
+	if format != dataset.FormatNDJSON && format != dataset.FormatCSV {
+		http.Error(w, "Invalid query: format must be ndjson or csv", http.StatusBadRequest)
+		return
+	}
+
+	out := &countingWriter{ResponseWriter: w}
+	writer, err := dataset.NewWriter(format, out)
+	if err != nil {
+		log.Printf("Error starting export: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", dataset.ContentType(format))
+	w.Header().Set("Content-Disposition", `attachment; filename="questions.`+format+`"`)
+	if err := h.datasetService.Export(r.Context(), writer); err != nil {
+		log.Printf("Error exporting questions: %v", err)
+		if out.written == 0 {
+			w.Header().Del("Content-Disposition")
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+		}
+		// Otherwise the status is already sent; the client sees a truncated body
+	}
+}
+
+// exportFormat picks the first supported format listed in an Accept header
+func exportFormat(accept string) string {
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		if format := dataset.FormatOf(mediaType); format != "" {
+			return format
+		}
+	}
+	return dataset.FormatNDJSON
+}
+
+// countingWriter records whether any of the body has been sent
+type countingWriter struct {
+	http.ResponseWriter
+	written int
+}
+
+func (w *countingWriter) Write(data []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(data)
+	w.written += n
+	return n, err
+}
+
+// Flush sends buffered data to the client
+func (w *countingWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"log"
 	"net/http"
+	"qa-api/internal/presenter"
 	"qa-api/internal/service"
 	"strconv"
 
@@ -22,10 +23,11 @@ func NewAnswerHandler(answerService service.AnswerServiceInterface) *AnswerHandl
 	}
 }
 
-// CreateAnswerRequest represents the request body for creating an answer
+// CreateAnswerRequest represents the request body for creating an answer.
+// UserID names the author when the request is not authenticated.
 type CreateAnswerRequest struct {
-	UserID string `json:"user_id"`
-	Text   string `json:"text"`
+	UserID string `json:"user_id,omitempty" validate:"userid"`
+	Text   string `json:"text" validate:"required,max=10000,nocontrol"`
 }
 
 // CreateAnswer handles POST /questions/{id}/answers/
@@ -38,12 +40,11 @@ func (h *AnswerHandler) CreateAnswer(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var req CreateAnswerRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+	if !decodeJSON(w, r, &req) {
 		return
 	}
 
-	answer, err := h.answerService.CreateAnswer(questionID, req.UserID, req.Text)
+	answer, err := h.answerService.CreateAnswer(questionID, actingUser(r, req.UserID), req.Text)
 	if err != nil {
 		log.Printf("Error creating answer: %v", err)
 		if err.Error() == "question not found" {
@@ -55,8 +56,9 @@ func (h *AnswerHandler) CreateAnswer(w http.ResponseWriter, r *http.Request) {
 	}
 
 	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", versionETag(answer.Version))
 	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(answer)
+	json.NewEncoder(w).Encode(presenter.NewAnswer(answer, presenter.Options{}))
 }
 
 // GetAnswer handles GET /answers/{id}
@@ -68,15 +70,22 @@ func (h *AnswerHandler) GetAnswer(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	opts, ok := parseOptions(w, r, presenter.AnswerResource)
+	if !ok {
+		return
+	}
+
 	answer, err := h.answerService.GetAnswerByID(id)
 	if err != nil {
 		log.Printf("Error getting answer: %v", err)
 		http.Error(w, "Answer not found", http.StatusNotFound)
 		return
 	}
+	if notModified(w, r, answerETag(answer, opts), opts) {
+		return
+	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(answer)
+	writeSelected(w, opts, presenter.NewAnswer(answer, opts))
 }
 
 // DeleteAnswer handles DELETE /answers/{id}
@@ -88,10 +97,17 @@ func (h *AnswerHandler) DeleteAnswer(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.answerService.DeleteAnswer(id); err != nil {
+	version, ok := ifMatchVersion(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.answerService.DeleteAnswer(id, version); err != nil {
 		log.Printf("Error deleting answer: %v", err)
 		if err.Error() == "answer not found" {
 			http.Error(w, "Answer not found", http.StatusNotFound)
+		} else if err.Error() == "version mismatch" {
+			http.Error(w, "Answer has been modified", http.StatusPreconditionFailed)
 		} else {
 			http.Error(w, "Internal server error", http.StatusInternalServerError)
 		}
@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"qa-api/internal/presenter"
+	"qa-api/internal/service"
+	"qa-api/internal/validation"
+
+	"github.com/gorilla/mux"
+)
+
+// BadgeHandler handles HTTP requests for badges
+type BadgeHandler struct {
+	badgeService service.BadgeServiceInterface
+}
+
+// NewBadgeHandler creates a new BadgeHandler
+func NewBadgeHandler(badgeService service.BadgeServiceInterface) *BadgeHandler {
+	return &BadgeHandler{
+		badgeService: badgeService,
+	}
+}
+
+// GetBadges handles GET /badges
+func (h *BadgeHandler) GetBadges(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(presenter.NewBadges(h.badgeService.GetBadges()))
+}
+
+// GetUserBadges handles GET /users/{id}/badges
+func (h *BadgeHandler) GetUserBadges(w http.ResponseWriter, r *http.Request) {
+	path := userPath{ID: mux.Vars(r)["id"]}
+	if errs := validation.Struct(&path); errs != nil {
+		writeValidationErrors(w, errs)
+		return
+	}
+
+	awards, err := h.badgeService.GetUserBadges(path.ID)
+	if err != nil {
+		log.Printf("Error getting badges: %v", err)
+		if err.Error() == "user not found" {
+			http.Error(w, "User not found", http.StatusNotFound)
+		} else {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(presenter.NewUserBadges(awards, h.badgeService.GetBadges()))
+}
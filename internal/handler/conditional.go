@@ -0,0 +1,118 @@
+package handler
+
+import (
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"qa-api/internal/models"
+	"qa-api/internal/presenter"
+	"strconv"
+	"strings"
+)
+
+// versionETag builds a weak ETag from an entity version, optionally followed
+// by the versions of embedded entities: W/"3" or W/"3.7"
+func versionETag(versions ...int) string {
+	parts := make([]string, len(versions))
+	for i, version := range versions {
+		parts[i] = strconv.Itoa(version)
+	}
+	return `W/"` + strings.Join(parts, ".") + `"`
+}
+
+// answerETag covers the embedded question when it is included
+func answerETag(answer *models.Answer, opts presenter.Options) string {
+	if opts.Includes("question") && answer.Question.ID != 0 {
+		return versionETag(answer.Version, answer.Question.Version)
+	}
+	return versionETag(answer.Version)
+}
+
+// listETag builds a weak ETag for a list of questions from their IDs and
+// versions, so it changes whenever a question is added, removed or changed
+func listETag(questions []models.Question) string {
+	h := fnv.New64a()
+	for _, question := range questions {
+		fmt.Fprintf(h, "%d:%d;", question.ID, question.Version)
+	}
+	return fmt.Sprintf(`W/"%x"`, h.Sum64())
+}
+
+// representationETag appends a hash of the representation chosen with
+// ?fields= and ?include= to etag, so a sparse or differently embedded copy
+// never revalidates another: W/"3" becomes W/"3.1a2b3c4d". Responses with the
+// default representation keep the plain tag.
+func representationETag(etag string, opts presenter.Options) string {
+	key := opts.Key()
+	if key == "" {
+		return etag
+	}
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return fmt.Sprintf(`%s.%08x"`, strings.TrimSuffix(etag, `"`), h.Sum32())
+}
+
+// notModified sets the ETag of the representation selected by opts and
+// reports whether If-None-Match matches it; in that case 304 Not Modified has
+// already been written
+func notModified(w http.ResponseWriter, r *http.Request, etag string, opts presenter.Options) bool {
+	etag = representationETag(etag, opts)
+	w.Header().Set("ETag", etag)
+
+	header := r.Header.Get("If-None-Match")
+	if header == "" {
+		return false
+	}
+	for _, tag := range splitETags(header) {
+		if tag == "*" || weakMatch(tag, etag) {
+			w.WriteHeader(http.StatusNotModified)
+			return true
+		}
+	}
+	return false
+}
+
+// ifMatchVersion reads the entity version a mutation expects from If-Match.
+// "*" matches any version and yields 0. A missing header is answered with
+// 428 Precondition Required, an unusable one with 400; both return false.
+func ifMatchVersion(w http.ResponseWriter, r *http.Request) (int, bool) {
+	header := r.Header.Get("If-Match")
+	if header == "" {
+		http.Error(w, "If-Match header is required", http.StatusPreconditionRequired)
+		return 0, false
+	}
+
+	tags := splitETags(header)
+	if len(tags) == 1 && tags[0] == "*" {
+		return 0, true
+	}
+	if len(tags) != 1 {
+		http.Error(w, "If-Match must contain a single ETag", http.StatusBadRequest)
+		return 0, false
+	}
+
+	// Only weak tags are ever issued, so they are compared by version
+	opaque := strings.Trim(strings.TrimPrefix(tags[0], "W/"), `"`)
+	head, _, _ := strings.Cut(opaque, ".")
+	version, err := strconv.Atoi(head)
+	if err != nil || version <= 0 {
+		http.Error(w, "Invalid If-Match ETag", http.StatusBadRequest)
+		return 0, false
+	}
+	return version, true
+}
+
+func splitETags(header string) []string {
+	var tags []string
+	for _, tag := range strings.Split(header, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// weakMatch compares two entity tags ignoring the weak indicator
+func weakMatch(a, b string) bool {
+	return strings.TrimPrefix(a, "W/") == strings.TrimPrefix(b, "W/")
+}
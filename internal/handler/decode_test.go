@@ -0,0 +1,44 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeJSON_FieldErrors(t *testing.T) {
+	body := `{"user_id":"user 1","text":"bad\u0007text"}`
+	req := httptest.NewRequest("POST", "/questions/1/answers/", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	w := httptest.NewRecorder()
+
+	var dst CreateAnswerRequest
+	ok := decodeJSON(w, req, &dst)
+
+	assert.False(t, ok)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+	var resp ValidationErrorResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+	require.Len(t, resp.Fields, 2)
+	assert.Equal(t, "user_id", resp.Fields[0].Field)
+	assert.Equal(t, "invalid_format", resp.Fields[0].Code)
+	assert.Equal(t, "text", resp.Fields[1].Field)
+	assert.Equal(t, "control_characters", resp.Fields[1].Code)
+}
+
+func TestDecodeJSON_Valid(t *testing.T) {
+	req := httptest.NewRequest("POST", "/questions/", strings.NewReader(`{"text":"What is Go?"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	var dst CreateQuestionRequest
+	assert.True(t, decodeJSON(w, req, &dst))
+	assert.Equal(t, "What is Go?", dst.Text)
+}
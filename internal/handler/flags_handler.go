@@ -0,0 +1,36 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"qa-api/internal/flags"
+)
+
+// FlagsHandler handles HTTP requests for the feature flag state
+type FlagsHandler struct {
+	store *flags.Store
+}
+
+// NewFlagsHandler creates a new FlagsHandler
+func NewFlagsHandler(store *flags.Store) *FlagsHandler {
+	return &FlagsHandler{
+		store: store,
+	}
+}
+
+// Get handles GET /admin/flags. With ?user_id= every flag is also evaluated
+// for that user, which shows whether they fall into a rollout.
+func (h *FlagsHandler) Get(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.store.Status(r.URL.Query().Get("user_id")))
+}
+
+// Reload handles POST /admin/flags/reload, an alternative to SIGHUP where
+// signals are hard to send. A broken file leaves the previous flags active.
+func (h *FlagsHandler) Reload(w http.ResponseWriter, r *http.Request) {
+	if err := h.store.Reload(); err != nil {
+		http.Error(w, "Failed to reload feature flags: "+err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	h.Get(w, r)
+}
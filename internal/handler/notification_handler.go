@@ -0,0 +1,216 @@
+package handler
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"qa-api/internal/auth"
+	"qa-api/internal/presenter"
+	"qa-api/internal/service"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// defaultNotificationPage is the number of notifications without ?limit=
+const defaultNotificationPage = 20
+
+// NotificationHandler handles HTTP requests for the notification inbox of
+// the authenticated user and the questions they follow. Every request needs
+// an authenticated user: an inbox is private, so a client-supplied user_id
+// is never trusted.
+type NotificationHandler struct {
+	notificationService service.NotificationServiceInterface
+}
+
+// NewNotificationHandler creates a new NotificationHandler
+func NewNotificationHandler(notificationService service.NotificationServiceInterface) *NotificationHandler {
+	return &NotificationHandler{
+		notificationService: notificationService,
+	}
+}
+
+// UpdatePreferencesRequest represents the request body for turning
+// notification kinds on or off; kinds it leaves out keep their setting
+type UpdatePreferencesRequest struct {
+	Preferences map[string]bool `json:"preferences"`
+}
+
+// PreferencesResponse lists whether each notification kind is on
+type PreferencesResponse struct {
+	Preferences map[string]bool `json:"preferences"`
+}
+
+// GetNotifications handles GET /me/notifications; ?before= pages back from
+// a notification ID
+func (h *NotificationHandler) GetNotifications(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.user(w, r)
+	if !ok {
+		return
+	}
+
+	limit := defaultNotificationPage
+	var beforeID int64
+	query := r.URL.Query()
+	if value := query.Get("limit"); value != "" {
+		var err error
+		if limit, err = strconv.Atoi(value); err != nil {
+			http.Error(w, "Invalid query: limit must be an integer", http.StatusBadRequest)
+			return
+		}
+	}
+	if value := query.Get("before"); value != "" {
+		var err error
+		if beforeID, err = strconv.ParseInt(value, 10, 64); err != nil || beforeID < 1 {
+			http.Error(w, "Invalid query: before must be a positive integer", http.StatusBadRequest)
+			return
+		}
+	}
+
+	inbox, err := h.notificationService.GetInbox(userID, beforeID, limit)
+	if err != nil {
+		writeNotificationError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(presenter.NewInbox(inbox.Notifications, inbox.UnreadCount))
+}
+
+// MarkRead handles POST /me/notifications/{id}/read
+func (h *NotificationHandler) MarkRead(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid notification ID", http.StatusBadRequest)
+		return
+	}
+	userID, ok := h.user(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.notificationService.MarkRead(userID, id); err != nil {
+		writeNotificationError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// MarkAllRead handles POST /me/notifications/read-all
+func (h *NotificationHandler) MarkAllRead(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.user(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.notificationService.MarkAllRead(userID); err != nil {
+		writeNotificationError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetPreferences handles GET /me/notifications/preferences
+func (h *NotificationHandler) GetPreferences(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.user(w, r)
+	if !ok {
+		return
+	}
+
+	preferences, err := h.notificationService.GetPreferences(userID)
+	if err != nil {
+		writeNotificationError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(PreferencesResponse{Preferences: preferences})
+}
+
+// UpdatePreferences handles PUT /me/notifications/preferences
+func (h *NotificationHandler) UpdatePreferences(w http.ResponseWriter, r *http.Request) {
+	var req UpdatePreferencesRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	userID, ok := h.user(w, r)
+	if !ok {
+		return
+	}
+
+	preferences, err := h.notificationService.UpdatePreferences(userID, req.Preferences)
+	if err != nil {
+		writeNotificationError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(PreferencesResponse{Preferences: preferences})
+}
+
+// Follow handles PUT /questions/{id}/follow
+func (h *NotificationHandler) Follow(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid question ID", http.StatusBadRequest)
+		return
+	}
+	userID, ok := h.user(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.notificationService.Follow(id, userID); err != nil {
+		writeNotificationError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Unfollow handles DELETE /questions/{id}/follow
+func (h *NotificationHandler) Unfollow(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid question ID", http.StatusBadRequest)
+		return
+	}
+	userID, ok := h.user(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.notificationService.Unfollow(id, userID); err != nil {
+		writeNotificationError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// user returns the authenticated user of a request. Without one it writes
+// 401 Unauthorized and returns false.
+func (h *NotificationHandler) user(w http.ResponseWriter, r *http.Request) (string, bool) {
+	userID := auth.UserID(r.Context())
+	if userID == "" {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="qa-api"`)
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return "", false
+	}
+	return userID, true
+}
+
+func writeNotificationError(w http.ResponseWriter, err error) {
+	log.Printf("Error handling notifications: %v", err)
+	switch {
+	case err.Error() == "notification not found":
+		http.Error(w, "Notification not found", http.StatusNotFound)
+	case err.Error() == "question not found":
+		http.Error(w, "Question not found", http.StatusNotFound)
+	case err.Error() == "user_id cannot be empty",
+		strings.HasPrefix(err.Error(), "limit must"),
+		strings.HasPrefix(err.Error(), "unknown notification kind"):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
@@ -4,6 +4,9 @@ import (
 	"encoding/json"
 	"log"
 	"net/http"
+	"qa-api/internal/auth"
+	"qa-api/internal/models"
+	"qa-api/internal/presenter"
 	"qa-api/internal/service"
 	"strconv"
 
@@ -22,33 +25,47 @@ func NewQuestionHandler(questionService service.QuestionServiceInterface) *Quest
 	}
 }
 
-// CreateQuestionRequest represents the request body for creating a question
+// CreateQuestionRequest represents the request body for creating a question.
+// UserID names the author when the request is not authenticated.
 type CreateQuestionRequest struct {
-	Text string `json:"text"`
+	UserID string `json:"user_id,omitempty" validate:"userid"`
+	Text   string `json:"text" validate:"required,max=2000,nocontrol"`
 }
 
 // GetQuestions handles GET /questions/
 func (h *QuestionHandler) GetQuestions(w http.ResponseWriter, r *http.Request) {
-	questions, err := h.questionService.GetAllQuestions()
+	opts, ok := parseOptions(w, r, presenter.QuestionResource)
+	if !ok {
+		return
+	}
+
+	var questions []models.Question
+	var err error
+	if opts.Includes("answers") {
+		questions, err = h.questionService.GetAllQuestionsWithAnswers()
+	} else {
+		questions, err = h.questionService.GetAllQuestions()
+	}
 	if err != nil {
 		log.Printf("Error getting questions: %v", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
+	if notModified(w, r, listETag(questions), opts) {
+		return
+	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(questions)
+	writeSelected(w, opts, presenter.NewQuestions(questions, opts))
 }
 
 // CreateQuestion handles POST /questions/
 func (h *QuestionHandler) CreateQuestion(w http.ResponseWriter, r *http.Request) {
 	var req CreateQuestionRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+	if !decodeJSON(w, r, &req) {
 		return
 	}
 
-	question, err := h.questionService.CreateQuestion(req.Text)
+	question, err := h.questionService.CreateQuestion(actingUser(r, req.UserID), req.Text)
 	if err != nil {
 		log.Printf("Error creating question: %v", err)
 		http.Error(w, err.Error(), http.StatusBadRequest)
@@ -56,8 +73,9 @@ func (h *QuestionHandler) CreateQuestion(w http.ResponseWriter, r *http.Request)
 	}
 
 	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", versionETag(question.Version))
 	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(question)
+	json.NewEncoder(w).Encode(presenter.NewQuestion(question, presenter.Options{}))
 }
 
 // GetQuestion handles GET /questions/{id}
@@ -69,15 +87,32 @@ func (h *QuestionHandler) GetQuestion(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	opts, ok := parseOptions(w, r, presenter.QuestionResource, "answers")
+	if !ok {
+		return
+	}
+
 	question, err := h.questionService.GetQuestionByID(id)
 	if err != nil {
 		log.Printf("Error getting question: %v", err)
 		http.Error(w, "Question not found", http.StatusNotFound)
 		return
 	}
+	if notModified(w, r, versionETag(question.Version), opts) {
+		return
+	}
+	recordView(h.questionService, r, id)
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(question)
+	writeSelected(w, opts, presenter.NewQuestion(question, opts))
+}
+
+// recordView counts a view of a question served in full; revalidations
+// answered with 304 are not counted. Viewers are told apart by user ID;
+// the service ignores anonymous ones. A failure does not fail the request.
+func recordView(questionService service.QuestionServiceInterface, r *http.Request, id int) {
+	if err := questionService.RecordView(id, auth.UserID(r.Context())); err != nil {
+		log.Printf("Error recording view of question %d: %v", id, err)
+	}
 }
 
 // DeleteQuestion handles DELETE /questions/{id}
@@ -89,10 +124,17 @@ func (h *QuestionHandler) DeleteQuestion(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	if err := h.questionService.DeleteQuestion(id); err != nil {
+	version, ok := ifMatchVersion(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.questionService.DeleteQuestion(id, version); err != nil {
 		log.Printf("Error deleting question: %v", err)
 		if err.Error() == "question not found" {
 			http.Error(w, "Question not found", http.StatusNotFound)
+		} else if err.Error() == "version mismatch" {
+			http.Error(w, "Question has been modified", http.StatusPreconditionFailed)
 		} else {
 			http.Error(w, "Internal server error", http.StatusInternalServerError)
 		}
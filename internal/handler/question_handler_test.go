@@ -5,10 +5,12 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"qa-api/internal/auth"
 	"qa-api/internal/models"
 	"qa-api/internal/service"
 	"testing"
 
+	"github.com/gorilla/mux"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -21,8 +23,8 @@ type MockQuestionService struct {
 // Ensure MockQuestionService implements QuestionServiceInterface
 var _ service.QuestionServiceInterface = (*MockQuestionService)(nil)
 
-func (m *MockQuestionService) CreateQuestion(text string) (*models.Question, error) {
-	args := m.Called(text)
+func (m *MockQuestionService) CreateQuestion(userID, text string) (*models.Question, error) {
+	args := m.Called(userID, text)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -37,6 +39,14 @@ func (m *MockQuestionService) GetAllQuestions() ([]models.Question, error) {
 	return args.Get(0).([]models.Question), args.Error(1)
 }
 
+func (m *MockQuestionService) GetAllQuestionsWithAnswers() ([]models.Question, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Question), args.Error(1)
+}
+
 func (m *MockQuestionService) GetQuestionByID(id int) (*models.Question, error) {
 	args := m.Called(id)
 	if args.Get(0) == nil {
@@ -45,8 +55,13 @@ func (m *MockQuestionService) GetQuestionByID(id int) (*models.Question, error)
 	return args.Get(0).(*models.Question), args.Error(1)
 }
 
-func (m *MockQuestionService) DeleteQuestion(id int) error {
-	args := m.Called(id)
+func (m *MockQuestionService) DeleteQuestion(id, version int) error {
+	args := m.Called(id, version)
+	return args.Error(0)
+}
+
+func (m *MockQuestionService) RecordView(id int, viewer string) error {
+	args := m.Called(id, viewer)
 	return args.Error(0)
 }
 
@@ -59,7 +74,7 @@ func TestQuestionHandler_CreateQuestion(t *testing.T) {
 			ID:   1,
 			Text: "Test question",
 		}
-		mockService.On("CreateQuestion", "Test question").Return(expectedQuestion, nil)
+		mockService.On("CreateQuestion", "", "Test question").Return(expectedQuestion, nil)
 
 		reqBody := CreateQuestionRequest{Text: "Test question"}
 		jsonBody, _ := json.Marshal(reqBody)
@@ -74,7 +89,7 @@ func TestQuestionHandler_CreateQuestion(t *testing.T) {
 	})
 
 	t.Run("empty text", func(t *testing.T) {
-		mockService.On("CreateQuestion", "").Return(nil, assert.AnError)
+		mockService.On("CreateQuestion", "", "").Return(nil, assert.AnError)
 
 		reqBody := CreateQuestionRequest{Text: ""}
 		jsonBody, _ := json.Marshal(reqBody)
@@ -109,3 +124,28 @@ func TestQuestionHandler_GetQuestions(t *testing.T) {
 	})
 }
 
+
+func TestQuestionHandler_GetQuestionRecordsView(t *testing.T) {
+	mockService := new(MockQuestionService)
+	handler := NewQuestionHandler(mockService)
+	mockService.On("GetQuestionByID", 1).Return(&models.Question{ID: 1, Text: "Question 1", Version: 2}, nil)
+	mockService.On("RecordView", 1, "").Return(assert.AnError).Once()
+
+	req := mux.SetURLVars(httptest.NewRequest("GET", "/questions/1", nil), map[string]string{"id": "1"})
+	w := httptest.NewRecorder()
+	handler.GetQuestion(w, req)
+	assert.Equal(t, http.StatusOK, w.Code, "a failed view count does not fail the request")
+
+	// Revalidations are not views
+	req = mux.SetURLVars(httptest.NewRequest("GET", "/questions/1", nil), map[string]string{"id": "1"})
+	req.Header.Set("If-None-Match", `W/"2"`)
+	w = httptest.NewRecorder()
+	handler.GetQuestion(w, req)
+	assert.Equal(t, http.StatusNotModified, w.Code)
+
+	// Authenticated viewers are told apart by user ID
+	mockService.On("RecordView", 1, "alice").Return(nil).Once()
+	req = mux.SetURLVars(httptest.NewRequest("GET", "/questions/1", nil), map[string]string{"id": "1"})
+	handler.GetQuestion(httptest.NewRecorder(), req.WithContext(auth.WithUserID(req.Context(), "alice")))
+	mockService.AssertExpectations(t)
+}
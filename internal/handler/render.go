@@ -0,0 +1,32 @@
+package handler
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"qa-api/internal/presenter"
+)
+
+// parseOptions reads ?fields= and ?include= for the resource. On failure it
+// writes 400 Bad Request and returns false.
+func parseOptions(w http.ResponseWriter, r *http.Request, resource presenter.Resource, defaultInclude ...string) (presenter.Options, bool) {
+	opts, err := presenter.ParseOptions(r.URL.Query(), resource, defaultInclude...)
+	if err != nil {
+		http.Error(w, "Invalid query: "+err.Error(), http.StatusBadRequest)
+		return presenter.Options{}, false
+	}
+	return opts, true
+}
+
+// writeSelected writes a DTO reduced to the requested sparse fieldset
+func writeSelected(w http.ResponseWriter, opts presenter.Options, dto interface{}) {
+	body, err := opts.Select(dto)
+	if err != nil {
+		log.Printf("Error rendering response: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,191 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"qa-api/internal/events"
+	"qa-api/internal/service"
+	"qa-api/internal/stream"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	streamBuffer     = 64
+	streamReplayPage = 100
+	// streamSeenWindow is how far behind the newest event sent an event may
+	// be committed and still be delivered; older ones count as sent
+	streamSeenWindow = 1024
+)
+
+// sentEvents remembers the events sent on a stream. Only IDs within the
+// window below the highest one are kept, so long streams use bounded memory.
+type sentEvents struct {
+	ids     map[int64]bool
+	highest int64
+}
+
+// add records an event and reports whether it was not sent yet
+func (s *sentEvents) add(id int64) bool {
+	if s.ids[id] || id <= s.highest-streamSeenWindow {
+		return false
+	}
+	s.ids[id] = true
+	if id > s.highest {
+		s.highest = id
+	}
+	if len(s.ids) > 2*streamSeenWindow {
+		for seen := range s.ids {
+			if seen <= s.highest-streamSeenWindow {
+				delete(s.ids, seen)
+			}
+		}
+	}
+	return true
+}
+
+// StreamHandler handles Server-Sent Events streams
+type StreamHandler struct {
+	questionService service.QuestionServiceInterface
+	broker          *stream.Broker
+	history         stream.History
+	heartbeat       time.Duration
+}
+
+// NewStreamHandler creates a new StreamHandler
+func NewStreamHandler(questionService service.QuestionServiceInterface, broker *stream.Broker, history stream.History) *StreamHandler {
+	return &StreamHandler{
+		questionService: questionService,
+		broker:          broker,
+		history:         history,
+		heartbeat:       15 * time.Second,
+	}
+}
+
+// QuestionEvents handles GET /questions/{id}/events
+func (h *StreamHandler) QuestionEvents(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid question ID", http.StatusBadRequest)
+		return
+	}
+
+	lastEventID, err := parseLastEventID(r)
+	if err != nil {
+		http.Error(w, "Invalid Last-Event-ID", http.StatusBadRequest)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	if _, err := h.questionService.GetQuestionByID(id); err != nil {
+		log.Printf("Error getting question: %v", err)
+		http.Error(w, "Question not found", http.StatusNotFound)
+		return
+	}
+
+	// Subscribe before replaying history so no event is lost in between
+	connectedAt := time.Now()
+	match := stream.ForQuestion(id)
+	sub := h.broker.Subscribe(match, streamBuffer)
+	defer h.broker.Unsubscribe(sub)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, "retry: 3000\n\n")
+	flusher.Flush()
+
+	// Every event is sent once. IDs are not committed in order, so a live
+	// event may be older than one already sent and is not skipped for that.
+	sent := &sentEvents{ids: make(map[int64]bool)}
+	send := func(event events.Event) {
+		if !match(event) || !sent.add(event.ID) {
+			return
+		}
+		writeSSE(w, event)
+	}
+	// catchUp sends the stored events after the resume position that were
+	// not sent yet; without one, those since the stream started
+	catchUp := func() bool {
+		afterID := lastEventID
+		for {
+			batch, err := h.history.EventsSince(id, afterID, streamReplayPage)
+			if err != nil {
+				log.Printf("Error replaying events: %v", err)
+				return false
+			}
+			for _, event := range batch {
+				afterID = event.ID
+				if lastEventID > 0 || !event.OccurredAt.Before(connectedAt) {
+					send(event)
+				}
+			}
+			flusher.Flush()
+			if len(batch) < streamReplayPage {
+				return true
+			}
+		}
+	}
+
+	if lastEventID > 0 && !catchUp() {
+		return
+	}
+
+	heartbeat := time.NewTicker(h.heartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case event, ok := <-sub.Events():
+			if !ok {
+				// Too slow to keep up: the client reconnects with Last-Event-ID
+				return
+			}
+			send(event)
+			flusher.Flush()
+		case <-sub.Gaps():
+			if !catchUp() {
+				return
+			}
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": heartbeat\n\n")
+			flusher.Flush()
+		}
+	}
+}
+
+// parseLastEventID reads the resume position from the Last-Event-ID header or
+// the last_event_id query parameter
+func parseLastEventID(r *http.Request) (int64, error) {
+	value := r.Header.Get("Last-Event-ID")
+	if value == "" {
+		value = r.URL.Query().Get("last_event_id")
+	}
+	if value == "" {
+		return 0, nil
+	}
+	return strconv.ParseInt(value, 10, 64)
+}
+
+func writeSSE(w http.ResponseWriter, event events.Event) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		log.Printf("Error encoding event %d: %v", event.ID, err)
+		return
+	}
+	fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+}
@@ -0,0 +1,146 @@
+package handler
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"qa-api/internal/events"
+	"qa-api/internal/models"
+	"qa-api/internal/stream"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryHistory is an in-memory implementation of stream.History
+type memoryHistory struct {
+	mu     sync.Mutex
+	events []events.Event
+}
+
+func (h *memoryHistory) add(event events.Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.events = append(h.events, event)
+	sort.Slice(h.events, func(i, j int) bool { return h.events[i].ID < h.events[j].ID })
+}
+
+func (h *memoryHistory) EventsSince(questionID int, afterID int64, limit int) ([]events.Event, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	var result []events.Event
+	for _, e := range h.events {
+		if e.QuestionID == questionID && e.ID > afterID {
+			result = append(result, e)
+		}
+	}
+	return result, nil
+}
+
+func TestStreamHandler_QuestionEvents(t *testing.T) {
+	mockService := new(MockQuestionService)
+	mockService.On("GetQuestionByID", 1).Return(&models.Question{ID: 1}, nil)
+	mockService.On("GetQuestionByID", 2).Return(nil, assert.AnError)
+
+	broker := stream.NewBroker()
+	history := &memoryHistory{events: []events.Event{
+		{ID: 4, Type: events.AnswerCreated, QuestionID: 1},
+		{ID: 5, Type: events.AnswerDeleted, QuestionID: 1},
+	}}
+	handler := NewStreamHandler(mockService, broker, history)
+
+	router := mux.NewRouter()
+	router.HandleFunc("/questions/{id}/events", handler.QuestionEvents)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	t.Run("question not found", func(t *testing.T) {
+		resp, err := http.Get(server.URL + "/questions/2/events")
+		require.NoError(t, err)
+		resp.Body.Close()
+
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+
+	t.Run("resume and live events", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", server.URL+"/questions/1/events", nil)
+		req.Header.Set("Last-Event-ID", "4")
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+		reader := bufio.NewReader(resp.Body)
+		nextID := func() string {
+			for {
+				line, err := reader.ReadString('\n')
+				require.NoError(t, err)
+				if strings.HasPrefix(line, "id: ") {
+					return strings.TrimSpace(strings.TrimPrefix(line, "id: "))
+				}
+			}
+		}
+
+		// Replayed from history
+		assert.Equal(t, "5", nextID())
+
+		// Duplicates of replayed events and other questions are skipped
+		broker.Publish(events.Event{ID: 5, Type: events.AnswerDeleted, QuestionID: 1})
+		broker.Publish(events.Event{ID: 6, Type: events.AnswerCreated, QuestionID: 2})
+		broker.Publish(events.Event{ID: 7, Type: events.AnswerCreated, QuestionID: 1})
+		assert.Equal(t, "7", nextID())
+
+		// IDs are not committed in order: a late older event still arrives
+		broker.Publish(events.Event{ID: 3, Type: events.AnswerCreated, QuestionID: 1})
+		assert.Equal(t, "3", nextID())
+
+		// Events missed while the feed was down are caught up from history
+		history.add(events.Event{ID: 7, Type: events.AnswerCreated, QuestionID: 1})
+		history.add(events.Event{ID: 8, Type: events.AnswerCreated, QuestionID: 1})
+		broker.Gap()
+		assert.Equal(t, "8", nextID())
+		broker.Publish(events.Event{ID: 9, Type: events.AnswerCreated, QuestionID: 1})
+		assert.Equal(t, "9", nextID())
+	})
+
+	t.Run("catching up a fresh stream skips older history", func(t *testing.T) {
+		resp, err := http.Get(server.URL + "/questions/1/events")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		reader := bufio.NewReader(resp.Body)
+		_, err = reader.ReadString('\n')
+		require.NoError(t, err)
+
+		history.add(events.Event{ID: 10, Type: events.AnswerCreated, QuestionID: 1, OccurredAt: time.Now()})
+		broker.Gap()
+		for {
+			line, err := reader.ReadString('\n')
+			require.NoError(t, err)
+			if strings.HasPrefix(line, "id: ") {
+				assert.Equal(t, "id: 10\n", line)
+				return
+			}
+		}
+	})
+}
+
+func TestSentEvents(t *testing.T) {
+	sent := &sentEvents{ids: make(map[int64]bool)}
+	assert.True(t, sent.add(5))
+	assert.False(t, sent.add(5))
+	assert.True(t, sent.add(3), "late older events are sent")
+
+	for id := int64(6); id < 5*streamSeenWindow; id++ {
+		require.True(t, sent.add(id))
+	}
+	assert.LessOrEqual(t, len(sent.ids), 2*streamSeenWindow+1, "old IDs are forgotten")
+	assert.False(t, sent.add(4), "events far behind count as sent")
+	assert.False(t, sent.add(5*streamSeenWindow-1))
+}
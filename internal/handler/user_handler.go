@@ -0,0 +1,138 @@
+package handler
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"qa-api/internal/auth"
+	"qa-api/internal/models"
+	"qa-api/internal/presenter"
+	"qa-api/internal/service"
+	"qa-api/internal/validation"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// defaultLeaderboardSize is the leaderboard length without ?limit=
+const defaultLeaderboardSize = 10
+
+// UserHandler handles HTTP requests for user profiles and the leaderboard
+type UserHandler struct {
+	userService service.UserServiceInterface
+}
+
+// NewUserHandler creates a new UserHandler
+func NewUserHandler(userService service.UserServiceInterface) *UserHandler {
+	return &UserHandler{
+		userService: userService,
+	}
+}
+
+// UpdateUserRequest represents the request body for updating a profile
+type UpdateUserRequest struct {
+	DisplayName string `json:"display_name" validate:"max=100,nocontrol"`
+	AvatarURL   string `json:"avatar_url" validate:"max=2000,url"`
+	Bio         string `json:"bio" validate:"max=1000,nocontrol"`
+}
+
+// userPath is the user ID in the path, checked like user_id in bodies
+type userPath struct {
+	ID string `json:"id" validate:"required,userid"`
+}
+
+// actingUser returns the user a request acts for: the authenticated user,
+// or the user_id the client named when authentication is disabled
+func actingUser(r *http.Request, userID string) string {
+	if authenticated := auth.UserID(r.Context()); authenticated != "" {
+		return authenticated
+	}
+	return userID
+}
+
+// GetUser handles GET /users/{id}
+func (h *UserHandler) GetUser(w http.ResponseWriter, r *http.Request) {
+	path := userPath{ID: mux.Vars(r)["id"]}
+	if errs := validation.Struct(&path); errs != nil {
+		writeValidationErrors(w, errs)
+		return
+	}
+
+	h.writeProfile(w, path.ID)
+}
+
+// UpdateUser handles PUT /users/{id}. With authentication enabled users can
+// only edit their own profile.
+func (h *UserHandler) UpdateUser(w http.ResponseWriter, r *http.Request) {
+	path := userPath{ID: mux.Vars(r)["id"]}
+	if errs := validation.Struct(&path); errs != nil {
+		writeValidationErrors(w, errs)
+		return
+	}
+	if authenticated := auth.UserID(r.Context()); authenticated != "" && authenticated != path.ID {
+		http.Error(w, "Cannot edit another user's profile", http.StatusForbidden)
+		return
+	}
+
+	var req UpdateUserRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	user := &models.User{
+		ID:          path.ID,
+		DisplayName: req.DisplayName,
+		AvatarURL:   req.AvatarURL,
+		Bio:         req.Bio,
+	}
+	if err := h.userService.UpdateProfile(user); err != nil {
+		log.Printf("Error updating user: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	h.writeProfile(w, path.ID)
+}
+
+// GetLeaderboard handles GET /leaderboard
+func (h *UserHandler) GetLeaderboard(w http.ResponseWriter, r *http.Request) {
+	limit := defaultLeaderboardSize
+	if value := r.URL.Query().Get("limit"); value != "" {
+		var err error
+		if limit, err = strconv.Atoi(value); err != nil {
+			http.Error(w, "Invalid query: limit must be an integer", http.StatusBadRequest)
+			return
+		}
+	}
+
+	standings, err := h.userService.Leaderboard(limit)
+	if err != nil {
+		log.Printf("Error getting leaderboard: %v", err)
+		if strings.HasPrefix(err.Error(), "limit must") {
+			http.Error(w, "Invalid query: "+err.Error(), http.StatusBadRequest)
+		} else {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(standings)
+}
+
+func (h *UserHandler) writeProfile(w http.ResponseWriter, id string) {
+	profile, err := h.userService.GetProfile(id)
+	if err != nil {
+		log.Printf("Error getting user: %v", err)
+		if err.Error() == "user not found" {
+			http.Error(w, "User not found", http.StatusNotFound)
+		} else {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(presenter.NewUser(&profile.User, profile.Reputation, profile.Questions, profile.Answers))
+}
@@ -0,0 +1,180 @@
+package handler
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"qa-api/internal/models"
+	"qa-api/internal/presenter"
+	"qa-api/internal/service"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// MediaTypeV2 is the vendor media type of v2 responses; clients may also
+// request it with the Accept header on unversioned paths
+const MediaTypeV2 = "application/vnd.qa.v2+json"
+
+// V2Handler serves the v2 representations of questions and answers.
+// Deletions have no body and are served by the v1 handlers.
+type V2Handler struct {
+	questionService service.QuestionServiceInterface
+	answerService   service.AnswerServiceInterface
+}
+
+// NewV2Handler creates a new V2Handler
+func NewV2Handler(questionService service.QuestionServiceInterface, answerService service.AnswerServiceInterface) *V2Handler {
+	return &V2Handler{
+		questionService: questionService,
+		answerService:   answerService,
+	}
+}
+
+// GetQuestions handles GET /v2/questions/
+func (h *V2Handler) GetQuestions(w http.ResponseWriter, r *http.Request) {
+	opts, ok := parseOptions(w, r, presenter.QuestionV2Resource)
+	if !ok {
+		return
+	}
+
+	questions, err := h.listQuestions(opts)
+	if err != nil {
+		log.Printf("Error getting questions: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if notModified(w, r, listETag(questions), opts) {
+		return
+	}
+
+	data, err := opts.Select(presenter.NewQuestionsV2(questions, opts))
+	if err != nil {
+		log.Printf("Error rendering questions: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	writeV2(w, http.StatusOK, presenter.ListV2{Data: data})
+}
+
+// CreateQuestion handles POST /v2/questions/
+func (h *V2Handler) CreateQuestion(w http.ResponseWriter, r *http.Request) {
+	var req CreateQuestionRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	question, err := h.questionService.CreateQuestion(actingUser(r, req.UserID), req.Text)
+	if err != nil {
+		log.Printf("Error creating question: %v", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("ETag", versionETag(question.Version))
+	writeV2(w, http.StatusCreated, presenter.NewQuestionV2(question, presenter.Options{}))
+}
+
+// GetQuestion handles GET /v2/questions/{id}
+func (h *V2Handler) GetQuestion(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid question ID", http.StatusBadRequest)
+		return
+	}
+
+	opts, ok := parseOptions(w, r, presenter.QuestionV2Resource, "answers")
+	if !ok {
+		return
+	}
+
+	question, err := h.questionService.GetQuestionByID(id)
+	if err != nil {
+		log.Printf("Error getting question: %v", err)
+		http.Error(w, "Question not found", http.StatusNotFound)
+		return
+	}
+	if notModified(w, r, versionETag(question.Version), opts) {
+		return
+	}
+	recordView(h.questionService, r, id)
+
+	writeSelectedV2(w, opts, presenter.NewQuestionV2(question, opts))
+}
+
+// CreateAnswer handles POST /v2/questions/{id}/answers/
+func (h *V2Handler) CreateAnswer(w http.ResponseWriter, r *http.Request) {
+	questionID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid question ID", http.StatusBadRequest)
+		return
+	}
+
+	var req CreateAnswerRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	answer, err := h.answerService.CreateAnswer(questionID, actingUser(r, req.UserID), req.Text)
+	if err != nil {
+		log.Printf("Error creating answer: %v", err)
+		if err.Error() == "question not found" {
+			http.Error(w, "Question not found", http.StatusNotFound)
+		} else {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		}
+		return
+	}
+
+	w.Header().Set("ETag", versionETag(answer.Version))
+	writeV2(w, http.StatusCreated, presenter.NewAnswerV2(answer))
+}
+
+// GetAnswer handles GET /v2/answers/{id}
+func (h *V2Handler) GetAnswer(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid answer ID", http.StatusBadRequest)
+		return
+	}
+
+	opts, ok := parseOptions(w, r, presenter.AnswerV2Resource)
+	if !ok {
+		return
+	}
+
+	answer, err := h.answerService.GetAnswerByID(id)
+	if err != nil {
+		log.Printf("Error getting answer: %v", err)
+		http.Error(w, "Answer not found", http.StatusNotFound)
+		return
+	}
+	if notModified(w, r, versionETag(answer.Version), opts) {
+		return
+	}
+
+	writeSelectedV2(w, opts, presenter.NewAnswerV2(answer))
+}
+
+func (h *V2Handler) listQuestions(opts presenter.Options) ([]models.Question, error) {
+	if opts.Includes("answers") {
+		return h.questionService.GetAllQuestionsWithAnswers()
+	}
+	return h.questionService.GetAllQuestions()
+}
+
+func writeSelectedV2(w http.ResponseWriter, opts presenter.Options, dto interface{}) {
+	body, err := opts.Select(dto)
+	if err != nil {
+		log.Printf("Error rendering response: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	writeV2(w, http.StatusOK, body)
+}
+
+func writeV2(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", MediaTypeV2)
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
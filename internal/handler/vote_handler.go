@@ -0,0 +1,118 @@
+package handler
+
+import (
+	"log"
+	"net/http"
+	"qa-api/internal/flags"
+	"qa-api/internal/service"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// VoteHandler handles HTTP requests for votes and accepted answers
+type VoteHandler struct {
+	voteService service.VoteServiceInterface
+	flags       flags.Checker
+}
+
+// NewVoteHandler creates a new VoteHandler; voting is only open to the
+// users the voting feature flag is enabled for
+func NewVoteHandler(voteService service.VoteServiceInterface, flags flags.Checker) *VoteHandler {
+	return &VoteHandler{
+		voteService: voteService,
+		flags:       flags,
+	}
+}
+
+// VoteRequest represents the request body for voting on an answer. UserID
+// names the voter when the request is not authenticated.
+type VoteRequest struct {
+	UserID string `json:"user_id,omitempty" validate:"userid"`
+	Value  int    `json:"value"`
+}
+
+// Vote handles PUT /answers/{id}/vote
+func (h *VoteHandler) Vote(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid answer ID", http.StatusBadRequest)
+		return
+	}
+
+	var req VoteRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	userID := actingUser(r, req.UserID)
+	if !h.votingEnabled(w, userID) {
+		return
+	}
+
+	if err := h.voteService.Vote(id, userID, req.Value); err != nil {
+		writeVoteError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Retract handles DELETE /answers/{id}/vote; unauthenticated clients name
+// the voter with ?user_id=
+func (h *VoteHandler) Retract(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid answer ID", http.StatusBadRequest)
+		return
+	}
+
+	userID := actingUser(r, r.URL.Query().Get("user_id"))
+	if !h.votingEnabled(w, userID) {
+		return
+	}
+
+	if err := h.voteService.Retract(id, userID); err != nil {
+		writeVoteError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Accept handles POST /answers/{id}/accept; unauthenticated clients name
+// the question author with ?user_id=
+func (h *VoteHandler) Accept(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid answer ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.voteService.Accept(id, actingUser(r, r.URL.Query().Get("user_id"))); err != nil {
+		writeVoteError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// votingEnabled checks the voting flag for the user. When it is off it
+// writes 403 Forbidden and returns false.
+func (h *VoteHandler) votingEnabled(w http.ResponseWriter, userID string) bool {
+	if h.flags.Enabled(flags.Voting, userID) {
+		return true
+	}
+	http.Error(w, "Voting is not enabled", http.StatusForbidden)
+	return false
+}
+
+func writeVoteError(w http.ResponseWriter, err error) {
+	log.Printf("Error voting: %v", err)
+	switch err.Error() {
+	case "answer not found":
+		http.Error(w, "Answer not found", http.StatusNotFound)
+	case "cannot vote on your own answer", "only the author of the question can accept an answer":
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case "vote must be 1 or -1", "user_id cannot be empty":
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,253 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"qa-api/internal/auth"
+	"qa-api/internal/events"
+	"qa-api/internal/stream"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// Slow consumer policies for WebSocket connections
+const (
+	SlowConsumerDrop       = "drop"
+	SlowConsumerDisconnect = "disconnect"
+)
+
+const (
+	wsSendBuffer   = 64
+	wsMaxTopics    = 100
+	wsMaxMessage   = 4096
+	wsWriteTimeout = 10 * time.Second
+	wsPongTimeout  = 60 * time.Second
+	wsPingInterval = 50 * time.Second
+)
+
+var errTooManyTopics = errors.New("too many topics")
+
+// WebSocketHandler serves real-time event feeds over WebSocket
+type WebSocketHandler struct {
+	broker   *stream.Broker
+	policy   string
+	upgrader websocket.Upgrader
+}
+
+// NewWebSocketHandler creates a new WebSocketHandler. policy decides what
+// happens when a client does not read fast enough: SlowConsumerDrop skips
+// events and reports the number skipped, SlowConsumerDisconnect closes the socket.
+func NewWebSocketHandler(broker *stream.Broker, policy string) *WebSocketHandler {
+	if policy != SlowConsumerDisconnect {
+		policy = SlowConsumerDrop
+	}
+	return &WebSocketHandler{
+		broker: broker,
+		policy: policy,
+	}
+}
+
+// wsRequest is a message sent by the client
+type wsRequest struct {
+	Action string `json:"action"`
+	Topic  string `json:"topic"`
+}
+
+// wsMessage is a message sent to the client
+type wsMessage struct {
+	Type    string        `json:"type"`
+	Topic   string        `json:"topic,omitempty"`
+	Topics  []string      `json:"topics,omitempty"`
+	Event   *events.Event `json:"event,omitempty"`
+	UserID  string        `json:"user_id,omitempty"`
+	Dropped int           `json:"dropped,omitempty"`
+	Error   string        `json:"error,omitempty"`
+}
+
+// wsClient holds the state of a single connection
+type wsClient struct {
+	conn   *websocket.Conn
+	policy string
+	send   chan wsMessage
+	done   chan struct{}
+
+	mu      sync.Mutex
+	topics  map[string]func(events.Event) bool
+	dropped int
+}
+
+// Serve handles GET /ws. Authentication is performed by the HTTP middleware
+// before the connection is upgraded.
+func (h *WebSocketHandler) Serve(w http.ResponseWriter, r *http.Request) {
+	conn, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Printf("Error upgrading WebSocket connection: %v", err)
+		return
+	}
+
+	client := &wsClient{
+		conn:   conn,
+		policy: h.policy,
+		send:   make(chan wsMessage, wsSendBuffer),
+		done:   make(chan struct{}),
+		topics: make(map[string]func(events.Event) bool),
+	}
+
+	sub := h.broker.Subscribe(client.matches, wsSendBuffer)
+	go client.pump(sub)
+	go client.writeLoop()
+
+	client.enqueue(wsMessage{Type: "welcome", UserID: auth.UserID(r.Context())})
+	client.readLoop()
+
+	close(client.done)
+	h.broker.Unsubscribe(sub)
+	conn.Close()
+}
+
+// readLoop processes subscribe/unsubscribe requests until the connection closes
+func (c *wsClient) readLoop() {
+	c.conn.SetReadLimit(wsMaxMessage)
+	c.conn.SetReadDeadline(time.Now().Add(wsPongTimeout))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(wsPongTimeout))
+	})
+
+	for {
+		_, data, err := c.conn.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		var req wsRequest
+		if err := json.Unmarshal(data, &req); err != nil {
+			c.enqueue(wsMessage{Type: "error", Error: "invalid message"})
+			continue
+		}
+
+		switch req.Action {
+		case "subscribe":
+			if err := c.subscribe(req.Topic); err != nil {
+				c.enqueue(wsMessage{Type: "error", Topic: req.Topic, Error: err.Error()})
+				continue
+			}
+			c.enqueue(wsMessage{Type: "subscribed", Topic: req.Topic})
+		case "unsubscribe":
+			c.mu.Lock()
+			delete(c.topics, req.Topic)
+			c.mu.Unlock()
+			c.enqueue(wsMessage{Type: "unsubscribed", Topic: req.Topic})
+		default:
+			c.enqueue(wsMessage{Type: "error", Error: "unknown action"})
+		}
+	}
+}
+
+func (c *wsClient) subscribe(topic string) error {
+	match, err := stream.ParseTopic(topic)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.topics[topic]; !ok && len(c.topics) >= wsMaxTopics {
+		return errTooManyTopics
+	}
+	c.topics[topic] = match
+	return nil
+}
+
+// matches reports whether any subscribed topic accepts the event
+func (c *wsClient) matches(event events.Event) bool {
+	return len(c.matchingTopics(event)) > 0
+}
+
+func (c *wsClient) matchingTopics(event events.Event) []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var topics []string
+	for topic, match := range c.topics {
+		if match(event) {
+			topics = append(topics, topic)
+		}
+	}
+	sort.Strings(topics)
+	return topics
+}
+
+// pump forwards broker events to the send queue without blocking the broker
+func (c *wsClient) pump(sub *stream.Subscription) {
+	for event := range sub.Events() {
+		topics := c.matchingTopics(event)
+		if len(topics) == 0 {
+			continue
+		}
+		event := event
+		c.enqueue(wsMessage{Type: "event", Topics: topics, Event: &event})
+	}
+}
+
+// enqueue queues a message, applying the slow consumer policy when the queue is full
+func (c *wsClient) enqueue(msg wsMessage) {
+	select {
+	case c.send <- msg:
+	case <-c.done:
+	default:
+		if c.policy == SlowConsumerDisconnect {
+			c.conn.WriteControl(websocket.CloseMessage,
+				websocket.FormatCloseMessage(websocket.CloseTryAgainLater, "slow consumer"),
+				time.Now().Add(wsWriteTimeout))
+			c.conn.Close()
+			return
+		}
+		c.mu.Lock()
+		c.dropped++
+		c.mu.Unlock()
+	}
+}
+
+// writeLoop is the only goroutine writing data frames to the connection
+func (c *wsClient) writeLoop() {
+	ping := time.NewTicker(wsPingInterval)
+	defer ping.Stop()
+
+	for {
+		select {
+		case <-c.done:
+			return
+		case msg := <-c.send:
+			c.mu.Lock()
+			dropped := c.dropped
+			c.dropped = 0
+			c.mu.Unlock()
+			if dropped > 0 {
+				if !c.write(wsMessage{Type: "dropped", Dropped: dropped}) {
+					return
+				}
+			}
+			if !c.write(msg) {
+				return
+			}
+		case <-ping.C:
+			if err := c.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteTimeout)); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (c *wsClient) write(msg wsMessage) bool {
+	c.conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+	if err := c.conn.WriteJSON(msg); err != nil {
+		c.conn.Close()
+		return false
+	}
+	return true
+}
@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"qa-api/internal/auth"
+	"qa-api/internal/events"
+	"qa-api/internal/stream"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebSocketHandler_Serve(t *testing.T) {
+	broker := stream.NewBroker()
+	handler := NewWebSocketHandler(broker, SlowConsumerDrop)
+	authenticator := auth.NewAuthenticator("secret-token:user-1")
+	server := httptest.NewServer(authenticator.Middleware(http.HandlerFunc(handler.Serve)))
+	defer server.Close()
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/ws"
+
+	t.Run("requires authentication", func(t *testing.T) {
+		_, resp, err := websocket.DefaultDialer.Dial(url, nil)
+		require.Error(t, err)
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+
+	t.Run("subscribe and receive events", func(t *testing.T) {
+		conn, _, err := websocket.DefaultDialer.Dial(url+"?access_token=secret-token", nil)
+		require.NoError(t, err)
+		defer conn.Close()
+		conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+		read := func() wsMessage {
+			var msg wsMessage
+			require.NoError(t, conn.ReadJSON(&msg))
+			return msg
+		}
+
+		welcome := read()
+		assert.Equal(t, "welcome", welcome.Type)
+		assert.Equal(t, "user-1", welcome.UserID)
+
+		require.NoError(t, conn.WriteJSON(wsRequest{Action: "subscribe", Topic: "bogus"}))
+		assert.Equal(t, "error", read().Type)
+
+		require.NoError(t, conn.WriteJSON(wsRequest{Action: "subscribe", Topic: "question:1"}))
+		assert.Equal(t, "subscribed", read().Type)
+		require.NoError(t, conn.WriteJSON(wsRequest{Action: "subscribe", Topic: "user:alice"}))
+		assert.Equal(t, "subscribed", read().Type)
+
+		payload, _ := json.Marshal(events.AnswerPayload{ID: 3, QuestionID: 1, UserID: "alice"})
+		broker.Publish(events.Event{ID: 1, Type: events.AnswerCreated, QuestionID: 2})
+		broker.Publish(events.Event{ID: 2, Type: events.AnswerCreated, AggregateType: events.AggregateAnswer, QuestionID: 1, Payload: payload})
+
+		msg := read()
+		assert.Equal(t, "event", msg.Type)
+		assert.Equal(t, []string{"question:1", "user:alice"}, msg.Topics)
+		require.NotNil(t, msg.Event)
+		assert.Equal(t, int64(2), msg.Event.ID)
+	})
+}
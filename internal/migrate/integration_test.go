@@ -0,0 +1,48 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"qa-api/internal/pgtest"
+	"qa-api/migrations"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMain(m *testing.M) {
+	os.Exit(pgtest.Run(m))
+}
+
+func TestIntegration_MigrationsRoundTrip(t *testing.T) {
+	db, err := sql.Open("pgx", pgtest.Schema(t))
+	require.NoError(t, err)
+	defer db.Close()
+	ctx := context.Background()
+
+	migrator, err := New(db, "postgres", migrations.FS)
+	require.NoError(t, err)
+	assert.ErrorContains(t, migrator.Check(ctx), "pending migrations")
+
+	results, err := migrator.Up(ctx)
+	require.NoError(t, err)
+	require.NotEmpty(t, results)
+	require.NoError(t, migrator.Check(ctx))
+
+	// Every Down must undo its Up, so the whole chain can be rolled back
+	// and applied again
+	for range results {
+		_, err := migrator.Down(ctx)
+		require.NoError(t, err)
+	}
+	var tables int
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = current_schema() AND table_name <> 'goose_db_version'`).Scan(&tables))
+	assert.Zero(t, tables)
+
+	again, err := migrator.Up(ctx)
+	require.NoError(t, err)
+	assert.Len(t, again, len(results))
+	assert.NoError(t, migrator.Check(ctx))
+}
@@ -0,0 +1,264 @@
+// Package migrate applies and inspects the goose SQL migrations. On
+// PostgreSQL every operation holds an advisory lock, so replicas starting at
+// the same time migrate one after another; SQLite serves a single node.
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pressly/goose/v3"
+	"github.com/pressly/goose/v3/lock"
+)
+
+// Dir is the source directory of the SQL migrations; binaries use the
+// copies embedded in package migrations
+const Dir = "migrations"
+
+// SQLiteDir is the subdirectory of a migrations directory with the SQLite
+// variants of its migrations
+const SQLiteDir = "sqlite"
+
+// Status is the state of a single migration
+type Status struct {
+	Version   int64      `json:"version"`
+	Name      string     `json:"name"`
+	Applied   bool       `json:"applied"`
+	AppliedAt *time.Time `json:"applied_at,omitempty"`
+}
+
+// Result describes a migration that was applied or rolled back
+type Result struct {
+	Version   int64         `json:"version"`
+	Name      string        `json:"name"`
+	Direction string        `json:"direction"`
+	Duration  time.Duration `json:"duration"`
+}
+
+// Migrator runs the migrations found in a file system against a database
+type Migrator struct {
+	provider *goose.Provider
+	db       *sql.DB
+	dialect  string
+}
+
+// New creates a Migrator for the database db of the given SQL dialect
+// ("postgres" or "sqlite"); fsys must hold the migrations of that dialect
+func New(db *sql.DB, dialect string, fsys fs.FS) (*Migrator, error) {
+	var provider *goose.Provider
+	var err error
+	switch dialect {
+	case "postgres":
+		locker, lockErr := lock.NewPostgresSessionLocker()
+		if lockErr != nil {
+			return nil, lockErr
+		}
+		provider, err = goose.NewProvider(goose.DialectPostgres, db, fsys, goose.WithSessionLocker(locker))
+	case "sqlite":
+		provider, err = goose.NewProvider(goose.DialectSQLite3, db, fsys)
+	default:
+		return nil, fmt.Errorf("unsupported SQL dialect %q", dialect)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load migrations: %w", err)
+	}
+	return &Migrator{provider: provider, db: db, dialect: dialect}, nil
+}
+
+// Up applies all pending migrations
+func (m *Migrator) Up(ctx context.Context) ([]Result, error) {
+	results, err := m.provider.Up(ctx)
+	return convertResults(results), err
+}
+
+// Down rolls back the most recently applied migration
+func (m *Migrator) Down(ctx context.Context) ([]Result, error) {
+	result, err := m.provider.Down(ctx)
+	if result == nil {
+		return nil, err
+	}
+	return convertResults([]*goose.MigrationResult{result}), err
+}
+
+// Status lists every known migration and whether it has been applied
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	statuses, err := m.provider.Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]Status, 0, len(statuses))
+	for _, s := range statuses {
+		status := Status{
+			Version: s.Source.Version,
+			Name:    filepath.Base(s.Source.Path),
+			Applied: s.State == goose.StateApplied,
+		}
+		if status.Applied {
+			appliedAt := s.AppliedAt
+			status.AppliedAt = &appliedAt
+		}
+		list = append(list, status)
+	}
+	return list, nil
+}
+
+// Check verifies that the schema is exactly at the latest known migration.
+// It writes nothing: a database without goose's version table is reported
+// as having every migration pending, and the table is not created.
+func (m *Migrator) Check(ctx context.Context) error {
+	exists, err := m.versionTableExists(ctx)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		var latest int64
+		var pending []string
+		for _, source := range m.provider.ListSources() {
+			latest = source.Version
+			pending = append(pending, filepath.Base(source.Path))
+		}
+		if len(pending) == 0 {
+			return nil
+		}
+		return fmt.Errorf("database schema is not versioned, expected %d; pending migrations: %s", latest, strings.Join(pending, ", "))
+	}
+
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		return err
+	}
+	current, err := m.provider.GetDBVersion(ctx)
+	if err != nil {
+		return err
+	}
+
+	var latest int64
+	var pending []string
+	for _, s := range statuses {
+		latest = s.Version
+		if !s.Applied {
+			pending = append(pending, s.Name)
+		}
+	}
+	if current > latest {
+		return fmt.Errorf("database schema is at version %d, newer than the latest known migration %d", current, latest)
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("database schema is at version %d, expected %d; pending migrations: %s", current, latest, strings.Join(pending, ", "))
+	}
+	return nil
+}
+
+// versionTableExists reports whether goose's version table is there; goose
+// itself would create it on the first query
+func (m *Migrator) versionTableExists(ctx context.Context) (bool, error) {
+	query := `SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?`
+	if m.dialect == "postgres" {
+		query = `SELECT COUNT(*) FROM pg_catalog.pg_tables WHERE schemaname = current_schema() AND tablename = $1`
+	}
+	var count int
+	if err := m.db.QueryRowContext(ctx, query, goose.DefaultTablename).Scan(&count); err != nil {
+		return false, fmt.Errorf("failed to look up the migration version table: %w", err)
+	}
+	return count > 0, nil
+}
+
+func convertResults(results []*goose.MigrationResult) []Result {
+	list := make([]Result, 0, len(results))
+	for _, r := range results {
+		list = append(list, Result{
+			Version:   r.Source.Version,
+			Name:      filepath.Base(r.Source.Path),
+			Direction: r.Direction,
+			Duration:  r.Duration,
+		})
+	}
+	return list
+}
+
+var (
+	versionPattern = regexp.MustCompile(`^(\d+)_.*\.sql$`)
+	namePattern    = regexp.MustCompile(`[^a-z0-9]+`)
+)
+
+const template = `-- +goose Up
+-- +goose StatementBegin
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- +goose StatementEnd
+`
+
+// Create writes an empty SQL migration numbered after the last one in dir
+// and returns its path. If dir has a SQLite subdirectory, the SQLite variant
+// with the same number is written there as well and its path follows.
+func Create(dir, name string) ([]string, error) {
+	name = strings.Trim(namePattern.ReplaceAllString(strings.ToLower(name), "_"), "_")
+	if name == "" {
+		return nil, fmt.Errorf("migration name must contain letters or digits")
+	}
+
+	dirs := []string{dir}
+	if info, err := os.Stat(filepath.Join(dir, SQLiteDir)); err == nil && info.IsDir() {
+		dirs = append(dirs, filepath.Join(dir, SQLiteDir))
+	}
+	var last int64
+	for _, d := range dirs {
+		version, err := lastVersion(d)
+		if err != nil {
+			return nil, err
+		}
+		if version > last {
+			last = version
+		}
+	}
+
+	var paths []string
+	for _, d := range dirs {
+		path := filepath.Join(d, fmt.Sprintf("%03d_%s.sql", last+1, name))
+		if err := writeTemplate(path); err != nil {
+			return paths, err
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// lastVersion returns the highest migration version in dir, 0 if it has none
+func lastVersion(dir string) (int64, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return 0, err
+	}
+	var last int64
+	for _, entry := range entries {
+		match := versionPattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			continue
+		}
+		if version, err := strconv.ParseInt(match[1], 10, 64); err == nil && version > last {
+			last = version
+		}
+	}
+	return last, nil
+}
+
+func writeTemplate(path string) error {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = file.WriteString(template)
+	return err
+}
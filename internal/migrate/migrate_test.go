@@ -0,0 +1,85 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"qa-api/migrations"
+	"testing"
+
+	_ "github.com/glebarez/go-sqlite" // SQLite driver
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreate(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"001_initial.sql", "009_later.sql", "README.md"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), nil, 0o644))
+	}
+
+	paths, err := Create(dir, "Add user profiles!")
+	require.NoError(t, err)
+	require.Len(t, paths, 1)
+	assert.Equal(t, filepath.Join(dir, "010_add_user_profiles.sql"), paths[0])
+
+	content, err := os.ReadFile(paths[0])
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "-- +goose Up")
+	assert.Contains(t, string(content), "-- +goose Down")
+
+	_, err = Create(dir, "!!!")
+	assert.Error(t, err)
+}
+
+func TestCreate_SQLiteVariant(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(dir, SQLiteDir), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "004_versions.sql"), nil, 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, SQLiteDir, "005_ahead.sql"), nil, 0o644))
+
+	paths, err := Create(dir, "tags")
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(dir, "006_tags.sql"),
+		filepath.Join(dir, SQLiteDir, "006_tags.sql"),
+	}, paths, "both variants get the same version")
+}
+
+func TestSQLite_MigrationsRoundTrip(t *testing.T) {
+	db, err := sql.Open("sqlite", "file:"+filepath.Join(t.TempDir(), "qa.db")+"?_pragma=foreign_keys(1)")
+	require.NoError(t, err)
+	defer db.Close()
+	ctx := context.Background()
+
+	migrator, err := New(db, "sqlite", migrations.ForDialect("sqlite"))
+	require.NoError(t, err)
+	assert.ErrorContains(t, migrator.Check(ctx), "pending migrations")
+	var versionTables int
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE name = 'goose_db_version'`).Scan(&versionTables))
+	assert.Zero(t, versionTables, "checking does not create the version table")
+
+	results, err := migrator.Up(ctx)
+	require.NoError(t, err)
+	require.NotEmpty(t, results)
+	require.NoError(t, migrator.Check(ctx))
+
+	for range results {
+		_, err := migrator.Down(ctx)
+		require.NoError(t, err)
+	}
+	var tables int
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name NOT IN ('goose_db_version', 'sqlite_sequence')`).Scan(&tables))
+	assert.Zero(t, tables)
+
+	again, err := migrator.Up(ctx)
+	require.NoError(t, err)
+	assert.Len(t, again, len(results))
+	assert.NoError(t, migrator.Check(ctx))
+}
+
+func TestNew_UnknownDialect(t *testing.T) {
+	_, err := New(nil, "oracle", migrations.FS)
+	assert.ErrorContains(t, err, "unsupported SQL dialect")
+}
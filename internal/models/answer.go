@@ -1,15 +1,21 @@
 package models
 
-import "time"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 // Answer represents an answer to a question
 type Answer struct {
-	ID         int       `gorm:"primaryKey;autoIncrement" json:"id"`
-	QuestionID int       `gorm:"not null;index" json:"question_id"`
-	UserID     string    `gorm:"type:varchar(255);not null;index" json:"user_id"`
-	Text       string    `gorm:"type:text;not null" json:"text"`
-	CreatedAt  time.Time `gorm:"autoCreateTime" json:"created_at"`
-	Question   Question  `gorm:"foreignKey:QuestionID" json:"question,omitempty"`
+	ID         int            `gorm:"primaryKey;autoIncrement" json:"id"`
+	QuestionID int            `gorm:"not null;index" json:"question_id"`
+	UserID     string         `gorm:"type:varchar(255);not null;index" json:"user_id"`
+	Text       string         `gorm:"type:text;not null" json:"text"`
+	CreatedAt  time.Time      `gorm:"autoCreateTime" json:"created_at"`
+	Version    int            `gorm:"not null;default:1" json:"version"`
+	DeletedAt  gorm.DeletedAt `gorm:"index" json:"-"`
+	Question   Question       `gorm:"foreignKey:QuestionID" json:"question,omitempty"`
 }
 
 // TableName specifies the table name for Answer
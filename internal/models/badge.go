@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// BadgeAward is a badge earned by a user. Tier is the 1-based tier reached
+// on badges with several tiers and 1 on the others; each tier is awarded
+// once per user.
+type BadgeAward struct {
+	UserID    string    `gorm:"primaryKey;type:varchar(255)" json:"user_id"`
+	Badge     string    `gorm:"primaryKey;type:varchar(50)" json:"badge"`
+	Tier      int       `gorm:"primaryKey;autoIncrement:false" json:"tier"`
+	AwardedAt time.Time `gorm:"not null" json:"awarded_at"`
+}
+
+// TableName specifies the table name for BadgeAward
+func (BadgeAward) TableName() string {
+	return "badge_awards"
+}
+
+// Activity sums up what a user did; badge rules are evaluated against it
+type Activity struct {
+	Questions       int
+	Answers         int
+	AcceptedAnswers int
+	VotesCast       int
+	// QuestionViews is the view count of their most viewed question
+	QuestionViews int
+	// AnswerScore is the score (upvotes minus downvotes) of their best
+	// answer, 0 when none scores above 0
+	AnswerScore int
+	Reputation  int
+}
@@ -0,0 +1,48 @@
+package models
+
+import "time"
+
+// Notification is an inbox entry of a user about an event. An event yields
+// at most one notification per user, so redelivered events are ignored.
+// ActorID is who caused it and is empty for votes, which are anonymous.
+type Notification struct {
+	ID         int64      `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID     string     `gorm:"type:varchar(255);not null;index" json:"user_id"`
+	EventID    int64      `gorm:"not null" json:"event_id"`
+	Kind       string     `gorm:"type:varchar(50);not null" json:"kind"`
+	QuestionID int        `gorm:"not null" json:"question_id"`
+	AnswerID   int        `gorm:"not null;default:0" json:"answer_id"`
+	ActorID    string     `gorm:"type:varchar(255);not null;default:''" json:"actor_id"`
+	ReadAt     *time.Time `json:"read_at"`
+	CreatedAt  time.Time  `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// TableName specifies the table name for Notification
+func (Notification) TableName() string {
+	return "notifications"
+}
+
+// QuestionFollow is a user following a question to hear about new answers
+type QuestionFollow struct {
+	QuestionID int       `gorm:"primaryKey;autoIncrement:false" json:"question_id"`
+	UserID     string    `gorm:"primaryKey;type:varchar(255)" json:"user_id"`
+	CreatedAt  time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// TableName specifies the table name for QuestionFollow
+func (QuestionFollow) TableName() string {
+	return "question_follows"
+}
+
+// NotificationPreference turns a kind of notification on or off for a
+// user; kinds without a preference are on
+type NotificationPreference struct {
+	UserID  string `gorm:"primaryKey;type:varchar(255)" json:"user_id"`
+	Kind    string `gorm:"primaryKey;type:varchar(50)" json:"kind"`
+	Enabled bool   `gorm:"not null" json:"enabled"`
+}
+
+// TableName specifies the table name for NotificationPreference
+func (NotificationPreference) TableName() string {
+	return "notification_preferences"
+}
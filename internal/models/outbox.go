@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// OutboxEvent represents a domain event waiting to be delivered to sinks
+type OutboxEvent struct {
+	ID            int64      `gorm:"primaryKey;autoIncrement" json:"id"`
+	EventType     string     `gorm:"type:varchar(100);not null" json:"event_type"`
+	AggregateType string     `gorm:"type:varchar(50);not null" json:"aggregate_type"`
+	AggregateID   int        `gorm:"not null" json:"aggregate_id"`
+	QuestionID    int        `gorm:"not null;index" json:"question_id"`
+	Payload       string     `gorm:"type:jsonb;not null" json:"payload"`
+	Attempts      int        `gorm:"not null;default:0" json:"attempts"`
+	LastError     string     `gorm:"type:text;not null;default:''" json:"last_error"`
+	NextAttemptAt time.Time  `gorm:"not null" json:"next_attempt_at"`
+	DispatchedAt  *time.Time `json:"dispatched_at"`
+	CreatedAt     time.Time  `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// TableName specifies the table name for OutboxEvent
+func (OutboxEvent) TableName() string {
+	return "outbox"
+}
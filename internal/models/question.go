@@ -1,13 +1,28 @@
 package models
 
-import "time"
-
-// Question represents a question in the system
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Question represents a question in the system. AnswerCount is
+// denormalized from the answers table so lists need not count answers; it
+// changes in the transaction of every answer write, and `qactl recount`
+// only repairs it after manual edits. UserID
+// is empty for questions asked before authors were recorded. ViewCount is
+// not part of the version, so views do not invalidate cached copies.
 type Question struct {
-	ID        int       `gorm:"primaryKey;autoIncrement" json:"id"`
-	Text      string    `gorm:"type:text;not null" json:"text"`
-	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
-	Answers   []Answer  `gorm:"foreignKey:QuestionID;constraint:OnDelete:CASCADE" json:"answers,omitempty"`
+	ID               int            `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID           string         `gorm:"type:varchar(255);not null;default:'';index" json:"user_id"`
+	Text             string         `gorm:"type:text;not null" json:"text"`
+	CreatedAt        time.Time      `gorm:"autoCreateTime" json:"created_at"`
+	Version          int            `gorm:"not null;default:1" json:"version"`
+	AnswerCount      int            `gorm:"not null;default:0" json:"answer_count"`
+	AcceptedAnswerID *int           `json:"accepted_answer_id,omitempty"`
+	ViewCount        int            `gorm:"not null;default:0" json:"view_count"`
+	DeletedAt        gorm.DeletedAt `gorm:"index" json:"-"`
+	Answers          []Answer       `gorm:"foreignKey:QuestionID;constraint:OnDelete:CASCADE" json:"answers,omitempty"`
 }
 
 // TableName specifies the table name for Question
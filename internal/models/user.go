@@ -0,0 +1,55 @@
+package models
+
+import "time"
+
+// User is the profile of someone who asks, answers or votes. Users are
+// created on their first contribution; the profile fields start empty.
+type User struct {
+	ID          string    `gorm:"primaryKey;type:varchar(255)" json:"id"`
+	DisplayName string    `gorm:"type:varchar(100);not null;default:''" json:"display_name"`
+	AvatarURL   string    `gorm:"type:text;not null;default:''" json:"avatar_url"`
+	Bio         string    `gorm:"type:text;not null;default:''" json:"bio"`
+	JoinedAt    time.Time `gorm:"not null" json:"joined_at"`
+}
+
+// TableName specifies the table name for User
+func (User) TableName() string {
+	return "users"
+}
+
+// Vote is a user's up (1) or down (-1) vote on an answer
+type Vote struct {
+	AnswerID  int       `gorm:"primaryKey;autoIncrement:false" json:"answer_id"`
+	UserID    string    `gorm:"primaryKey;type:varchar(255)" json:"user_id"`
+	Value     int       `gorm:"not null" json:"value"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// TableName specifies the table name for Vote
+func (Vote) TableName() string {
+	return "votes"
+}
+
+// ReputationEntry is a change of a user's reputation caused by an event.
+// An event yields at most one entry per user and reason, so replaying the
+// history is idempotent.
+type ReputationEntry struct {
+	ID        int64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID    string    `gorm:"type:varchar(255);not null;index" json:"user_id"`
+	EventID   int64     `gorm:"not null" json:"event_id"`
+	Reason    string    `gorm:"type:varchar(50);not null" json:"reason"`
+	Delta     int       `gorm:"not null" json:"delta"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// TableName specifies the table name for ReputationEntry
+func (ReputationEntry) TableName() string {
+	return "reputation_ledger"
+}
+
+// Standing is a user's reputation as shown on the leaderboard
+type Standing struct {
+	UserID      string `json:"user_id"`
+	DisplayName string `json:"display_name"`
+	Reputation  int    `json:"reputation"`
+}
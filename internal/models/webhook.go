@@ -0,0 +1,116 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Webhook delivery statuses
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryDead      = "dead"
+)
+
+// Webhook represents an outgoing webhook subscription
+type Webhook struct {
+	ID         int        `gorm:"primaryKey;autoIncrement" json:"id"`
+	URL        string     `gorm:"type:text;not null" json:"url"`
+	Secret     string     `gorm:"type:varchar(255);not null" json:"secret,omitempty"`
+	EventTypes StringList `gorm:"type:text;not null" json:"event_types"`
+	QuestionID *int       `gorm:"index" json:"question_id,omitempty"`
+	CreatedAt  time.Time  `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// TableName specifies the table name for Webhook
+func (Webhook) TableName() string {
+	return "webhooks"
+}
+
+// Matches reports whether the webhook is subscribed to the event
+func (w Webhook) Matches(eventType string, questionID int) bool {
+	if w.QuestionID != nil && *w.QuestionID != questionID {
+		return false
+	}
+	for _, t := range w.EventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDelivery represents a single event delivery to a webhook
+type WebhookDelivery struct {
+	ID             int64      `gorm:"primaryKey;autoIncrement" json:"id"`
+	WebhookID      int        `gorm:"not null;uniqueIndex:idx_webhook_deliveries_event,priority:1" json:"webhook_id"`
+	EventID        int64      `gorm:"not null;uniqueIndex:idx_webhook_deliveries_event,priority:2" json:"event_id"`
+	EventType      string     `gorm:"type:varchar(100);not null" json:"event_type"`
+	Payload        RawJSON    `gorm:"type:jsonb;not null" json:"payload"`
+	Status         string     `gorm:"type:varchar(20);not null;default:pending" json:"status"`
+	Attempts       int        `gorm:"not null;default:0" json:"attempts"`
+	ResponseStatus int        `gorm:"not null;default:0" json:"response_status"`
+	LastError      string     `gorm:"type:text;not null;default:''" json:"last_error"`
+	NextAttemptAt  time.Time  `gorm:"not null" json:"next_attempt_at"`
+	DeliveredAt    *time.Time `json:"delivered_at"`
+	CreatedAt      time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	Webhook        *Webhook   `gorm:"foreignKey:WebhookID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// TableName specifies the table name for WebhookDelivery
+func (WebhookDelivery) TableName() string {
+	return "webhook_deliveries"
+}
+
+// StringList is a list of strings stored as a comma-separated column
+type StringList []string
+
+// Value implements driver.Valuer
+func (l StringList) Value() (driver.Value, error) {
+	return strings.Join(l, ","), nil
+}
+
+// Scan implements sql.Scanner
+func (l *StringList) Scan(value interface{}) error {
+	var s string
+	switch v := value.(type) {
+	case string:
+		s = v
+	case []byte:
+		s = string(v)
+	case nil:
+		*l = nil
+		return nil
+	default:
+		return fmt.Errorf("cannot scan %T into StringList", value)
+	}
+	if s == "" {
+		*l = StringList{}
+		return nil
+	}
+	*l = strings.Split(s, ",")
+	return nil
+}
+
+// RawJSON is a JSON document stored in a text or jsonb column
+type RawJSON string
+
+// MarshalJSON returns the stored document as is
+func (j RawJSON) MarshalJSON() ([]byte, error) {
+	if j == "" {
+		return []byte("null"), nil
+	}
+	return []byte(j), nil
+}
+
+// UnmarshalJSON stores the raw document
+func (j *RawJSON) UnmarshalJSON(data []byte) error {
+	if !json.Valid(data) {
+		return fmt.Errorf("invalid JSON document")
+	}
+	*j = RawJSON(data)
+	return nil
+}
@@ -0,0 +1,150 @@
+// Package notify fills the inboxes of users from domain events: askers hear
+// about answers to their questions, answer authors about votes and
+// acceptance, and followers about new answers to the questions they follow.
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"qa-api/internal/events"
+	"qa-api/internal/models"
+)
+
+// Notification kinds; users turn each of them on or off
+const (
+	Answer         = "answer"
+	FollowedAnswer = "followed_answer"
+	Accepted       = "accepted"
+	Upvote         = "upvote"
+	Downvote       = "downvote"
+)
+
+// Kinds lists every notification kind
+var Kinds = []string{Answer, FollowedAnswer, Accepted, Upvote, Downvote}
+
+// Store provides the recipients of notifications and keeps them. Notify
+// must ignore notifications it already holds (same event and user), since
+// events arrive at least once.
+type Store interface {
+	// QuestionAuthor returns the author of a live question, or an empty
+	// string for anonymous and deleted questions
+	QuestionAuthor(questionID int) (string, error)
+	Followers(questionID int) ([]string, error)
+	// Muted returns those of userIDs who turned kind off
+	Muted(kind string, userIDs []string) ([]string, error)
+	Notify(notifications []models.Notification) error
+}
+
+// Notifier creates the notifications of events
+type Notifier struct {
+	store Store
+}
+
+// NewNotifier creates a new Notifier
+func NewNotifier(store Store) *Notifier {
+	return &Notifier{store: store}
+}
+
+// Handle notifies the users an event concerns; it is an events.Handler
+func (n *Notifier) Handle(ctx context.Context, event events.Event) error {
+	var notifications []models.Notification
+	var err error
+	switch event.Type {
+	case events.AnswerCreated:
+		notifications, err = n.answered(event)
+	case events.AnswerAccepted:
+		var payload events.AcceptPayload
+		if err := json.Unmarshal(event.Payload, &payload); err != nil {
+			return fmt.Errorf("invalid %s payload: %w", event.Type, err)
+		}
+		if payload.UserID != payload.AcceptedBy {
+			notifications, err = n.filter(Accepted, event, []string{payload.UserID}, payload.AnswerID, payload.AcceptedBy)
+		}
+	case events.VoteCast:
+		var payload events.VotePayload
+		if err := json.Unmarshal(event.Payload, &payload); err != nil {
+			return fmt.Errorf("invalid %s payload: %w", event.Type, err)
+		}
+		switch payload.Value {
+		case 1:
+			notifications, err = n.filter(Upvote, event, []string{payload.UserID}, payload.AnswerID, "")
+		case -1:
+			notifications, err = n.filter(Downvote, event, []string{payload.UserID}, payload.AnswerID, "")
+		}
+	}
+	if err != nil || len(notifications) == 0 {
+		return err
+	}
+	return n.store.Notify(notifications)
+}
+
+// answered notifies the asker and the followers of a question about a new
+// answer. Nobody hears about their own answer, and an asker who follows
+// their question is notified once, as the asker.
+func (n *Notifier) answered(event events.Event) ([]models.Notification, error) {
+	var payload events.AnswerPayload
+	if err := json.Unmarshal(event.Payload, &payload); err != nil {
+		return nil, fmt.Errorf("invalid %s payload: %w", event.Type, err)
+	}
+	author, err := n.store.QuestionAuthor(payload.QuestionID)
+	if err != nil {
+		return nil, err
+	}
+	followers, err := n.store.Followers(payload.QuestionID)
+	if err != nil {
+		return nil, err
+	}
+
+	var others []string
+	for _, follower := range followers {
+		if follower != author {
+			others = append(others, follower)
+		}
+	}
+	notifications, err := n.filter(Answer, event, []string{author}, payload.ID, payload.UserID)
+	if err != nil {
+		return nil, err
+	}
+	following, err := n.filter(FollowedAnswer, event, others, payload.ID, payload.UserID)
+	return append(notifications, following...), err
+}
+
+// filter builds a notification of kind for each recipient except the
+// actor, anonymous users and those who turned kind off
+func (n *Notifier) filter(kind string, event events.Event, recipients []string, answerID int, actorID string) ([]models.Notification, error) {
+	var candidates []string
+	for _, userID := range recipients {
+		if userID != "" && userID != actorID {
+			candidates = append(candidates, userID)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+	muted, err := n.store.Muted(kind, candidates)
+	if err != nil {
+		return nil, err
+	}
+	off := make(map[string]bool, len(muted))
+	for _, userID := range muted {
+		off[userID] = true
+	}
+
+	var notifications []models.Notification
+	for _, userID := range candidates {
+		if off[userID] {
+			continue
+		}
+		notifications = append(notifications, models.Notification{
+			UserID:     userID,
+			EventID:    event.ID,
+			Kind:       kind,
+			QuestionID: event.QuestionID,
+			AnswerID:   answerID,
+			ActorID:    actorID,
+			CreatedAt:  event.OccurredAt,
+		})
+	}
+	return notifications, nil
+}
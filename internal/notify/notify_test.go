@@ -0,0 +1,133 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"qa-api/internal/events"
+	"qa-api/internal/models"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeStore keeps notifications like the unique key of notifications
+type fakeStore struct {
+	authors       map[int]string
+	followers     map[int][]string
+	muted         map[string]map[string]bool
+	notifications map[string]models.Notification
+}
+
+func newFakeStore() *fakeStore {
+	return &fakeStore{
+		authors:       map[int]string{},
+		followers:     map[int][]string{},
+		muted:         map[string]map[string]bool{},
+		notifications: map[string]models.Notification{},
+	}
+}
+
+func (s *fakeStore) QuestionAuthor(questionID int) (string, error) {
+	return s.authors[questionID], nil
+}
+
+func (s *fakeStore) Followers(questionID int) ([]string, error) {
+	return s.followers[questionID], nil
+}
+
+func (s *fakeStore) Muted(kind string, userIDs []string) ([]string, error) {
+	var muted []string
+	for _, userID := range userIDs {
+		if s.muted[userID][kind] {
+			muted = append(muted, userID)
+		}
+	}
+	return muted, nil
+}
+
+func (s *fakeStore) Notify(notifications []models.Notification) error {
+	for _, notification := range notifications {
+		key := fmt.Sprintf("%d:%s", notification.EventID, notification.UserID)
+		if _, ok := s.notifications[key]; !ok {
+			s.notifications[key] = notification
+		}
+	}
+	return nil
+}
+
+// inbox lists the notifications as user:kind
+func (s *fakeStore) inbox() []string {
+	var result []string
+	for _, notification := range s.notifications {
+		result = append(result, notification.UserID+":"+notification.Kind)
+	}
+	sort.Strings(result)
+	return result
+}
+
+var eventIDs int64
+
+func event(t *testing.T, eventType string, questionID int, payload interface{}) events.Event {
+	t.Helper()
+	data, err := json.Marshal(payload)
+	require.NoError(t, err)
+	eventIDs++
+	return events.Event{ID: eventIDs, Type: eventType, QuestionID: questionID, Payload: data, OccurredAt: time.Now()}
+}
+
+func TestNotifier_Answer(t *testing.T) {
+	store := newFakeStore()
+	notifier := NewNotifier(store)
+	store.authors[1] = "alice"
+	store.followers[1] = []string{"alice", "bob", "carol", "dave"}
+	store.muted["dave"] = map[string]bool{FollowedAnswer: true}
+
+	answered := event(t, events.AnswerCreated, 1, events.AnswerPayload{ID: 7, QuestionID: 1, UserID: "bob"})
+	require.NoError(t, notifier.Handle(context.Background(), answered))
+	require.NoError(t, notifier.Handle(context.Background(), answered), "redelivered events are ignored")
+	assert.Equal(t, []string{"alice:answer", "carol:followed_answer"}, store.inbox(),
+		"the asker is notified once, the answerer and muted followers not at all")
+	notification := store.notifications[fmt.Sprintf("%d:alice", answered.ID)]
+	assert.Equal(t, models.Notification{
+		UserID: "alice", EventID: answered.ID, Kind: Answer, QuestionID: 1, AnswerID: 7, ActorID: "bob", CreatedAt: answered.OccurredAt,
+	}, notification)
+
+	store = newFakeStore()
+	notifier = NewNotifier(store)
+	store.authors[2] = "alice"
+	require.NoError(t, notifier.Handle(context.Background(), event(t, events.AnswerCreated, 2, events.AnswerPayload{ID: 8, QuestionID: 2, UserID: "alice"})))
+	require.NoError(t, notifier.Handle(context.Background(), event(t, events.AnswerCreated, 3, events.AnswerPayload{ID: 9, QuestionID: 3, UserID: "bob"})))
+	assert.Empty(t, store.inbox(), "nobody hears about their own answer or answers to anonymous questions")
+}
+
+func TestNotifier_AcceptAndVotes(t *testing.T) {
+	store := newFakeStore()
+	notifier := NewNotifier(store)
+
+	require.NoError(t, notifier.Handle(context.Background(), event(t, events.AnswerAccepted, 1, events.AcceptPayload{AnswerID: 7, QuestionID: 1, UserID: "bob", AcceptedBy: "alice"})))
+	require.NoError(t, notifier.Handle(context.Background(), event(t, events.AnswerAccepted, 1, events.AcceptPayload{AnswerID: 8, QuestionID: 1, UserID: "alice", AcceptedBy: "alice"})))
+	assert.Equal(t, []string{"bob:accepted"}, store.inbox(), "accepting your own answer notifies nobody")
+
+	store = newFakeStore()
+	notifier = NewNotifier(store)
+	upvote := event(t, events.VoteCast, 1, events.VotePayload{AnswerID: 7, QuestionID: 1, UserID: "bob", VoterID: "carol", Value: 1})
+	require.NoError(t, notifier.Handle(context.Background(), upvote))
+	require.NoError(t, notifier.Handle(context.Background(), event(t, events.VoteCast, 1, events.VotePayload{AnswerID: 8, QuestionID: 1, UserID: "dave", VoterID: "carol", Value: -1})))
+	require.NoError(t, notifier.Handle(context.Background(), event(t, events.VoteCast, 1, events.VotePayload{AnswerID: 7, QuestionID: 1, UserID: "bob", VoterID: "carol", Value: 0, Previous: 1})))
+	assert.Equal(t, []string{"bob:upvote", "dave:downvote"}, store.inbox(), "retracted votes notify nobody")
+	assert.Empty(t, store.notifications[fmt.Sprintf("%d:bob", upvote.ID)].ActorID, "voters stay anonymous")
+
+	store = newFakeStore()
+	notifier = NewNotifier(store)
+	store.muted["bob"] = map[string]bool{Upvote: true}
+	require.NoError(t, notifier.Handle(context.Background(), event(t, events.VoteCast, 1, events.VotePayload{AnswerID: 7, QuestionID: 1, UserID: "bob", VoterID: "carol", Value: 1})))
+	assert.Empty(t, store.inbox())
+
+	require.NoError(t, notifier.Handle(context.Background(), event(t, events.QuestionViewed, 1, events.ViewPayload{QuestionID: 1, UserID: "alice"})))
+	assert.Empty(t, store.inbox(), "other events notify nobody")
+	assert.Error(t, notifier.Handle(context.Background(), events.Event{Type: events.AnswerAccepted, Payload: json.RawMessage(`[]`)}))
+}
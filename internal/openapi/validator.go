@@ -0,0 +1,425 @@
+package openapi
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+const specURL = "openapi.json"
+
+// Validator checks requests and responses against the operations of the spec
+type Validator struct {
+	operations map[string]*operation
+}
+
+type operation struct {
+	params    []parameter
+	body      *jsonschema.Schema
+	bodyReq   bool
+	responses map[string]map[string]*jsonschema.Schema
+}
+
+type parameter struct {
+	name     string
+	in       string
+	required bool
+	kind     string
+	schema   *jsonschema.Schema
+}
+
+// NewValidator compiles the schemas of every operation in the given document
+func NewValidator(document []byte) (*Validator, error) {
+	var doc map[string]interface{}
+	if err := json.Unmarshal(document, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse spec: %w", err)
+	}
+
+	compiler := jsonschema.NewCompiler()
+	compiler.Draft = jsonschema.Draft2020
+	if err := compiler.AddResource(specURL, bytes.NewReader(document)); err != nil {
+		return nil, fmt.Errorf("failed to load spec: %w", err)
+	}
+
+	v := &Validator{operations: make(map[string]*operation)}
+	paths, _ := doc["paths"].(map[string]interface{})
+	for path, rawItem := range paths {
+		item, _ := rawItem.(map[string]interface{})
+		itemPtr := "/paths/" + escape(path)
+		shared := item["parameters"]
+
+		for method, rawOp := range item {
+			if method == "parameters" || method == "summary" || method == "description" {
+				continue
+			}
+			op, _ := rawOp.(map[string]interface{})
+			opPtr := itemPtr + "/" + method
+
+			compiled := &operation{responses: make(map[string]map[string]*jsonschema.Schema)}
+			params, err := compileParams(compiler, doc, itemPtr+"/parameters", shared)
+			if err != nil {
+				return nil, err
+			}
+			opParams, err := compileParams(compiler, doc, opPtr+"/parameters", op["parameters"])
+			if err != nil {
+				return nil, err
+			}
+			compiled.params = append(params, opParams...)
+
+			if body, ok := op["requestBody"].(map[string]interface{}); ok {
+				compiled.bodyReq, _ = body["required"].(bool)
+				if hasJSON(body) {
+					schema, err := compiler.Compile(specURL + "#" + opPtr + "/requestBody/content/application~1json/schema")
+					if err != nil {
+						return nil, fmt.Errorf("failed to compile request body of %s %s: %w", method, path, err)
+					}
+					compiled.body = schema
+				}
+			}
+
+			responses, _ := op["responses"].(map[string]interface{})
+			for status, rawResp := range responses {
+				respPtr := opPtr + "/responses/" + status
+				resp, _ := rawResp.(map[string]interface{})
+				if ref, ok := resp["$ref"].(string); ok {
+					respPtr = strings.TrimPrefix(ref, "#")
+					resp, _ = lookup(doc, respPtr).(map[string]interface{})
+				}
+				compiled.responses[status] = make(map[string]*jsonschema.Schema)
+				content, _ := resp["content"].(map[string]interface{})
+				for mediaType := range content {
+					compiled.responses[status][mediaType] = nil
+					if !isJSON(mediaType) {
+						continue
+					}
+					schema, err := compiler.Compile(specURL + "#" + respPtr + "/content/" + escape(mediaType) + "/schema")
+					if err != nil {
+						return nil, fmt.Errorf("failed to compile response %s of %s %s: %w", status, method, path, err)
+					}
+					compiled.responses[status][mediaType] = schema
+				}
+			}
+
+			v.operations[strings.ToUpper(method)+" "+path] = compiled
+		}
+	}
+
+	return v, nil
+}
+
+// HasOperation reports whether the spec documents the given method and path template
+func (v *Validator) HasOperation(method, path string) bool {
+	_, ok := v.operations[strings.ToUpper(method)+" "+path]
+	return ok
+}
+
+// ValidateRequest checks the parameters and JSON body of a routed request.
+// The body is restored so handlers can read it again.
+func (v *Validator) ValidateRequest(r *http.Request) error {
+	op, vars := v.lookup(r)
+	if op == nil {
+		return nil
+	}
+
+	for _, p := range op.params {
+		var value string
+		var present bool
+		switch p.in {
+		case "path":
+			value, present = vars[p.name]
+		case "query":
+			values, ok := r.URL.Query()[p.name]
+			if ok {
+				value, present = values[0], true
+			}
+		case "header":
+			value = r.Header.Get(p.name)
+			present = value != ""
+		}
+		if !present {
+			if p.required {
+				return fmt.Errorf("%s parameter %q is required", p.in, p.name)
+			}
+			continue
+		}
+		if err := p.validate(value); err != nil {
+			return fmt.Errorf("%s parameter %q: %v", p.in, p.name, err)
+		}
+	}
+
+	if op.body == nil {
+		return nil
+	}
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read request body: %w", err)
+	}
+	r.Body = io.NopCloser(bytes.NewReader(data))
+	if len(bytes.TrimSpace(data)) == 0 {
+		if op.bodyReq {
+			return errors.New("request body is required")
+		}
+		return nil
+	}
+	body, err := decodeJSON(data)
+	if err != nil {
+		return errors.New("request body is not valid JSON")
+	}
+	if err := op.body.Validate(body); err != nil {
+		return fmt.Errorf("request body: %s", describe(err))
+	}
+	return nil
+}
+
+// ValidateResponse checks a response status and JSON body against the operation
+func (v *Validator) ValidateResponse(r *http.Request, status int, contentType string, body []byte) error {
+	op, _ := v.lookup(r)
+	if op == nil {
+		return nil
+	}
+
+	content, ok := op.responses[strconv.Itoa(status)]
+	if !ok {
+		return fmt.Errorf("status %d is not documented", status)
+	}
+	if len(content) == 0 || len(body) == 0 {
+		return nil
+	}
+	if contentType == "" {
+		// net/http sniffs the type of responses written without one
+		contentType = http.DetectContentType(body)
+	}
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	schema, ok := content[mediaType]
+	if !ok {
+		return fmt.Errorf("content type %q is not documented for status %d", mediaType, status)
+	}
+	if schema == nil {
+		return nil
+	}
+	value, err := decodeJSON(body)
+	if err != nil {
+		return fmt.Errorf("response body is not valid JSON: %w", err)
+	}
+	if err := schema.Validate(value); err != nil {
+		return fmt.Errorf("response body: %s", describe(err))
+	}
+	return nil
+}
+
+// Middleware rejects requests that do not match the spec with 400 Bad Request.
+// It must be installed on the router so the matched route is known.
+func (v *Validator) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := v.ValidateRequest(r); err != nil {
+			http.Error(w, "Invalid request: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// ResponseMiddleware records every response and passes mismatches to report.
+// It is meant for tests; streaming and upgraded connections are not checked.
+func (v *Validator) ResponseMiddleware(report func(r *http.Request, err error)) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Upgrade") != "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			rec := &recorder{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(rec, r)
+			if rec.hijacked || strings.HasPrefix(rec.Header().Get("Content-Type"), "text/event-stream") {
+				return
+			}
+			if err := v.ValidateResponse(r, rec.status, rec.Header().Get("Content-Type"), rec.body.Bytes()); err != nil {
+				report(r, err)
+			}
+		})
+	}
+}
+
+func (v *Validator) lookup(r *http.Request) (*operation, map[string]string) {
+	route := mux.CurrentRoute(r)
+	if route == nil {
+		return nil, nil
+	}
+	path, err := route.GetPathTemplate()
+	if err != nil {
+		return nil, nil
+	}
+	return v.operations[r.Method+" "+path], mux.Vars(r)
+}
+
+func (p parameter) validate(value string) error {
+	var parsed interface{} = value
+	switch p.kind {
+	case "integer":
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return errors.New("must be an integer")
+		}
+		parsed = n
+	case "number":
+		n, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return errors.New("must be a number")
+		}
+		parsed = n
+	case "boolean":
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return errors.New("must be a boolean")
+		}
+		parsed = b
+	}
+	if p.schema == nil {
+		return nil
+	}
+	if err := p.schema.Validate(parsed); err != nil {
+		return errors.New(describe(err))
+	}
+	return nil
+}
+
+func compileParams(compiler *jsonschema.Compiler, doc map[string]interface{}, ptr string, raw interface{}) ([]parameter, error) {
+	list, _ := raw.([]interface{})
+	params := make([]parameter, 0, len(list))
+	for i, rawParam := range list {
+		paramPtr := fmt.Sprintf("%s/%d", ptr, i)
+		param, _ := rawParam.(map[string]interface{})
+		if ref, ok := param["$ref"].(string); ok {
+			paramPtr = strings.TrimPrefix(ref, "#")
+			param, _ = lookup(doc, paramPtr).(map[string]interface{})
+		}
+
+		p := parameter{}
+		p.name, _ = param["name"].(string)
+		p.in, _ = param["in"].(string)
+		p.required, _ = param["required"].(bool)
+		if schema, ok := param["schema"].(map[string]interface{}); ok {
+			p.kind, _ = schema["type"].(string)
+			compiled, err := compiler.Compile(specURL + "#" + paramPtr + "/schema")
+			if err != nil {
+				return nil, fmt.Errorf("failed to compile parameter %q: %w", p.name, err)
+			}
+			p.schema = compiled
+		}
+		params = append(params, p)
+	}
+	return params, nil
+}
+
+// hasJSON reports whether a request body or response object declares JSON content
+func hasJSON(object map[string]interface{}) bool {
+	content, _ := object["content"].(map[string]interface{})
+	_, ok := content["application/json"]
+	return ok
+}
+
+// isJSON reports whether a media type carries JSON, e.g. application/vnd.qa.v2+json
+func isJSON(mediaType string) bool {
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// lookup resolves a JSON pointer like /components/parameters/ID inside doc
+func lookup(doc interface{}, ptr string) interface{} {
+	current := doc
+	for _, token := range strings.Split(strings.TrimPrefix(ptr, "/"), "/") {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		object, ok := current.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		current = object[token]
+	}
+	return current
+}
+
+// decodeJSON parses a single JSON value keeping numbers exact
+func decodeJSON(data []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return nil, errors.New("unexpected data after JSON value")
+	}
+	return value, nil
+}
+
+func escape(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
+
+// describe flattens a schema validation error into a single line
+func describe(err error) string {
+	var verr *jsonschema.ValidationError
+	if !errors.As(err, &verr) {
+		return err.Error()
+	}
+	var messages []string
+	var walk func(e *jsonschema.ValidationError)
+	walk = func(e *jsonschema.ValidationError) {
+		if len(e.Causes) == 0 {
+			location := e.InstanceLocation
+			if location == "" {
+				location = "/"
+			}
+			messages = append(messages, location+": "+e.Message)
+			return
+		}
+		for _, cause := range e.Causes {
+			walk(cause)
+		}
+	}
+	walk(verr)
+	return strings.Join(messages, "; ")
+}
+
+// recorder copies the response body while writing it through
+type recorder struct {
+	http.ResponseWriter
+	status   int
+	body     bytes.Buffer
+	hijacked bool
+}
+
+func (r *recorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *recorder) Write(data []byte) (int, error) {
+	r.body.Write(data)
+	return r.ResponseWriter.Write(data)
+}
+
+func (r *recorder) Flush() {
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (r *recorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := r.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("response writer does not support hijacking")
+	}
+	r.hijacked = true
+	return hijacker.Hijack()
+}
@@ -0,0 +1,83 @@
+package openapi
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpecCompiles(t *testing.T) {
+	validator, err := NewValidator(Spec())
+	require.NoError(t, err)
+	assert.True(t, validator.HasOperation("GET", "/questions/{id}"))
+	assert.False(t, validator.HasOperation("PUT", "/questions/{id}"))
+}
+
+func TestValidatorMiddleware(t *testing.T) {
+	validator, err := NewValidator(Spec())
+	require.NoError(t, err)
+
+	var body string
+	router := mux.NewRouter()
+	router.Use(validator.Middleware)
+	router.HandleFunc("/webhooks", func(w http.ResponseWriter, r *http.Request) {
+		buf := new(strings.Builder)
+		_, _ = io.Copy(buf, r.Body)
+		body = buf.String()
+		w.WriteHeader(http.StatusCreated)
+	}).Methods("POST")
+
+	tests := []struct {
+		name   string
+		body   string
+		status int
+	}{
+		{"valid", `{"url":"https://example.com/hook","event_types":["answer.created"]}`, http.StatusCreated},
+		{"unknown event type", `{"url":"https://example.com/hook","event_types":["answer.updated"]}`, http.StatusBadRequest},
+		{"empty event types", `{"url":"https://example.com/hook","event_types":[]}`, http.StatusBadRequest},
+		{"missing body", ``, http.StatusBadRequest},
+		{"malformed JSON", `{"url":`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/webhooks", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			assert.Equal(t, tt.status, w.Code, w.Body.String())
+			if tt.status == http.StatusCreated {
+				assert.Equal(t, tt.body, body, "body must be readable by the handler")
+			}
+		})
+	}
+}
+
+func TestResponseMiddlewareReportsMismatches(t *testing.T) {
+	validator, err := NewValidator(Spec())
+	require.NoError(t, err)
+
+	var reported []error
+	router := mux.NewRouter()
+	router.Use(validator.ResponseMiddleware(func(r *http.Request, err error) {
+		reported = append(reported, err)
+	}))
+	router.HandleFunc("/answers/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"1"}`))
+	}).Methods("GET")
+	router.HandleFunc("/answers/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}).Methods("DELETE")
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/answers/1", nil))
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("DELETE", "/answers/1", nil))
+
+	require.Len(t, reported, 2)
+	assert.Contains(t, reported[0].Error(), "response body")
+	assert.Contains(t, reported[1].Error(), "status 418 is not documented")
+}
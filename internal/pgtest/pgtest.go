@@ -0,0 +1,174 @@
+// Package pgtest runs integration tests against a real PostgreSQL.
+//
+// The server is the one at TEST_DATABASE_URL or, when that is not set, a
+// throwaway cluster created with initdb and started on a free port for the
+// duration of the test binary. Every test gets its own schema, so tests see
+// none of each other's rows and can apply the migrations from scratch. When
+// neither is available, or with -short, the tests are skipped.
+package pgtest
+
+import (
+	"database/sql"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+)
+
+// EnvURL names the variable with the DSN of an existing server
+const EnvURL = "TEST_DATABASE_URL"
+
+var (
+	once     sync.Once
+	baseURL  string
+	admin    *sql.DB
+	stop     func()
+	startErr error
+	skipWhy  string
+	schemas  atomic.Int64
+)
+
+// Run runs the tests of a package and stops the throwaway server, if one
+// was started. Call it from TestMain: os.Exit(pgtest.Run(m)).
+func Run(m *testing.M) int {
+	code := m.Run()
+	if admin != nil {
+		admin.Close()
+	}
+	if stop != nil {
+		stop()
+	}
+	return code
+}
+
+// Schema creates an empty schema for the test, dropped when it finishes,
+// and returns a DSN whose search_path points to it. It skips the test when
+// no server is available.
+func Schema(t testing.TB) string {
+	t.Helper()
+	once.Do(start)
+	if skipWhy != "" {
+		t.Skip(skipWhy)
+	}
+	if startErr != nil {
+		t.Fatalf("Failed to start PostgreSQL: %v", startErr)
+	}
+
+	name := fmt.Sprintf("test_%d_%d", os.Getpid(), schemas.Add(1))
+	if _, err := admin.Exec("CREATE SCHEMA " + name); err != nil {
+		t.Fatalf("Failed to create schema %s: %v", name, err)
+	}
+	t.Cleanup(func() {
+		if _, err := admin.Exec("DROP SCHEMA " + name + " CASCADE"); err != nil {
+			t.Errorf("Failed to drop schema %s: %v", name, err)
+		}
+	})
+	return withSearchPath(baseURL, name)
+}
+
+func start() {
+	baseURL = os.Getenv(EnvURL)
+	if baseURL == "" {
+		if testing.Short() {
+			skipWhy = "integration test skipped in -short mode"
+			return
+		}
+		baseURL, stop, startErr = startCluster()
+		if startErr != nil {
+			return
+		}
+	}
+	admin, startErr = sql.Open("pgx", baseURL)
+	if startErr == nil {
+		startErr = admin.Ping()
+	}
+}
+
+// startCluster creates a cluster in a temporary directory and starts it on
+// a free local port
+func startCluster() (string, func(), error) {
+	initdb, err := findBinary("initdb")
+	if err != nil {
+		skipWhy = fmt.Sprintf("PostgreSQL is not available: set %s or put initdb and pg_ctl on PATH", EnvURL)
+		return "", nil, nil
+	}
+	if os.Geteuid() == 0 {
+		skipWhy = fmt.Sprintf("initdb refuses to run as root: set %s to run integration tests", EnvURL)
+		return "", nil, nil
+	}
+	pgCtl := filepath.Join(filepath.Dir(initdb), "pg_ctl")
+
+	dir, err := os.MkdirTemp("", "qa-api-pg-")
+	if err != nil {
+		return "", nil, err
+	}
+	data := filepath.Join(dir, "data")
+	if out, err := exec.Command(initdb, "-D", data, "-U", "postgres", "-A", "trust", "-E", "UTF8", "--no-sync").CombinedOutput(); err != nil {
+		os.RemoveAll(dir)
+		return "", nil, fmt.Errorf("initdb: %v\n%s", err, out)
+	}
+
+	port, err := freePort()
+	if err != nil {
+		os.RemoveAll(dir)
+		return "", nil, err
+	}
+	options := fmt.Sprintf("-p %d -k %s -c listen_addresses=127.0.0.1 -c fsync=off -c max_connections=200", port, dir)
+	if out, err := exec.Command(pgCtl, "-D", data, "-l", filepath.Join(dir, "server.log"), "-o", options, "-w", "start").CombinedOutput(); err != nil {
+		os.RemoveAll(dir)
+		return "", nil, fmt.Errorf("pg_ctl start: %v\n%s", err, out)
+	}
+
+	stop := func() {
+		exec.Command(pgCtl, "-D", data, "-m", "immediate", "stop").Run()
+		os.RemoveAll(dir)
+	}
+	return fmt.Sprintf("postgres://postgres@127.0.0.1:%d/postgres?sslmode=disable", port), stop, nil
+}
+
+// findBinary looks for a PostgreSQL program on PATH and in the usual
+// versioned install directories of Debian and Homebrew
+func findBinary(name string) (string, error) {
+	if path, err := exec.LookPath(name); err == nil {
+		return path, nil
+	}
+	for _, pattern := range []string{"/usr/lib/postgresql/*/bin/", "/opt/homebrew/opt/postgresql@*/bin/", "/usr/local/opt/postgresql@*/bin/"} {
+		matches, _ := filepath.Glob(pattern + name)
+		if len(matches) > 0 {
+			return matches[len(matches)-1], nil
+		}
+	}
+	return "", exec.ErrNotFound
+}
+
+func freePort() (int, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, err
+	}
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port, nil
+}
+
+// withSearchPath adds search_path to a URL or key=value DSN; both pgx and
+// lib/pq pass it to the server as a run-time parameter
+func withSearchPath(dsn, schema string) string {
+	if strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://") {
+		u, err := url.Parse(dsn)
+		if err == nil {
+			query := u.Query()
+			query.Set("search_path", schema)
+			u.RawQuery = query.Encode()
+			return u.String()
+		}
+	}
+	return dsn + " search_path=" + schema
+}
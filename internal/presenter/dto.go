@@ -0,0 +1,205 @@
+package presenter
+
+import (
+	"qa-api/internal/badges"
+	"qa-api/internal/models"
+	"time"
+)
+
+// QuestionResource describes the v1 question representation
+var QuestionResource = Resource{
+	Fields:    []string{"id", "user_id", "text", "accepted_answer_id", "created_at"},
+	Relations: []string{"answers"},
+}
+
+// AnswerResource describes the v1 answer representation
+var AnswerResource = Resource{
+	Fields:    []string{"id", "question_id", "user_id", "text", "created_at"},
+	Relations: []string{"question"},
+}
+
+// Question is the v1 representation of a question. The author and the
+// accepted answer are omitted when there is none.
+type Question struct {
+	ID               int       `json:"id"`
+	UserID           string    `json:"user_id,omitempty"`
+	Text             string    `json:"text"`
+	AcceptedAnswerID *int      `json:"accepted_answer_id,omitempty"`
+	CreatedAt        time.Time `json:"created_at"`
+	Answers          []Answer  `json:"answers,omitempty"`
+}
+
+// Answer is the v1 representation of an answer
+type Answer struct {
+	ID         int       `json:"id"`
+	QuestionID int       `json:"question_id"`
+	UserID     string    `json:"user_id"`
+	Text       string    `json:"text"`
+	CreatedAt  time.Time `json:"created_at"`
+	Question   *Question `json:"question,omitempty"`
+}
+
+// NewQuestion maps a question, embedding its answers when included
+func NewQuestion(question *models.Question, opts Options) Question {
+	dto := Question{
+		ID:               question.ID,
+		UserID:           question.UserID,
+		Text:             question.Text,
+		AcceptedAnswerID: question.AcceptedAnswerID,
+		CreatedAt:        question.CreatedAt,
+	}
+	if opts.Includes("answers") {
+		dto.Answers = make([]Answer, 0, len(question.Answers))
+		for i := range question.Answers {
+			dto.Answers = append(dto.Answers, NewAnswer(&question.Answers[i], Options{}))
+		}
+	}
+	return dto
+}
+
+// NewQuestions maps a list of questions
+func NewQuestions(questions []models.Question, opts Options) []Question {
+	dtos := make([]Question, 0, len(questions))
+	for i := range questions {
+		dtos = append(dtos, NewQuestion(&questions[i], opts))
+	}
+	return dtos
+}
+
+// NewAnswer maps an answer, embedding its question when included and loaded
+func NewAnswer(answer *models.Answer, opts Options) Answer {
+	dto := Answer{
+		ID:         answer.ID,
+		QuestionID: answer.QuestionID,
+		UserID:     answer.UserID,
+		Text:       answer.Text,
+		CreatedAt:  answer.CreatedAt,
+	}
+	if opts.Includes("question") && answer.Question.ID != 0 {
+		question := NewQuestion(&answer.Question, Options{})
+		dto.Question = &question
+	}
+	return dto
+}
+
+// User is the v1 representation of a user profile with their questions and
+// answers
+type User struct {
+	ID          string     `json:"id"`
+	DisplayName string     `json:"display_name"`
+	AvatarURL   string     `json:"avatar_url"`
+	Bio         string     `json:"bio"`
+	JoinedAt    time.Time  `json:"joined_at"`
+	Reputation  int        `json:"reputation"`
+	Questions   []Question `json:"questions"`
+	Answers     []Answer   `json:"answers"`
+}
+
+// NewUser maps a user profile
+func NewUser(user *models.User, reputation int, questions []models.Question, answers []models.Answer) User {
+	dto := User{
+		ID:          user.ID,
+		DisplayName: user.DisplayName,
+		AvatarURL:   user.AvatarURL,
+		Bio:         user.Bio,
+		JoinedAt:    user.JoinedAt,
+		Reputation:  reputation,
+		Questions:   NewQuestions(questions, Options{}),
+		Answers:     make([]Answer, 0, len(answers)),
+	}
+	for i := range answers {
+		dto.Answers = append(dto.Answers, NewAnswer(&answers[i], Options{}))
+	}
+	return dto
+}
+
+// Badge is the v1 representation of a badge that can be earned
+type Badge struct {
+	Name        string `json:"name"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Metric      string `json:"metric"`
+	Tiers       []int  `json:"tiers"`
+}
+
+// NewBadges maps badge rules
+func NewBadges(rules []badges.Rule) []Badge {
+	dtos := make([]Badge, 0, len(rules))
+	for _, rule := range rules {
+		dtos = append(dtos, Badge{
+			Name:        rule.Name,
+			Title:       rule.Title,
+			Description: rule.Description,
+			Metric:      rule.Metric,
+			Tiers:       rule.Tiers,
+		})
+	}
+	return dtos
+}
+
+// UserBadge is the v1 representation of a badge awarded to a user. Badges
+// whose rule was removed keep their name as title and have no threshold.
+type UserBadge struct {
+	Badge     string    `json:"badge"`
+	Title     string    `json:"title"`
+	Tier      int       `json:"tier"`
+	Threshold int       `json:"threshold,omitempty"`
+	AwardedAt time.Time `json:"awarded_at"`
+}
+
+// NewUserBadges maps the awards of a user, describing them by their rules
+func NewUserBadges(awards []models.BadgeAward, rules []badges.Rule) []UserBadge {
+	byName := make(map[string]badges.Rule, len(rules))
+	for _, rule := range rules {
+		byName[rule.Name] = rule
+	}
+	dtos := make([]UserBadge, 0, len(awards))
+	for _, award := range awards {
+		dto := UserBadge{Badge: award.Badge, Title: award.Badge, Tier: award.Tier, AwardedAt: award.AwardedAt}
+		if rule, ok := byName[award.Badge]; ok {
+			dto.Title = rule.Title
+			if award.Tier <= len(rule.Tiers) {
+				dto.Threshold = rule.Tiers[award.Tier-1]
+			}
+		}
+		dtos = append(dtos, dto)
+	}
+	return dtos
+}
+
+// Notification is the v1 representation of an inbox entry. The answer is
+// omitted when there is none and the actor for anonymous votes.
+type Notification struct {
+	ID         int64      `json:"id"`
+	Kind       string     `json:"kind"`
+	QuestionID int        `json:"question_id"`
+	AnswerID   int        `json:"answer_id,omitempty"`
+	ActorID    string     `json:"actor_id,omitempty"`
+	Read       bool       `json:"read"`
+	ReadAt     *time.Time `json:"read_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// Inbox is the v1 representation of a page of notifications
+type Inbox struct {
+	UnreadCount   int64          `json:"unread_count"`
+	Notifications []Notification `json:"notifications"`
+}
+
+// NewInbox maps a page of notifications and the unread count
+func NewInbox(notifications []models.Notification, unreadCount int64) Inbox {
+	dto := Inbox{UnreadCount: unreadCount, Notifications: make([]Notification, 0, len(notifications))}
+	for _, notification := range notifications {
+		dto.Notifications = append(dto.Notifications, Notification{
+			ID:         notification.ID,
+			Kind:       notification.Kind,
+			QuestionID: notification.QuestionID,
+			AnswerID:   notification.AnswerID,
+			ActorID:    notification.ActorID,
+			Read:       notification.ReadAt != nil,
+			ReadAt:     notification.ReadAt,
+			CreatedAt:  notification.CreatedAt,
+		})
+	}
+	return dto
+}
@@ -0,0 +1,92 @@
+package presenter
+
+import (
+	"qa-api/internal/models"
+	"time"
+)
+
+// QuestionV2Resource describes the v2 question representation
+var QuestionV2Resource = Resource{
+	Fields:    []string{"id", "author", "text", "answer_count", "accepted_answer_id", "created_at"},
+	Relations: []string{"answers"},
+}
+
+// AnswerV2Resource describes the v2 answer representation
+var AnswerV2Resource = Resource{
+	Fields: []string{"id", "question_id", "author", "text", "created_at"},
+}
+
+// QuestionV2 is the v2 representation of a question. The author and the
+// accepted answer are omitted when there is none.
+type QuestionV2 struct {
+	ID               int       `json:"id"`
+	Author           *AuthorV2 `json:"author,omitempty"`
+	Text             string    `json:"text"`
+	AnswerCount      int       `json:"answer_count"`
+	AcceptedAnswerID *int      `json:"accepted_answer_id,omitempty"`
+	CreatedAt        time.Time `json:"created_at"`
+	// Answers is a pointer so an included but empty list is still rendered
+	Answers *[]AnswerV2 `json:"answers,omitempty"`
+}
+
+// AnswerV2 is the v2 representation of an answer; unlike v1 it never
+// embeds the parent question and reports the user as an author object
+type AnswerV2 struct {
+	ID         int       `json:"id"`
+	QuestionID int       `json:"question_id"`
+	Author     AuthorV2  `json:"author"`
+	Text       string    `json:"text"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// AuthorV2 identifies the user who wrote a question or an answer
+type AuthorV2 struct {
+	ID string `json:"id"`
+}
+
+// ListV2 wraps v2 lists so metadata can be added later
+type ListV2 struct {
+	Data interface{} `json:"data"`
+}
+
+// NewQuestionV2 maps a question, embedding its answers when included
+func NewQuestionV2(question *models.Question, opts Options) QuestionV2 {
+	dto := QuestionV2{
+		ID:               question.ID,
+		Text:             question.Text,
+		AnswerCount:      question.AnswerCount,
+		AcceptedAnswerID: question.AcceptedAnswerID,
+		CreatedAt:        question.CreatedAt,
+	}
+	if question.UserID != "" {
+		dto.Author = &AuthorV2{ID: question.UserID}
+	}
+	if opts.Includes("answers") {
+		answers := make([]AnswerV2, 0, len(question.Answers))
+		for i := range question.Answers {
+			answers = append(answers, NewAnswerV2(&question.Answers[i]))
+		}
+		dto.Answers = &answers
+	}
+	return dto
+}
+
+// NewQuestionsV2 maps a list of questions
+func NewQuestionsV2(questions []models.Question, opts Options) []QuestionV2 {
+	dtos := make([]QuestionV2, 0, len(questions))
+	for i := range questions {
+		dtos = append(dtos, NewQuestionV2(&questions[i], opts))
+	}
+	return dtos
+}
+
+// NewAnswerV2 maps an answer
+func NewAnswerV2(answer *models.Answer) AnswerV2 {
+	return AnswerV2{
+		ID:         answer.ID,
+		QuestionID: answer.QuestionID,
+		Author:     AuthorV2{ID: answer.UserID},
+		Text:       answer.Text,
+		CreatedAt:  answer.CreatedAt,
+	}
+}
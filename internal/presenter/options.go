@@ -0,0 +1,161 @@
+// Package presenter maps models to the response DTOs of the REST API, so
+// database columns and GORM associations never leak into responses.
+package presenter
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// Resource lists the fields and relations a DTO exposes to clients
+type Resource struct {
+	Fields    []string
+	Relations []string
+}
+
+// Options selects which fields and relations a response contains
+type Options struct {
+	fields  map[string]bool
+	include map[string]bool
+	// custom is set when the query chose the fields or relations
+	custom bool
+}
+
+// ParseOptions reads ?fields= (sparse fieldset) and ?include= (embedded
+// relations) from the query. Without ?include= the defaults are embedded;
+// an empty ?include= embeds nothing.
+func ParseOptions(query url.Values, resource Resource, defaultInclude ...string) (Options, error) {
+	opts := Options{include: make(map[string]bool)}
+	_, hasFields := query["fields"]
+	_, hasInclude := query["include"]
+	opts.custom = hasFields || hasInclude
+
+	if values, ok := query["fields"]; ok {
+		allowed := append(append([]string{}, resource.Fields...), resource.Relations...)
+		fields, err := parseList(values, allowed, "field")
+		if err != nil {
+			return Options{}, err
+		}
+		if len(fields) == 0 {
+			return Options{}, fmt.Errorf("fields must not be empty")
+		}
+		opts.fields = fields
+	}
+
+	if values, ok := query["include"]; ok {
+		include, err := parseList(values, resource.Relations, "relation")
+		if err != nil {
+			return Options{}, err
+		}
+		opts.include = include
+	} else {
+		for _, relation := range defaultInclude {
+			opts.include[relation] = true
+		}
+	}
+
+	// A relation asked for in fields is embedded as well
+	for field := range opts.fields {
+		if contains(resource.Relations, field) {
+			opts.include[field] = true
+		}
+	}
+
+	return opts, nil
+}
+
+// Key returns the representation chosen by ?fields= and ?include= in a
+// canonical form, or "" when the query left the defaults. Requests with the
+// same key get the same representation of an entity.
+func (o Options) Key() string {
+	if !o.custom {
+		return ""
+	}
+	fields := "*"
+	if o.fields != nil {
+		fields = strings.Join(sortedKeys(o.fields), ",")
+	}
+	return "fields=" + fields + "&include=" + strings.Join(sortedKeys(o.include), ",")
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Includes reports whether the relation should be embedded
+func (o Options) Includes(relation string) bool {
+	return o.include[relation]
+}
+
+// Select applies the sparse fieldset to a DTO or a slice of DTOs. Embedded
+// relations are kept in full. Without ?fields= the value is returned as is.
+func (o Options) Select(v interface{}) (interface{}, error) {
+	if o.fields == nil {
+		return v, nil
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.HasPrefix(strings.TrimSpace(string(data)), "[") {
+		var items []map[string]json.RawMessage
+		if err := json.Unmarshal(data, &items); err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			o.filter(item)
+		}
+		return items, nil
+	}
+
+	var item map[string]json.RawMessage
+	if err := json.Unmarshal(data, &item); err != nil {
+		return nil, err
+	}
+	o.filter(item)
+	return item, nil
+}
+
+func (o Options) filter(item map[string]json.RawMessage) {
+	for key := range item {
+		if !o.fields[key] && !o.include[key] {
+			delete(item, key)
+		}
+	}
+}
+
+func parseList(values []string, allowed []string, kind string) (map[string]bool, error) {
+	set := make(map[string]bool)
+	for _, value := range values {
+		for _, name := range strings.Split(value, ",") {
+			name = strings.TrimSpace(name)
+			if name == "" {
+				continue
+			}
+			if !contains(allowed, name) {
+				return nil, fmt.Errorf("unknown %s %q, expected one of: %s", kind, name, strings.Join(allowed, ", "))
+			}
+			set[name] = true
+		}
+	}
+	return set, nil
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
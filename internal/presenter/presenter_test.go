@@ -0,0 +1,96 @@
+package presenter
+
+import (
+	"encoding/json"
+	"net/url"
+	"qa-api/internal/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func render(t *testing.T, v interface{}) string {
+	data, err := json.Marshal(v)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func parse(t *testing.T, query string, resource Resource, defaultInclude ...string) Options {
+	values, err := url.ParseQuery(query)
+	require.NoError(t, err)
+	opts, err := ParseOptions(values, resource, defaultInclude...)
+	require.NoError(t, err)
+	return opts
+}
+
+var createdAt = time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+func TestNewAnswer_OmitsUnloadedQuestion(t *testing.T) {
+	answer := &models.Answer{ID: 1, QuestionID: 2, UserID: "user-1", Text: "A language", CreatedAt: createdAt}
+
+	body := render(t, NewAnswer(answer, parse(t, "include=question", AnswerResource)))
+
+	assert.JSONEq(t, `{"id":1,"question_id":2,"user_id":"user-1","text":"A language","created_at":"2024-01-01T12:00:00Z"}`, body)
+}
+
+func TestNewAnswer_IncludesQuestion(t *testing.T) {
+	answer := &models.Answer{ID: 1, QuestionID: 2, UserID: "user-1", Text: "A language", CreatedAt: createdAt,
+		Question: models.Question{ID: 2, Text: "What is Go?", CreatedAt: createdAt}}
+
+	assert.NotContains(t, render(t, NewAnswer(answer, parse(t, "", AnswerResource))), "question\":{")
+	assert.Contains(t, render(t, NewAnswer(answer, parse(t, "include=question", AnswerResource))), `"question":{"id":2`)
+}
+
+func TestNewQuestion_DefaultInclude(t *testing.T) {
+	question := &models.Question{ID: 2, Text: "What is Go?", CreatedAt: createdAt,
+		Answers: []models.Answer{{ID: 1, QuestionID: 2, UserID: "user-1", Text: "A language", CreatedAt: createdAt}}}
+
+	assert.Contains(t, render(t, NewQuestion(question, parse(t, "", QuestionResource, "answers"))), `"answers":[{"id":1`)
+	assert.NotContains(t, render(t, NewQuestion(question, parse(t, "include=", QuestionResource, "answers"))), "answers")
+}
+
+func TestSelect(t *testing.T) {
+	question := &models.Question{ID: 2, Text: "What is Go?", CreatedAt: createdAt,
+		Answers: []models.Answer{{ID: 1, QuestionID: 2, UserID: "user-1", Text: "A language", CreatedAt: createdAt}}}
+
+	t.Run("single", func(t *testing.T) {
+		opts := parse(t, "fields=id,text&include=", QuestionResource)
+		body, err := opts.Select(NewQuestion(question, opts))
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"id":2,"text":"What is Go?"}`, render(t, body))
+	})
+
+	t.Run("list keeps included relations", func(t *testing.T) {
+		opts := parse(t, "fields=id&include=answers", QuestionResource)
+		body, err := opts.Select(NewQuestions([]models.Question{*question}, opts))
+		require.NoError(t, err)
+		assert.JSONEq(t, `[{"id":2,"answers":[{"id":1,"question_id":2,"user_id":"user-1","text":"A language","created_at":"2024-01-01T12:00:00Z"}]}]`, render(t, body))
+	})
+
+	t.Run("v2 includes empty answers", func(t *testing.T) {
+		opts := parse(t, "fields=answers", QuestionV2Resource)
+		body, err := opts.Select(NewQuestionV2(&models.Question{ID: 3}, opts))
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"answers":[]}`, render(t, body))
+	})
+}
+
+func TestParseOptions_RejectsUnknownNames(t *testing.T) {
+	_, err := ParseOptions(url.Values{"fields": {"id,secret"}}, AnswerResource)
+	assert.EqualError(t, err, `unknown field "secret", expected one of: id, question_id, user_id, text, created_at, question`)
+
+	_, err = ParseOptions(url.Values{"include": {"answers"}}, AnswerResource)
+	assert.Error(t, err)
+
+	_, err = ParseOptions(url.Values{"fields": {""}}, AnswerResource)
+	assert.Error(t, err)
+}
+
+func TestOptions_Key(t *testing.T) {
+	assert.Empty(t, parse(t, "", QuestionResource, "answers").Key())
+	assert.Equal(t, "fields=id,text&include=answers", parse(t, "fields=text,id", QuestionResource, "answers").Key())
+	assert.Equal(t, parse(t, "fields=text,id", QuestionResource).Key(), parse(t, "fields=id&fields=text", QuestionResource).Key())
+	assert.Equal(t, "fields=*&include=", parse(t, "include=", QuestionResource, "answers").Key())
+}
@@ -2,7 +2,12 @@ package repository
 
 import (
 	"qa-api/internal/database"
+	"qa-api/internal/events"
 	"qa-api/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // AnswerRepository handles database operations for answers
@@ -13,24 +18,116 @@ func NewAnswerRepository() *AnswerRepository {
 	return &AnswerRepository{}
 }
 
-// Create creates a new answer
+// Create creates a new answer, and its author unless they exist, bumps the
+// version of its question and records an AnswerCreated event
 func (r *AnswerRepository) Create(answer *models.Answer) error {
-	return database.GetDB().Create(answer).Error
+	return database.GetDB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(answer).Error; err != nil {
+			return err
+		}
+		if err := ensureUsers(tx, map[string]time.Time{answer.UserID: answer.CreatedAt}); err != nil {
+			return err
+		}
+		if err := bumpQuestionVersion(tx, answer.QuestionID, 1); err != nil {
+			return err
+		}
+
+		event, err := events.NewAnswerEvent(events.AnswerCreated, answer)
+		if err != nil {
+			return err
+		}
+		return appendEvent(tx, event)
+	})
 }
 
-// GetByID retrieves an answer by ID
+// GetByID retrieves an answer by ID together with its question. Its question
+// is not known up front, so the read sticks to the primary after any write.
 func (r *AnswerRepository) GetByID(id int) (*models.Answer, error) {
 	var answer models.Answer
-	err := database.GetDB().First(&answer, id).Error
+	err := database.Reader().Preload("Question").First(&answer, id).Error
 	return &answer, err
 }
 
-// Delete deletes an answer by ID
-func (r *AnswerRepository) Delete(id int) error {
-	return database.GetDB().Delete(&models.Answer{}, id).Error
+// GetByQuestionIDs retrieves up to limit answers of each of the given
+// questions with an ID greater than afterID, ordered by ID
+func (r *AnswerRepository) GetByQuestionIDs(questionIDs []int, afterID, limit int) ([]models.Answer, error) {
+	return answerPages(database.Reader(questionIDs...), "question_id", questionIDs, afterID, limit)
+}
+
+// GetByUserIDs retrieves up to limit answers of each of the given users with
+// an ID greater than afterID, ordered by ID
+func (r *AnswerRepository) GetByUserIDs(userIDs []string, afterID, limit int) ([]models.Answer, error) {
+	return answerPages(database.Reader(), "user_id", userIDs, afterID, limit)
+}
+
+// answerPages reads a page of answers per value of column in one query
+func answerPages(db *gorm.DB, column string, values interface{}, afterID, limit int) ([]models.Answer, error) {
+	ranked := db.Model(&models.Answer{}).
+		Select("*, ROW_NUMBER() OVER (PARTITION BY "+column+" ORDER BY id) AS page_row").
+		Where(column+" IN ? AND id > ?", values, afterID)
+	var answers []models.Answer
+	err := db.Table("(?) AS ranked", ranked).Where("page_row <= ?", limit).Order("id").Find(&answers).Error
+	return answers, err
 }
 
+// Delete soft-deletes an answer by ID and records an AnswerDeleted event.
+// A non-zero version must match the stored one.
+func (r *AnswerRepository) Delete(id, version int) error {
+	return database.GetDB().Transaction(func(tx *gorm.DB) error {
+		var answer models.Answer
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&answer, id).Error; err != nil {
+			return err
+		}
+		if version != 0 && answer.Version != version {
+			return ErrVersionMismatch
+		}
+		if err := tx.Delete(&models.Answer{}, id).Error; err != nil {
+			return err
+		}
+		if err := bumpQuestionVersion(tx, answer.QuestionID, -1); err != nil {
+			return err
+		}
 
+		event, err := events.NewAnswerEvent(events.AnswerDeleted, &answer)
+		if err != nil {
+			return err
+		}
+		return appendEvent(tx, event)
+	})
+}
 
+// Restore undoes the soft delete of an answer and records an AnswerCreated
+// event, so subscribers see it reappear. The question must not be deleted.
+func (r *AnswerRepository) Restore(id int) (*models.Answer, error) {
+	var answer models.Answer
+	err := database.GetDB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Clauses(clause.Locking{Strength: "UPDATE"}).First(&answer, id).Error; err != nil {
+			return err
+		}
+		if !answer.DeletedAt.Valid {
+			return ErrNotDeleted
+		}
+		var count int64
+		if err := tx.Model(&models.Question{}).Where("id = ?", answer.QuestionID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			return gorm.ErrRecordNotFound
+		}
 
+		if err := tx.Unscoped().Model(&answer).Update("deleted_at", nil).Error; err != nil {
+			return err
+		}
+		answer.DeletedAt = gorm.DeletedAt{}
+		if err := bumpQuestionVersion(tx, answer.QuestionID, 1); err != nil {
+			return err
+		}
 
+		event, err := events.NewAnswerEvent(events.AnswerCreated, &answer)
+		if err != nil {
+			return err
+		}
+		return appendEvent(tx, event)
+	})
+	return &answer, err
+}
@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"qa-api/internal/database"
+	"qa-api/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// BadgeRepository handles database operations for badges and the activity
+// they are awarded for
+type BadgeRepository struct{}
+
+// NewBadgeRepository creates a new BadgeRepository
+func NewBadgeRepository() *BadgeRepository {
+	return &BadgeRepository{}
+}
+
+// Activity sums up the live questions, answers and votes of a user. It runs
+// right after the events it follows, so it always asks the primary.
+func (r *BadgeRepository) Activity(userID string) (models.Activity, error) {
+	var activity models.Activity
+	db := database.GetDB()
+	queries := []struct {
+		dest  *int
+		query *gorm.DB
+	}{
+		{&activity.Questions, db.Model(&models.Question{}).Select("COUNT(*)").Where("user_id = ?", userID)},
+		{&activity.Answers, db.Model(&models.Answer{}).Select("COUNT(*)").Where("user_id = ?", userID)},
+		{&activity.AcceptedAnswers, db.Model(&models.Answer{}).Select("COUNT(*)").
+			Joins("JOIN questions ON questions.accepted_answer_id = answers.id AND questions.deleted_at IS NULL").
+			Where("answers.user_id = ?", userID)},
+		{&activity.VotesCast, db.Model(&models.Vote{}).Select("COUNT(*)").Where("user_id = ?", userID)},
+		{&activity.QuestionViews, db.Model(&models.Question{}).Select("COALESCE(MAX(view_count), 0)").Where("user_id = ?", userID)},
+		{&activity.AnswerScore, db.Table("(?) AS scores", db.Model(&models.Vote{}).
+			Select("SUM(votes.value) AS score").
+			Joins("JOIN answers ON answers.id = votes.answer_id AND answers.deleted_at IS NULL").
+			Where("answers.user_id = ?", userID).
+			Group("votes.answer_id").
+			Having("SUM(votes.value) > 0")).Select("COALESCE(MAX(score), 0)")},
+		{&activity.Reputation, db.Model(&models.ReputationEntry{}).Select("COALESCE(SUM(delta), 0)").Where("user_id = ?", userID)},
+	}
+	for _, q := range queries {
+		if err := q.query.Scan(q.dest).Error; err != nil {
+			return activity, err
+		}
+	}
+	return activity, nil
+}
+
+// Awards retrieves the badges of a user in the order they were awarded. It
+// guards new awards, so it always asks the primary.
+func (r *BadgeRepository) Awards(userID string) ([]models.BadgeAward, error) {
+	awards := []models.BadgeAward{}
+	err := database.GetDB().Where("user_id = ?", userID).Order("awarded_at, badge, tier").Find(&awards).Error
+	return awards, err
+}
+
+// Award stores badges, skipping those the user already holds
+func (r *BadgeRepository) Award(awards []models.BadgeAward) error {
+	if len(awards) == 0 {
+		return nil
+	}
+	return database.GetDB().Clauses(clause.OnConflict{DoNothing: true}).Create(&awards).Error
+}
+
+// UserIDs retrieves up to limit user IDs greater than afterID, ordered
+func (r *BadgeRepository) UserIDs(afterID string, limit int) ([]string, error) {
+	var ids []string
+	err := database.GetDB().Model(&models.User{}).Where("id > ?", afterID).Order("id").Limit(limit).Pluck("id", &ids).Error
+	return ids, err
+}
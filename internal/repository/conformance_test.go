@@ -0,0 +1,94 @@
+package repository_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"qa-api/internal/config"
+	"qa-api/internal/database"
+	"qa-api/internal/events"
+	"qa-api/internal/migrate"
+	"qa-api/internal/models"
+	"qa-api/internal/pgtest"
+	"qa-api/internal/storage"
+	"qa-api/internal/storage/storagetest"
+	"qa-api/migrations"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMain(m *testing.M) {
+	os.Exit(pgtest.Run(m))
+}
+
+// openBackend connects the repositories to the database at databaseURL and
+// migrates it
+func openBackend(t *testing.T, databaseURL string) storage.Backend {
+	t.Helper()
+	cfg, err := config.Load(nil, nil)
+	require.NoError(t, err)
+	cfg.DB.URL = databaseURL
+	cfg.DB.LogLevel = "silent"
+	cfg.DB.Retry.Attempts = 1
+
+	require.NoError(t, database.Init(cfg))
+	sqlDB, err := database.GetDB().DB()
+	require.NoError(t, err)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	dialect := cfg.DB.Dialect()
+	migrator, err := migrate.New(sqlDB, dialect, migrations.ForDialect(dialect))
+	require.NoError(t, err)
+	_, err = migrator.Up(context.Background())
+	require.NoError(t, err)
+	return storage.NewSQL(cfg.DB.URL)
+}
+
+func TestSQLite_Conformance(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) storage.Backend {
+		return openBackend(t, "sqlite:"+filepath.Join(t.TempDir(), "qa.db"))
+	})
+}
+
+func TestSQLite_RecountAnswers(t *testing.T) {
+	b := openBackend(t, "sqlite:"+filepath.Join(t.TempDir(), "qa.db"))
+	question := &models.Question{Text: "question"}
+	require.NoError(t, b.Questions.Create(question))
+	require.NoError(t, database.GetDB().Model(question).UpdateColumn("answer_count", 3).Error)
+
+	updated, err := b.Questions.RecountAnswers()
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), updated)
+
+	stored, err := b.Questions.GetByID(question.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 0, stored.AnswerCount)
+	assert.Equal(t, 2, stored.Version)
+
+	history, err := b.Outbox.EventsSince(question.ID, 0, 10)
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	assert.Equal(t, events.QuestionUpdated, history[1].Type, "caches learn about the new count")
+}
+
+func TestIntegration_Conformance(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) storage.Backend {
+		return openBackend(t, pgtest.Schema(t))
+	})
+}
+
+func TestIntegration_CreateInBatchesNotifiesLargeImports(t *testing.T) {
+	b := openBackend(t, pgtest.Schema(t))
+	// More events than a statement can bind parameters for
+	questions := make([]models.Question, 70000)
+	for i := range questions {
+		questions[i].Text = "question"
+	}
+	require.NoError(t, b.Questions.CreateInBatches(questions, 1000))
+
+	var stored int64
+	require.NoError(t, database.GetDB().Model(&models.OutboxEvent{}).Count(&stored).Error)
+	assert.Equal(t, int64(len(questions)), stored)
+}
@@ -0,0 +1,153 @@
+package repository
+
+import (
+	"qa-api/internal/database"
+	"qa-api/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// NotificationRepository handles database operations for notifications,
+// their preferences and question follows, which only serve notifications
+type NotificationRepository struct{}
+
+// NewNotificationRepository creates a new NotificationRepository
+func NewNotificationRepository() *NotificationRepository {
+	return &NotificationRepository{}
+}
+
+// QuestionAuthor returns the author of a live question, or an empty string
+// for anonymous and deleted questions. It runs right after the events it
+// follows, so it always asks the primary.
+func (r *NotificationRepository) QuestionAuthor(questionID int) (string, error) {
+	var authors []string
+	err := database.GetDB().Model(&models.Question{}).Where("id = ?", questionID).Pluck("user_id", &authors).Error
+	if err != nil || len(authors) == 0 {
+		return "", err
+	}
+	return authors[0], nil
+}
+
+// Followers returns the users following a question, ordered by user ID
+func (r *NotificationRepository) Followers(questionID int) ([]string, error) {
+	var userIDs []string
+	err := database.GetDB().Model(&models.QuestionFollow{}).Where("question_id = ?", questionID).
+		Order("user_id").Pluck("user_id", &userIDs).Error
+	return userIDs, err
+}
+
+// Muted returns those of userIDs who turned kind off
+func (r *NotificationRepository) Muted(kind string, userIDs []string) ([]string, error) {
+	var muted []string
+	err := database.GetDB().Model(&models.NotificationPreference{}).
+		Where("kind = ? AND user_id IN ? AND NOT enabled", kind, userIDs).Pluck("user_id", &muted).Error
+	return muted, err
+}
+
+// Notify stores notifications, skipping those already stored for the same
+// event and user
+func (r *NotificationRepository) Notify(notifications []models.Notification) error {
+	if len(notifications) == 0 {
+		return nil
+	}
+	return database.GetDB().Clauses(clause.OnConflict{DoNothing: true}).Create(&notifications).Error
+}
+
+// List retrieves up to limit notifications of a user with an ID below
+// beforeID, newest first; beforeID 0 starts with the newest. Inbox reads go
+// to the replicas, so a notification just marked read may show as unread
+// for up to the replica lag.
+func (r *NotificationRepository) List(userID string, beforeID int64, limit int) ([]models.Notification, error) {
+	notifications := []models.Notification{}
+	query := database.Reader().Where("user_id = ?", userID)
+	if beforeID > 0 {
+		query = query.Where("id < ?", beforeID)
+	}
+	err := query.Order("id DESC").Limit(limit).Find(&notifications).Error
+	return notifications, err
+}
+
+// UnreadCount returns the number of unread notifications of a user
+func (r *NotificationRepository) UnreadCount(userID string) (int64, error) {
+	var count int64
+	err := database.Reader().Model(&models.Notification{}).Where("user_id = ? AND read_at IS NULL", userID).Count(&count).Error
+	return count, err
+}
+
+// MarkRead marks a notification of a user as read; marking it again keeps
+// the first read time. Notifications of other users are not found.
+func (r *NotificationRepository) MarkRead(userID string, id int64) error {
+	return database.GetDB().Transaction(func(tx *gorm.DB) error {
+		var notification models.Notification
+		if err := tx.Where("id = ? AND user_id = ?", id, userID).First(&notification).Error; err != nil {
+			return err
+		}
+		if notification.ReadAt != nil {
+			return nil
+		}
+		return tx.Model(&notification).Update("read_at", time.Now()).Error
+	})
+}
+
+// MarkAllRead marks every unread notification of a user as read and
+// returns how many there were
+func (r *NotificationRepository) MarkAllRead(userID string) (int64, error) {
+	result := database.GetDB().Model(&models.Notification{}).Where("user_id = ? AND read_at IS NULL", userID).Update("read_at", time.Now())
+	return result.RowsAffected, result.Error
+}
+
+// GetPreferences returns the kinds a user turned on or off
+func (r *NotificationRepository) GetPreferences(userID string) (map[string]bool, error) {
+	var preferences []models.NotificationPreference
+	if err := database.Reader().Where("user_id = ?", userID).Find(&preferences).Error; err != nil {
+		return nil, err
+	}
+	result := make(map[string]bool, len(preferences))
+	for _, preference := range preferences {
+		result[preference.Kind] = preference.Enabled
+	}
+	return result, nil
+}
+
+// SavePreferences turns the given kinds on or off for a user and leaves
+// the others as they are
+func (r *NotificationRepository) SavePreferences(userID string, preferences map[string]bool) error {
+	if len(preferences) == 0 {
+		return nil
+	}
+	rows := make([]models.NotificationPreference, 0, len(preferences))
+	for kind, enabled := range preferences {
+		rows = append(rows, models.NotificationPreference{UserID: userID, Kind: kind, Enabled: enabled})
+	}
+	return database.GetDB().Transaction(func(tx *gorm.DB) error {
+		if err := ensureUsers(tx, map[string]time.Time{userID: time.Now()}); err != nil {
+			return err
+		}
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}, {Name: "kind"}},
+			DoUpdates: clause.AssignmentColumns([]string{"enabled"}),
+		}).Create(&rows).Error
+	})
+}
+
+// Follow makes a user follow a live question; following it again changes
+// nothing
+func (r *NotificationRepository) Follow(questionID int, userID string) error {
+	return database.GetDB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Select("id").First(&models.Question{}, questionID).Error; err != nil {
+			return err
+		}
+		if err := ensureUsers(tx, map[string]time.Time{userID: time.Now()}); err != nil {
+			return err
+		}
+		return tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&models.QuestionFollow{QuestionID: questionID, UserID: userID}).Error
+	})
+}
+
+// Unfollow stops a user following a question, if they did
+func (r *NotificationRepository) Unfollow(questionID int, userID string) error {
+	return database.GetDB().Where("question_id = ? AND user_id = ?", questionID, userID).Delete(&models.QuestionFollow{}).Error
+}
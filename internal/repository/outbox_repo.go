@@ -0,0 +1,179 @@
+package repository
+
+import (
+	"qa-api/internal/config"
+	"qa-api/internal/database"
+	"qa-api/internal/events"
+	"qa-api/internal/models"
+	"sort"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// OutboxRepository handles database operations for outbox events
+type OutboxRepository struct{}
+
+// NewOutboxRepository creates a new OutboxRepository
+func NewOutboxRepository() *OutboxRepository {
+	return &OutboxRepository{}
+}
+
+// ClaimDue locks due events with SKIP LOCKED and pushes their next attempt
+// forward by lease, so concurrent dispatchers never pick the same batch
+func (r *OutboxRepository) ClaimDue(limit int, lease time.Duration) ([]events.Event, error) {
+	var records []models.OutboxEvent
+	now := time.Now()
+	err := database.GetDB().Raw(`
+		UPDATE outbox SET next_attempt_at = ?
+		WHERE id IN (
+			SELECT id FROM outbox
+			WHERE dispatched_at IS NULL AND next_attempt_at <= ?
+			ORDER BY id
+			LIMIT ?
+			`+skipLocked()+`
+		)
+		RETURNING *`, now.Add(lease), now, limit).Scan(&records).Error
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(records, func(i, j int) bool { return records[i].ID < records[j].ID })
+
+	result := make([]events.Event, 0, len(records))
+	for _, record := range records {
+		result = append(result, events.FromOutbox(record))
+	}
+	return result, nil
+}
+
+// MarkDispatched marks an event as delivered
+func (r *OutboxRepository) MarkDispatched(id int64) error {
+	return database.GetDB().Model(&models.OutboxEvent{}).Where("id = ?", id).
+		Update("dispatched_at", time.Now()).Error
+}
+
+// MarkFailed records a failed delivery attempt and schedules a retry
+func (r *OutboxRepository) MarkFailed(id int64, attempts int, nextAttemptAt time.Time, lastError string) error {
+	return database.GetDB().Model(&models.OutboxEvent{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"attempts":        attempts,
+		"next_attempt_at": nextAttemptAt,
+		"last_error":      lastError,
+	}).Error
+}
+
+// GetByID retrieves an event by ID
+func (r *OutboxRepository) GetByID(id int64) (events.Event, error) {
+	var record models.OutboxEvent
+	if err := database.GetDB().First(&record, id).Error; err != nil {
+		return events.Event{}, err
+	}
+	return events.FromOutbox(record), nil
+}
+
+// EventsSince retrieves up to limit events of a question with an ID greater than afterID
+func (r *OutboxRepository) EventsSince(questionID int, afterID int64, limit int) ([]events.Event, error) {
+	var records []models.OutboxEvent
+	err := database.GetDB().Where("question_id = ? AND id > ?", questionID, afterID).
+		Order("id").Limit(limit).Find(&records).Error
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]events.Event, 0, len(records))
+	for _, record := range records {
+		result = append(result, events.FromOutbox(record))
+	}
+	return result, nil
+}
+
+// appendEvent writes an outbox record within the caller's transaction and
+// notifies listeners on every replica once the transaction commits. Reads of
+// the question stay on the primary database for the sticky window.
+func appendEvent(tx *gorm.DB, record *models.OutboxEvent) error {
+	if err := insertEvent(tx, record); err != nil {
+		return err
+	}
+	database.MarkWritten(record.QuestionID)
+	return nil
+}
+
+// insertEvent is appendEvent for events that change nothing readers of the
+// question see, which leave its reads on the replicas. SQLite has no NOTIFY;
+// its events are picked up by tailing the outbox.
+func insertEvent(tx *gorm.DB, record *models.OutboxEvent) error {
+	if err := tx.Create(record).Error; err != nil {
+		return err
+	}
+	if database.Dialect() != config.DialectPostgres {
+		return nil
+	}
+	return tx.Exec("SELECT pg_notify(?, ?)", events.NotifyChannel, strconv.FormatInt(record.ID, 10)).Error
+}
+
+// notifyBatchSize is how many events one NOTIFY query announces, far below
+// the limit of 65535 bind parameters per statement
+const notifyBatchSize = 1000
+
+// appendEvents is appendEvent for bulk writes: the records are inserted
+// batchSize rows per statement and announced by one NOTIFY query per
+// notifyBatchSize events
+func appendEvents(tx *gorm.DB, records []*models.OutboxEvent, batchSize int) error {
+	if len(records) == 0 {
+		return nil
+	}
+	if err := tx.CreateInBatches(records, batchSize).Error; err != nil {
+		return err
+	}
+
+	ids := make([]int64, len(records))
+	questionIDs := make([]int, len(records))
+	for i, record := range records {
+		ids[i] = record.ID
+		questionIDs[i] = record.QuestionID
+	}
+	database.MarkWritten(questionIDs...)
+	if database.Dialect() != config.DialectPostgres {
+		return nil
+	}
+	for start := 0; start < len(ids); start += notifyBatchSize {
+		batch := ids[start:min(start+notifyBatchSize, len(ids))]
+		err := tx.Exec("SELECT pg_notify(?, id::text) FROM outbox WHERE id IN ? ORDER BY id", events.NotifyChannel, batch).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LastEventID returns the ID of the newest event, 0 if there is none
+func (r *OutboxRepository) LastEventID() (int64, error) {
+	var id int64
+	err := database.GetDB().Model(&models.OutboxEvent{}).Select("COALESCE(MAX(id), 0)").Scan(&id).Error
+	return id, err
+}
+
+// EventsAfter retrieves up to limit events with an ID greater than afterID, ordered by ID
+func (r *OutboxRepository) EventsAfter(afterID int64, limit int) ([]events.Event, error) {
+	var records []models.OutboxEvent
+	err := database.GetDB().Where("id > ?", afterID).Order("id").Limit(limit).Find(&records).Error
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]events.Event, 0, len(records))
+	for _, record := range records {
+		result = append(result, events.FromOutbox(record))
+	}
+	return result, nil
+}
+
+// skipLocked is the row lock of the claim queries. SQLite has no row locks
+// and needs none: its transactions take the database write lock.
+func skipLocked() string {
+	if database.Dialect() == config.DialectSQLite {
+		return ""
+	}
+	return "FOR UPDATE SKIP LOCKED"
+}
@@ -2,7 +2,13 @@ package repository
 
 import (
 	"qa-api/internal/database"
+	"qa-api/internal/events"
 	"qa-api/internal/models"
+	"slices"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // QuestionRepository handles database operations for questions
@@ -13,38 +19,324 @@ func NewQuestionRepository() *QuestionRepository {
 	return &QuestionRepository{}
 }
 
-// Create creates a new question
+// Create creates a new question, and its author unless they exist, and
+// records a QuestionCreated event
 func (r *QuestionRepository) Create(question *models.Question) error {
-	return database.GetDB().Create(question).Error
+	return database.GetDB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(question).Error; err != nil {
+			return err
+		}
+		if err := ensureUsers(tx, map[string]time.Time{question.UserID: question.CreatedAt}); err != nil {
+			return err
+		}
+
+		event, err := events.NewQuestionEvent(events.QuestionCreated, question)
+		if err != nil {
+			return err
+		}
+		return appendEvent(tx, event)
+	})
 }
 
 // GetAll retrieves all questions
 func (r *QuestionRepository) GetAll() ([]models.Question, error) {
 	var questions []models.Question
-	err := database.GetDB().Find(&questions).Error
+	err := database.Reader().Find(&questions).Error
+	return questions, err
+}
+
+// GetAllWithAnswers retrieves all questions with their answers preloaded
+func (r *QuestionRepository) GetAllWithAnswers() ([]models.Question, error) {
+	var questions []models.Question
+	err := database.Reader().Preload("Answers", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id")
+	}).Find(&questions).Error
 	return questions, err
 }
 
 // GetByID retrieves a question by ID with its answers
 func (r *QuestionRepository) GetByID(id int) (*models.Question, error) {
 	var question models.Question
-	err := database.GetDB().Preload("Answers").First(&question, id).Error
+	err := database.Reader(id).Preload("Answers").First(&question, id).Error
 	return &question, err
 }
 
-// Delete deletes a question by ID (cascade delete will handle answers)
-func (r *QuestionRepository) Delete(id int) error {
-	return database.GetDB().Delete(&models.Question{}, id).Error
+// GetPage retrieves up to limit questions with an ID greater than afterID, ordered by ID
+func (r *QuestionRepository) GetPage(afterID, limit int) ([]models.Question, error) {
+	var questions []models.Question
+	err := database.Reader().Where("id > ?", afterID).Order("id").Limit(limit).Find(&questions).Error
+	return questions, err
 }
 
-// Exists checks if a question exists
-func (r *QuestionRepository) Exists(id int) (bool, error) {
-	var count int64
-	err := database.GetDB().Model(&models.Question{}).Where("id = ?", id).Count(&count).Error
-	return count > 0, err
+// GetByIDs retrieves the questions with the given IDs, without their answers
+func (r *QuestionRepository) GetByIDs(ids []int) ([]models.Question, error) {
+	var questions []models.Question
+	err := database.Reader(ids...).Where("id IN ?", ids).Find(&questions).Error
+	return questions, err
 }
 
+// GetPageWithAnswers is GetPage with the answers of each question preloaded
+func (r *QuestionRepository) GetPageWithAnswers(afterID, limit int) ([]models.Question, error) {
+	var questions []models.Question
+	err := database.Reader().Preload("Answers", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id")
+	}).Where("id > ?", afterID).Order("id").Limit(limit).Find(&questions).Error
+	return questions, err
+}
 
+// CreateInBatches inserts questions together with their answers and
+// authors in a single transaction, batchSize rows per statement, and records
+// a QuestionCreated event for every question and an AnswerCreated event for
+// every answer
+func (r *QuestionRepository) CreateInBatches(questions []models.Question, batchSize int) error {
+	for i := range questions {
+		questions[i].AnswerCount = len(questions[i].Answers)
+	}
+	defer database.MarkWritten()
+	return database.GetDB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.CreateInBatches(&questions, batchSize).Error; err != nil {
+			return err
+		}
+		if err := ensureUsers(tx, authors(questions)); err != nil {
+			return err
+		}
 
+		records, err := events.NewCreatedEvents(questions)
+		if err != nil {
+			return err
+		}
+		return appendEvents(tx, records, batchSize)
+	})
+}
+
+// Delete soft-deletes a question by ID together with its answers and
+// records a QuestionDeleted event. A non-zero version must match the stored
+// one, otherwise ErrVersionMismatch is returned.
+func (r *QuestionRepository) Delete(id, version int) error {
+	return database.GetDB().Transaction(func(tx *gorm.DB) error {
+		var question models.Question
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&question, id).Error; err != nil {
+			return err
+		}
+		if version != 0 && question.Version != version {
+			return ErrVersionMismatch
+		}
+		// The answers share the timestamp so Restore can tell them apart
+		// from answers deleted on their own earlier
+		deletedAt := tx.NowFunc()
+		if err := tx.Model(&models.Answer{}).Where("question_id = ?", id).Update("deleted_at", deletedAt).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&question).Update("deleted_at", deletedAt).Error; err != nil {
+			return err
+		}
+
+		event, err := events.NewQuestionEvent(events.QuestionDeleted, &question)
+		if err != nil {
+			return err
+		}
+		return appendEvent(tx, event)
+	})
+}
+
+// Restore undoes the soft delete of a question and of the answers deleted
+// with it, and records a QuestionCreated event
+func (r *QuestionRepository) Restore(id int) (*models.Question, error) {
+	var question models.Question
+	err := database.GetDB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Clauses(clause.Locking{Strength: "UPDATE"}).First(&question, id).Error; err != nil {
+			return err
+		}
+		if !question.DeletedAt.Valid {
+			return ErrNotDeleted
+		}
 
+		if err := tx.Unscoped().Model(&models.Answer{}).
+			Where("question_id = ? AND deleted_at = ?", id, question.DeletedAt.Time).
+			Update("deleted_at", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Model(&question).UpdateColumns(map[string]interface{}{
+			"deleted_at":   nil,
+			"version":      gorm.Expr("version + 1"),
+			"answer_count": tx.Model(&models.Answer{}).Select("COUNT(*)").Where("question_id = ?", id),
+		}).Error; err != nil {
+			return err
+		}
+		if err := tx.Preload("Answers", func(db *gorm.DB) *gorm.DB {
+			return db.Order("id")
+		}).First(&question, id).Error; err != nil {
+			return err
+		}
 
+		event, err := events.NewQuestionEvent(events.QuestionCreated, &question)
+		if err != nil {
+			return err
+		}
+		return appendEvent(tx, event)
+	})
+	return &question, err
+}
+
+// viewBatchSize is the number of questions counted per statement by RecordViews
+const viewBatchSize = 1000
+
+// RecordViews adds views, counts keyed by question ID, in one transaction;
+// deleted and unknown questions are skipped. Views are not events: only a
+// question passing one of milestones records a QuestionViewed event, so
+// badges for views are awarded without every page view writing to the
+// outbox. The version stays and reads stay on the replicas, since readers
+// see no change.
+func (r *QuestionRepository) RecordViews(views map[int]int, milestones []int) error {
+	// Questions viewed the same number of times share a statement
+	byCount := make(map[int][]int)
+	for id, count := range views {
+		byCount[count] = append(byCount[count], id)
+	}
+	return database.GetDB().Transaction(func(tx *gorm.DB) error {
+		for count, ids := range byCount {
+			slices.Sort(ids)
+			for start := 0; start < len(ids); start += viewBatchSize {
+				var questions []models.Question
+				err := tx.Model(&questions).
+					Clauses(clause.Returning{Columns: []clause.Column{{Name: "id"}, {Name: "user_id"}, {Name: "view_count"}}}).
+					Where("id IN ?", ids[start:min(start+viewBatchSize, len(ids))]).
+					UpdateColumn("view_count", gorm.Expr("view_count + ?", count)).Error
+				if err != nil {
+					return err
+				}
+				for i := range questions {
+					if !passed(milestones, questions[i].ViewCount-count, questions[i].ViewCount) {
+						continue
+					}
+					event, err := events.NewViewEvent(&questions[i])
+					if err != nil {
+						return err
+					}
+					if err := insertEvent(tx, event); err != nil {
+						return err
+					}
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// passed reports whether a count going from before to after reached one
+// of milestones
+func passed(milestones []int, before, after int) bool {
+	for _, milestone := range milestones {
+		if before < milestone && milestone <= after {
+			return true
+		}
+	}
+	return false
+}
+
+// recountBatchSize is the number of questions fixed per statement by RecountAnswers
+const recountBatchSize = 1000
+
+// RecountAnswers rebuilds the denormalized answer counts and returns the
+// number of questions that were off. Changed questions get a new version
+// and a QuestionUpdated event.
+func (r *QuestionRepository) RecountAnswers() (int64, error) {
+	var updated int64
+	err := database.GetDB().Transaction(func(tx *gorm.DB) error {
+		count := tx.Model(&models.Answer{}).Select("COUNT(*)").Where("answers.question_id = questions.id")
+		var ids []int
+		if err := tx.Model(&models.Question{}).Where("answer_count <> (?)", count).Order("id").Pluck("id", &ids).Error; err != nil {
+			return err
+		}
+
+		for start := 0; start < len(ids); start += recountBatchSize {
+			batch := ids[start:min(start+recountBatchSize, len(ids))]
+			result := tx.Model(&models.Question{}).Where("id IN ?", batch).UpdateColumns(map[string]interface{}{
+				"answer_count": count,
+				"version":      gorm.Expr("version + 1"),
+			})
+			if result.Error != nil {
+				return result.Error
+			}
+			updated += result.RowsAffected
+
+			var questions []models.Question
+			if err := tx.Where("id IN ?", batch).Order("id").Find(&questions).Error; err != nil {
+				return err
+			}
+			records := make([]*models.OutboxEvent, 0, len(questions))
+			for i := range questions {
+				record, err := events.NewQuestionEvent(events.QuestionUpdated, &questions[i])
+				if err != nil {
+					return err
+				}
+				records = append(records, record)
+			}
+			if err := appendEvents(tx, records, recountBatchSize); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return updated, err
+}
+
+// Purge permanently removes the questions and answers soft-deleted before
+// the given time and returns how many of each were removed. Votes and
+// follows go with them through the foreign keys. Questions whose accepted
+// answer is purged lose the mark, and the live ones get a new version and
+// a QuestionUpdated event.
+func (r *QuestionRepository) Purge(before time.Time) (questions, answers int64, err error) {
+	err = database.GetDB().Transaction(func(tx *gorm.DB) error {
+		purged := tx.Unscoped().Model(&models.Answer{}).Select("id").Where("deleted_at < ?", before)
+		var accepting []int
+		if err := tx.Unscoped().Model(&models.Question{}).Where("accepted_answer_id IN (?)", purged).Pluck("id", &accepting).Error; err != nil {
+			return err
+		}
+		if len(accepting) > 0 {
+			if err := tx.Unscoped().Model(&models.Question{}).Where("id IN ?", accepting).UpdateColumns(map[string]interface{}{
+				"accepted_answer_id": nil,
+				"version":            gorm.Expr("version + 1"),
+			}).Error; err != nil {
+				return err
+			}
+
+			var live []models.Question
+			if err := tx.Where("id IN ?", accepting).Order("id").Find(&live).Error; err != nil {
+				return err
+			}
+			records := make([]*models.OutboxEvent, 0, len(live))
+			for i := range live {
+				record, err := events.NewQuestionEvent(events.QuestionUpdated, &live[i])
+				if err != nil {
+					return err
+				}
+				records = append(records, record)
+			}
+			if err := appendEvents(tx, records, recountBatchSize); err != nil {
+				return err
+			}
+		}
+
+		result := tx.Unscoped().Where("deleted_at < ?", before).Delete(&models.Answer{})
+		if result.Error != nil {
+			return result.Error
+		}
+		answers = result.RowsAffected
+		result = tx.Unscoped().Where("deleted_at < ?", before).Delete(&models.Question{})
+		if result.Error != nil {
+			return result.Error
+		}
+		questions = result.RowsAffected
+		return nil
+	})
+	return questions, answers, err
+}
+
+// Exists checks if a question exists. It guards writes, so it always asks
+// the primary.
+func (r *QuestionRepository) Exists(id int) (bool, error) {
+	var count int64
+	err := database.GetDB().Model(&models.Question{}).Where("id = ?", id).Count(&count).Error
+	return count > 0, err
+}
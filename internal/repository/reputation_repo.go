@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"qa-api/internal/database"
+	"qa-api/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ReputationRepository handles database operations for the reputation ledger
+type ReputationRepository struct{}
+
+// NewReputationRepository creates a new ReputationRepository
+func NewReputationRepository() *ReputationRepository {
+	return &ReputationRepository{}
+}
+
+// Record appends entries to the ledger, skipping those already recorded
+// for the same event, user and reason
+func (r *ReputationRepository) Record(entries []models.ReputationEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	return database.GetDB().Clauses(clause.OnConflict{DoNothing: true}).Create(&entries).Error
+}
+
+// replaceBatchSize is the number of entries inserted per statement by Replace
+const replaceBatchSize = 1000
+
+// Replace swaps the entries of the events up to throughEventID for entries
+// in one transaction; entries of later events are kept
+func (r *ReputationRepository) Replace(entries []models.ReputationEntry, throughEventID int64) error {
+	return database.GetDB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("event_id <= ?", throughEventID).Delete(&models.ReputationEntry{}).Error; err != nil {
+			return err
+		}
+		if len(entries) == 0 {
+			return nil
+		}
+		return tx.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(&entries, replaceBatchSize).Error
+	})
+}
+
+// Total returns the reputation of a user, 0 for users without entries
+func (r *ReputationRepository) Total(userID string) (int, error) {
+	var total int
+	err := database.Reader().Model(&models.ReputationEntry{}).Select("COALESCE(SUM(delta), 0)").
+		Where("user_id = ?", userID).Scan(&total).Error
+	return total, err
+}
+
+// Leaderboard returns the limit users with the highest reputation; ties are
+// ordered by user ID
+func (r *ReputationRepository) Leaderboard(limit int) ([]models.Standing, error) {
+	standings := []models.Standing{}
+	err := database.Reader().Table("reputation_ledger AS l").
+		Select("l.user_id, COALESCE(u.display_name, '') AS display_name, SUM(l.delta) AS reputation").
+		Joins("LEFT JOIN users AS u ON u.id = l.user_id").
+		Group("l.user_id, u.display_name").
+		Order("reputation DESC, l.user_id").
+		Limit(limit).
+		Scan(&standings).Error
+	return standings, err
+}
@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"qa-api/internal/database"
+	"qa-api/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// UserRepository handles database operations for user profiles
+type UserRepository struct{}
+
+// NewUserRepository creates a new UserRepository
+func NewUserRepository() *UserRepository {
+	return &UserRepository{}
+}
+
+// GetByID retrieves a user by ID
+func (r *UserRepository) GetByID(id string) (*models.User, error) {
+	var user models.User
+	err := database.Reader().Where("id = ?", id).First(&user).Error
+	return &user, err
+}
+
+// Save creates the user or updates the profile fields of an existing one;
+// the join date of an existing user is kept and loaded into user. The saved
+// row is read back from the primary, so the caller sees it even while the
+// replicas lag.
+func (r *UserRepository) Save(user *models.User) error {
+	return database.GetDB().Transaction(func(tx *gorm.DB) error {
+		if user.JoinedAt.IsZero() {
+			user.JoinedAt = time.Now()
+		}
+		if err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"display_name", "avatar_url", "bio"}),
+		}).Create(user).Error; err != nil {
+			return err
+		}
+		return tx.Where("id = ?", user.ID).First(user).Error
+	})
+}
+
+// GetQuestions retrieves the questions asked by a user, ordered by ID
+func (r *UserRepository) GetQuestions(userID string) ([]models.Question, error) {
+	var questions []models.Question
+	err := database.Reader().Where("user_id = ?", userID).Order("id").Find(&questions).Error
+	return questions, err
+}
+
+// GetAnswers retrieves the answers written by a user, ordered by ID
+func (r *UserRepository) GetAnswers(userID string) ([]models.Answer, error) {
+	var answers []models.Answer
+	err := database.Reader().Where("user_id = ?", userID).Order("id").Find(&answers).Error
+	return answers, err
+}
+
+// ensureUsers creates the users that do not exist yet within the caller's
+// transaction; joined maps each user ID to the time of their contribution
+func ensureUsers(tx *gorm.DB, joined map[string]time.Time) error {
+	users := make([]models.User, 0, len(joined))
+	for id, at := range joined {
+		if id == "" {
+			continue
+		}
+		if at.IsZero() {
+			at = time.Now()
+		}
+		users = append(users, models.User{ID: id, JoinedAt: at})
+	}
+	if len(users) == 0 {
+		return nil
+	}
+	return tx.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(&users, 500).Error
+}
+
+// authors maps the authors of questions and their answers to their first
+// contribution among them
+func authors(questions []models.Question) map[string]time.Time {
+	joined := make(map[string]time.Time)
+	add := func(userID string, at time.Time) {
+		if first, ok := joined[userID]; !ok || at.Before(first) {
+			joined[userID] = at
+		}
+	}
+	for _, question := range questions {
+		add(question.UserID, question.CreatedAt)
+		for _, answer := range question.Answers {
+			add(answer.UserID, answer.CreatedAt)
+		}
+	}
+	return joined
+}
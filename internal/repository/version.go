@@ -0,0 +1,24 @@
+package repository
+
+import (
+	"errors"
+	"qa-api/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// ErrVersionMismatch is returned when an optimistic lock check fails
+var ErrVersionMismatch = errors.New("version mismatch")
+
+// ErrNotDeleted is returned when restoring a row that is not deleted
+var ErrNotDeleted = errors.New("not deleted")
+
+// bumpQuestionVersion marks a question as changed and adjusts its answer
+// count by answerDelta; its representation embeds the answers, so adding or
+// removing one is a change of the question
+func bumpQuestionVersion(tx *gorm.DB, questionID, answerDelta int) error {
+	return tx.Model(&models.Question{}).Where("id = ?", questionID).UpdateColumns(map[string]interface{}{
+		"version":      gorm.Expr("version + 1"),
+		"answer_count": gorm.Expr("answer_count + ?", answerDelta),
+	}).Error
+}
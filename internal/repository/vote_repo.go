@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"errors"
+	"qa-api/internal/database"
+	"qa-api/internal/events"
+	"qa-api/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// VoteRepository handles database operations for votes and accepted answers
+type VoteRepository struct{}
+
+// NewVoteRepository creates a new VoteRepository
+func NewVoteRepository() *VoteRepository {
+	return &VoteRepository{}
+}
+
+// Cast sets the vote of a user on an answer to value and records a VoteCast
+// event; 0 retracts the vote. Casting the vote already in place changes
+// nothing and records no event.
+func (r *VoteRepository) Cast(answerID int, userID string, value int) error {
+	return database.GetDB().Transaction(func(tx *gorm.DB) error {
+		// The answer lock serializes the votes on it
+		var answer models.Answer
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&answer, answerID).Error; err != nil {
+			return err
+		}
+		var votes []models.Vote
+		if err := tx.Where("answer_id = ? AND user_id = ?", answerID, userID).Find(&votes).Error; err != nil {
+			return err
+		}
+		previous := 0
+		if len(votes) > 0 {
+			previous = votes[0].Value
+		}
+		if value == previous {
+			return nil
+		}
+
+		var err error
+		switch {
+		case value == 0:
+			err = tx.Where("answer_id = ? AND user_id = ?", answerID, userID).Delete(&models.Vote{}).Error
+		case previous == 0:
+			if err = ensureUsers(tx, map[string]time.Time{userID: time.Now()}); err == nil {
+				err = tx.Create(&models.Vote{AnswerID: answerID, UserID: userID, Value: value}).Error
+			}
+		default:
+			err = tx.Model(&models.Vote{}).Where("answer_id = ? AND user_id = ?", answerID, userID).Update("value", value).Error
+		}
+		if err != nil {
+			return err
+		}
+
+		event, err := events.NewVoteEvent(&answer, userID, value, previous)
+		if err != nil {
+			return err
+		}
+		return appendEvent(tx, event)
+	})
+}
+
+// Accept makes an answer the accepted one of its question, replacing an
+// earlier choice, bumps the question version and records an AnswerAccepted
+// event. Accepting the answer already accepted changes nothing.
+func (r *VoteRepository) Accept(answerID int) error {
+	return database.GetDB().Transaction(func(tx *gorm.DB) error {
+		var answer models.Answer
+		if err := tx.First(&answer, answerID).Error; err != nil {
+			return err
+		}
+		var question models.Question
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&question, answer.QuestionID).Error; err != nil {
+			return err
+		}
+		if question.AcceptedAnswerID != nil && *question.AcceptedAnswerID == answerID {
+			return nil
+		}
+
+		var previous *models.Answer
+		if question.AcceptedAnswerID != nil {
+			var earlier models.Answer
+			err := tx.Unscoped().First(&earlier, *question.AcceptedAnswerID).Error
+			if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+			if err == nil {
+				previous = &earlier
+			}
+		}
+
+		if err := tx.Model(&question).UpdateColumns(map[string]interface{}{
+			"accepted_answer_id": answerID,
+			"version":            gorm.Expr("version + 1"),
+		}).Error; err != nil {
+			return err
+		}
+
+		event, err := events.NewAcceptEvent(&answer, question.UserID, previous)
+		if err != nil {
+			return err
+		}
+		return appendEvent(tx, event)
+	})
+}
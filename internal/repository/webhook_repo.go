@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"qa-api/internal/database"
+	"qa-api/internal/models"
+	"sort"
+	"time"
+
+	"gorm.io/gorm/clause"
+)
+
+// WebhookRepository handles database operations for webhooks and their deliveries
+type WebhookRepository struct{}
+
+// NewWebhookRepository creates a new WebhookRepository
+func NewWebhookRepository() *WebhookRepository {
+	return &WebhookRepository{}
+}
+
+// Create creates a new webhook
+func (r *WebhookRepository) Create(webhook *models.Webhook) error {
+	return database.GetDB().Create(webhook).Error
+}
+
+// GetByID retrieves a webhook by ID
+func (r *WebhookRepository) GetByID(id int) (*models.Webhook, error) {
+	var webhook models.Webhook
+	err := database.GetDB().First(&webhook, id).Error
+	return &webhook, err
+}
+
+// Delete deletes a webhook by ID (cascade delete will handle deliveries)
+func (r *WebhookRepository) Delete(id int) error {
+	return database.GetDB().Delete(&models.Webhook{}, id).Error
+}
+
+// FindForQuestion retrieves webhooks without a question filter or filtered by the given question
+func (r *WebhookRepository) FindForQuestion(questionID int) ([]models.Webhook, error) {
+	var webhooks []models.Webhook
+	err := database.GetDB().Where("question_id IS NULL OR question_id = ?", questionID).Find(&webhooks).Error
+	return webhooks, err
+}
+
+// GetDeliveries retrieves the deliveries of a webhook, newest first
+func (r *WebhookRepository) GetDeliveries(webhookID int) ([]models.WebhookDelivery, error) {
+	var deliveries []models.WebhookDelivery
+	err := database.GetDB().Where("webhook_id = ?", webhookID).Order("id DESC").Find(&deliveries).Error
+	return deliveries, err
+}
+
+// EnqueueDeliveries stores new deliveries, ignoring events already enqueued for a webhook
+func (r *WebhookRepository) EnqueueDeliveries(deliveries []models.WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	return database.GetDB().Clauses(clause.OnConflict{DoNothing: true}).Create(&deliveries).Error
+}
+
+// ClaimDueDeliveries locks pending deliveries whose next attempt is due and pushes
+// it forward by lease, so concurrent workers never pick the same delivery
+func (r *WebhookRepository) ClaimDueDeliveries(limit int, lease time.Duration) ([]models.WebhookDelivery, error) {
+	var deliveries []models.WebhookDelivery
+	now := time.Now()
+	err := database.GetDB().Raw(`
+		UPDATE webhook_deliveries SET next_attempt_at = ?
+		WHERE id IN (
+			SELECT id FROM webhook_deliveries
+			WHERE status = ? AND next_attempt_at <= ?
+			ORDER BY id
+			LIMIT ?
+			`+skipLocked()+`
+		)
+		RETURNING *`, now.Add(lease), models.DeliveryPending, now, limit).Scan(&deliveries).Error
+	if err != nil || len(deliveries) == 0 {
+		return deliveries, err
+	}
+
+	sort.Slice(deliveries, func(i, j int) bool { return deliveries[i].ID < deliveries[j].ID })
+
+	ids := make([]int, 0, len(deliveries))
+	for _, d := range deliveries {
+		ids = append(ids, d.WebhookID)
+	}
+	var webhooks []models.Webhook
+	if err := database.GetDB().Where("id IN ?", ids).Find(&webhooks).Error; err != nil {
+		return nil, err
+	}
+	byID := make(map[int]*models.Webhook, len(webhooks))
+	for i := range webhooks {
+		byID[webhooks[i].ID] = &webhooks[i]
+	}
+	for i := range deliveries {
+		deliveries[i].Webhook = byID[deliveries[i].WebhookID]
+	}
+	return deliveries, nil
+}
+
+// UpdateDelivery saves the outcome of a delivery attempt
+func (r *WebhookRepository) UpdateDelivery(delivery *models.WebhookDelivery) error {
+	return database.GetDB().Model(&models.WebhookDelivery{}).Where("id = ?", delivery.ID).Updates(map[string]interface{}{
+		"status":          delivery.Status,
+		"attempts":        delivery.Attempts,
+		"response_status": delivery.ResponseStatus,
+		"last_error":      delivery.LastError,
+		"next_attempt_at": delivery.NextAttemptAt,
+		"delivered_at":    delivery.DeliveredAt,
+	}).Error
+}
@@ -0,0 +1,80 @@
+package reputation
+
+import (
+	"context"
+	"qa-api/internal/events"
+	"qa-api/internal/models"
+)
+
+// Store keeps the ledger. Record must ignore entries it already holds
+// (same event, user and reason), since events arrive at least once.
+// Replace swaps the entries of the events up to throughEventID for entries
+// in one step and keeps those of later events.
+type Store interface {
+	Record(entries []models.ReputationEntry) error
+	Replace(entries []models.ReputationEntry, throughEventID int64) error
+}
+
+// History provides the event history replayed by Rebuild
+type History interface {
+	EventsAfter(afterID int64, limit int) ([]events.Event, error)
+}
+
+// replayBatch is how many events Rebuild reads at a time
+const replayBatch = 500
+
+// Ledger records the reputation entries of events
+type Ledger struct {
+	store Store
+}
+
+// NewLedger creates a new Ledger
+func NewLedger(store Store) *Ledger {
+	return &Ledger{store: store}
+}
+
+// Handle records the entries of an event; it is an events.Handler
+func (l *Ledger) Handle(ctx context.Context, event events.Event) error {
+	entries, err := Entries(event)
+	if err != nil || len(entries) == 0 {
+		return err
+	}
+	return l.store.Record(entries)
+}
+
+// Rebuild recomputes the ledger from the whole event history, e.g. after
+// the rules changed, and returns the number of events replayed. The entries
+// are collected first and swapped in at once, so readers never see a
+// partial ledger and a failed rebuild leaves the old one in place. Events
+// handled concurrently are recorded once, so the dispatcher may keep running.
+func (l *Ledger) Rebuild(ctx context.Context, history History) (int, error) {
+	var entries []models.ReputationEntry
+	replayed := 0
+	var lastID int64
+	for {
+		batch, err := history.EventsAfter(lastID, replayBatch)
+		if err != nil {
+			return 0, err
+		}
+		for _, event := range batch {
+			eventEntries, err := Entries(event)
+			if err != nil {
+				return 0, err
+			}
+			entries = append(entries, eventEntries...)
+			lastID = event.ID
+			replayed++
+		}
+		if len(batch) < replayBatch {
+			break
+		}
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := l.store.Replace(entries, lastID); err != nil {
+		return 0, err
+	}
+	return replayed, nil
+}
@@ -0,0 +1,122 @@
+package reputation
+
+import (
+	"context"
+	"encoding/json"
+	"qa-api/internal/events"
+	"qa-api/internal/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func event(t *testing.T, id int64, eventType string, payload interface{}) events.Event {
+	t.Helper()
+	data, err := json.Marshal(payload)
+	require.NoError(t, err)
+	return events.Event{ID: id, Type: eventType, Payload: data}
+}
+
+// deltas sums the entries per user
+func deltas(entries []models.ReputationEntry) map[string]int {
+	result := map[string]int{}
+	for _, entry := range entries {
+		result[entry.UserID] += entry.Delta
+	}
+	return result
+}
+
+func TestEntries(t *testing.T) {
+	tests := []struct {
+		name      string
+		eventType string
+		payload   interface{}
+		want      map[string]int
+	}{
+		{"upvote", events.VoteCast, events.VotePayload{UserID: "bob", VoterID: "carol", Value: 1}, map[string]int{"bob": 10}},
+		{"downvote", events.VoteCast, events.VotePayload{UserID: "bob", VoterID: "carol", Value: -1}, map[string]int{"bob": -2}},
+		{"upvote turned down", events.VoteCast, events.VotePayload{UserID: "bob", VoterID: "carol", Value: -1, Previous: 1}, map[string]int{"bob": -12}},
+		{"retracted downvote", events.VoteCast, events.VotePayload{UserID: "bob", VoterID: "carol", Previous: -1}, map[string]int{"bob": 2}},
+		{"first accept", events.AnswerAccepted, events.AcceptPayload{UserID: "bob", AcceptedBy: "alice"}, map[string]int{"bob": 15, "alice": 2}},
+		{"changed accept", events.AnswerAccepted, events.AcceptPayload{UserID: "carol", AcceptedBy: "alice", PreviousAnswerID: 1, PreviousUserID: "bob"}, map[string]int{"carol": 15, "bob": -15}},
+		{"own answer", events.AnswerAccepted, events.AcceptPayload{UserID: "alice", AcceptedBy: "alice"}, map[string]int{}},
+		{"away from own answer", events.AnswerAccepted, events.AcceptPayload{UserID: "bob", AcceptedBy: "alice", PreviousAnswerID: 1, PreviousUserID: "alice"}, map[string]int{"bob": 15}},
+		{"other events", events.AnswerCreated, events.AnswerPayload{UserID: "bob"}, map[string]int{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, err := Entries(event(t, 7, tt.eventType, tt.payload))
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, deltas(entries))
+			for _, entry := range entries {
+				assert.Equal(t, int64(7), entry.EventID)
+			}
+		})
+	}
+
+	_, err := Entries(events.Event{Type: events.VoteCast, Payload: json.RawMessage(`[]`)})
+	assert.Error(t, err)
+}
+
+// fakeStore keeps entries keyed like the unique index of the ledger
+type fakeStore struct {
+	entries  map[string]models.ReputationEntry
+	replaces int
+}
+
+func (s *fakeStore) Record(entries []models.ReputationEntry) error {
+	for _, entry := range entries {
+		key, _ := json.Marshal([]interface{}{entry.EventID, entry.UserID, entry.Reason})
+		if _, ok := s.entries[string(key)]; !ok {
+			s.entries[string(key)] = entry
+		}
+	}
+	return nil
+}
+
+func (s *fakeStore) Replace(entries []models.ReputationEntry, throughEventID int64) error {
+	for key, entry := range s.entries {
+		if entry.EventID <= throughEventID {
+			delete(s.entries, key)
+		}
+	}
+	s.replaces++
+	return s.Record(entries)
+}
+
+type fakeHistory []events.Event
+
+func (h fakeHistory) EventsAfter(afterID int64, limit int) ([]events.Event, error) {
+	var result []events.Event
+	for _, event := range h {
+		if event.ID > afterID && len(result) < limit {
+			result = append(result, event)
+		}
+	}
+	return result, nil
+}
+
+func TestLedger_Rebuild(t *testing.T) {
+	var history fakeHistory
+	for i := int64(1); i <= replayBatch+10; i++ {
+		history = append(history, event(t, i, events.VoteCast, events.VotePayload{UserID: "bob", Value: 1}))
+	}
+	store := &fakeStore{entries: map[string]models.ReputationEntry{}}
+	ledger := NewLedger(store)
+
+	// Entries handled before the rebuild are replaced, duplicates ignored
+	require.NoError(t, ledger.Handle(context.Background(), history[0]))
+	require.NoError(t, ledger.Handle(context.Background(), history[0]))
+	store.entries["stale"] = models.ReputationEntry{UserID: "bob", Delta: 1000}
+
+	replayed, err := ledger.Rebuild(context.Background(), history)
+	require.NoError(t, err)
+	assert.Equal(t, len(history), replayed, "every batch is replayed")
+	assert.Equal(t, 1, store.replaces, "the ledger is swapped in one step")
+	total := 0
+	for _, entry := range store.entries {
+		total += entry.Delta
+	}
+	assert.Equal(t, len(history)*UpvotePoints, total)
+}
@@ -0,0 +1,83 @@
+// Package reputation derives users' reputation from domain events. Every
+// event is turned into ledger entries by fixed rules, so the ledger can be
+// rebuilt at any time by replaying the event history.
+package reputation
+
+import (
+	"encoding/json"
+	"fmt"
+	"qa-api/internal/events"
+	"qa-api/internal/models"
+)
+
+// Points awarded by the rules
+const (
+	UpvotePoints   = 10
+	DownvotePoints = -2
+	AcceptedPoints = 15
+	AcceptPoints   = 2
+)
+
+// Reasons of ledger entries
+const (
+	ReasonVote       = "vote"
+	ReasonAccepted   = "accepted"
+	ReasonUnaccepted = "unaccepted"
+	ReasonAccept     = "accept"
+)
+
+// Entries returns the ledger entries an event yields; most events yield none
+func Entries(event events.Event) ([]models.ReputationEntry, error) {
+	var entries []models.ReputationEntry
+	add := func(userID, reason string, delta int) {
+		if userID == "" || delta == 0 {
+			return
+		}
+		entries = append(entries, models.ReputationEntry{
+			UserID:    userID,
+			EventID:   event.ID,
+			Reason:    reason,
+			Delta:     delta,
+			CreatedAt: event.OccurredAt,
+		})
+	}
+
+	switch event.Type {
+	case events.VoteCast:
+		var payload events.VotePayload
+		if err := json.Unmarshal(event.Payload, &payload); err != nil {
+			return nil, fmt.Errorf("invalid %s payload: %w", event.Type, err)
+		}
+		// A changed vote takes back what the previous one gave
+		add(payload.UserID, ReasonVote, votePoints(payload.Value)-votePoints(payload.Previous))
+
+	case events.AnswerAccepted:
+		var payload events.AcceptPayload
+		if err := json.Unmarshal(event.Payload, &payload); err != nil {
+			return nil, fmt.Errorf("invalid %s payload: %w", event.Type, err)
+		}
+		// Accepting your own answer earns nothing
+		if payload.UserID != payload.AcceptedBy {
+			add(payload.UserID, ReasonAccepted, AcceptedPoints)
+		}
+		if payload.PreviousUserID != payload.AcceptedBy {
+			add(payload.PreviousUserID, ReasonUnaccepted, -AcceptedPoints)
+		}
+		// The asker is rewarded once per question, not for every change of mind
+		if payload.PreviousAnswerID == 0 && payload.UserID != payload.AcceptedBy {
+			add(payload.AcceptedBy, ReasonAccept, AcceptPoints)
+		}
+	}
+	return entries, nil
+}
+
+func votePoints(value int) int {
+	switch value {
+	case 1:
+		return UpvotePoints
+	case -1:
+		return DownvotePoints
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,199 @@
+// Package router wires the HTTP handlers to their routes.
+package router
+
+import (
+	"expvar"
+	"fmt"
+	"mime"
+	"net/http"
+	"qa-api/internal/handler"
+	"qa-api/internal/openapi"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// Handlers groups everything served over HTTP
+type Handlers struct {
+	Question     *handler.QuestionHandler
+	Answer       *handler.AnswerHandler
+	Webhook      *handler.WebhookHandler
+	Stream       *handler.StreamHandler
+	WebSocket    *handler.WebSocketHandler
+	V2           *handler.V2Handler
+	User         *handler.UserHandler
+	Vote         *handler.VoteHandler
+	Badge        *handler.BadgeHandler
+	Notification *handler.NotificationHandler
+	Admin        *handler.AdminHandler
+	Flags        *handler.FlagsHandler
+	GraphQL      http.Handler
+}
+
+// Options tunes versioning behaviour
+type Options struct {
+	// DeprecatedAt and Sunset are announced on unversioned paths
+	DeprecatedAt time.Time
+	Sunset       time.Time
+
+	// Admin guards the /admin routes, e.g. by restricting them to some users
+	Admin []mux.MiddlewareFunc
+}
+
+// route is a versioned REST endpoint; v2 is nil when v2 has no own representation
+type route struct {
+	path   string
+	method string
+	v1     http.HandlerFunc
+	v2     http.HandlerFunc
+}
+
+func restRoutes(h Handlers) []route {
+	return []route{
+		// Question routes
+		{"/questions/", "GET", h.Question.GetQuestions, h.V2.GetQuestions},
+		{"/questions/", "POST", h.Question.CreateQuestion, h.V2.CreateQuestion},
+		{"/questions/{id}", "GET", h.Question.GetQuestion, h.V2.GetQuestion},
+		{"/questions/{id}", "DELETE", h.Question.DeleteQuestion, h.Question.DeleteQuestion},
+		{"/questions/{id}/events", "GET", h.Stream.QuestionEvents, nil},
+
+		// Answer routes
+		{"/questions/{id}/answers/", "POST", h.Answer.CreateAnswer, h.V2.CreateAnswer},
+		{"/answers/{id}", "GET", h.Answer.GetAnswer, h.V2.GetAnswer},
+		{"/answers/{id}", "DELETE", h.Answer.DeleteAnswer, h.Answer.DeleteAnswer},
+		{"/answers/{id}/vote", "PUT", h.Vote.Vote, nil},
+		{"/answers/{id}/vote", "DELETE", h.Vote.Retract, nil},
+		{"/answers/{id}/accept", "POST", h.Vote.Accept, nil},
+
+		// User routes
+		{"/users/{id}", "GET", h.User.GetUser, nil},
+		{"/users/{id}", "PUT", h.User.UpdateUser, nil},
+		{"/leaderboard", "GET", h.User.GetLeaderboard, nil},
+
+		// Badge routes
+		{"/badges", "GET", h.Badge.GetBadges, nil},
+		{"/users/{id}/badges", "GET", h.Badge.GetUserBadges, nil},
+
+		// Notification routes
+		{"/me/notifications", "GET", h.Notification.GetNotifications, nil},
+		{"/me/notifications/read-all", "POST", h.Notification.MarkAllRead, nil},
+		{"/me/notifications/preferences", "GET", h.Notification.GetPreferences, nil},
+		{"/me/notifications/preferences", "PUT", h.Notification.UpdatePreferences, nil},
+		{"/me/notifications/{id}/read", "POST", h.Notification.MarkRead, nil},
+		{"/questions/{id}/follow", "PUT", h.Notification.Follow, nil},
+		{"/questions/{id}/follow", "DELETE", h.Notification.Unfollow, nil},
+
+		// Webhook routes
+		{"/webhooks", "POST", h.Webhook.CreateWebhook, nil},
+		{"/webhooks/{id}", "DELETE", h.Webhook.DeleteWebhook, nil},
+		{"/webhooks/{id}/deliveries", "GET", h.Webhook.GetDeliveries, nil},
+	}
+}
+
+// New builds the router; middlewares run after a route is matched.
+//
+// REST endpoints live under /v1 and /v2. The unversioned paths are kept as
+// deprecated aliases of v1 unless the client asks for v2 via Accept.
+func New(h Handlers, opts Options, middlewares ...mux.MiddlewareFunc) *mux.Router {
+	router := mux.NewRouter()
+	router.Use(middlewares...)
+
+	v1 := router.PathPrefix("/v1").Subrouter()
+	v2 := router.PathPrefix("/v2").Subrouter()
+	for _, rt := range restRoutes(h) {
+		v1.HandleFunc(rt.path, rt.v1).Methods(rt.method)
+		if rt.v2 != nil {
+			v2.HandleFunc(rt.path, rt.v2).Methods(rt.method)
+		}
+		router.Handle(rt.path, legacy(rt, opts)).Methods(rt.method)
+	}
+
+	// Bulk import and export
+	admin := router.PathPrefix("/admin").Subrouter()
+	admin.Use(opts.Admin...)
+	admin.HandleFunc("/import", h.Admin.Import).Methods("POST")
+	admin.HandleFunc("/export", h.Admin.Export).Methods("GET")
+
+	// Feature flags
+	admin.HandleFunc("/flags", h.Flags.Get).Methods("GET")
+	admin.HandleFunc("/flags/reload", h.Flags.Reload).Methods("POST")
+
+	// GraphQL
+	router.Handle("/graphql", h.GraphQL).Methods("GET", "POST")
+
+	// Real-time feed
+	router.HandleFunc("/ws", h.WebSocket.Serve).Methods("GET")
+
+	// API description
+	router.HandleFunc("/openapi.json", openapi.SpecHandler).Methods("GET")
+	router.HandleFunc("/docs", openapi.DocsHandler).Methods("GET")
+
+	// Metrics (expvar counters such as question_cache hits and misses),
+	// restricted like the admin routes
+	debug := router.PathPrefix("/debug").Subrouter()
+	debug.Use(opts.Admin...)
+	debug.HandleFunc("/vars", vars).Methods("GET")
+
+	// Health check
+	router.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("OK"))
+	}).Methods("GET")
+
+	return router
+}
+
+// vars serves the expvar variables like expvar.Handler, except for the
+// command line, which may carry settings
+func vars(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	fmt.Fprint(w, "{\n")
+	first := true
+	expvar.Do(func(kv expvar.KeyValue) {
+		if kv.Key == "cmdline" {
+			return
+		}
+		if !first {
+			fmt.Fprint(w, ",\n")
+		}
+		first = false
+		fmt.Fprintf(w, "%q: %s", kv.Key, kv.Value)
+	})
+	fmt.Fprint(w, "\n}\n")
+}
+
+// legacy serves an unversioned path: v2 when negotiated through Accept,
+// otherwise v1 with Deprecation, Sunset and successor Link headers
+func legacy(rt route, opts Options) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if rt.v2 != nil {
+			w.Header().Add("Vary", "Accept")
+			if acceptsV2(r.Header.Get("Accept")) {
+				rt.v2(w, r)
+				return
+			}
+		}
+
+		if !opts.DeprecatedAt.IsZero() {
+			w.Header().Set("Deprecation", fmt.Sprintf("@%d", opts.DeprecatedAt.Unix()))
+		}
+		if !opts.Sunset.IsZero() {
+			w.Header().Set("Sunset", opts.Sunset.UTC().Format(http.TimeFormat))
+		}
+		w.Header().Set("Link", fmt.Sprintf("</v1%s>; rel=\"successor-version\"", r.URL.Path))
+		rt.v1(w, r)
+	})
+}
+
+// acceptsV2 reports whether the Accept header lists the v2 media type
+func acceptsV2(accept string) bool {
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil || mediaType != handler.MediaTypeV2 {
+			continue
+		}
+		return params["q"] != "0"
+	}
+	return false
+}
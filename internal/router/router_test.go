@@ -0,0 +1,451 @@
+package router
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"qa-api/internal/flags"
+	"qa-api/internal/handler"
+	"qa-api/internal/models"
+	"qa-api/internal/openapi"
+	"qa-api/internal/service"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEveryRouteIsDocumented(t *testing.T) {
+	validator, err := openapi.NewValidator(openapi.Spec())
+	require.NoError(t, err)
+
+	router := New(Handlers{}, Options{})
+	routes := 0
+	err = router.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		if route.GetHandler() == nil {
+			// version prefixes only hold subrouters
+			return nil
+		}
+		path, err := route.GetPathTemplate()
+		require.NoError(t, err)
+		methods, err := route.GetMethods()
+		require.NoError(t, err)
+
+		for _, method := range methods {
+			routes++
+			assert.True(t, validator.HasOperation(method, path), "%s %s is missing from openapi.json", method, path)
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	assert.NotZero(t, routes)
+}
+
+type stubQuestionService struct{}
+
+func (stubQuestionService) CreateQuestion(userID, text string) (*models.Question, error) {
+	return &models.Question{ID: 1, UserID: userID, Text: text, CreatedAt: time.Now(), Version: 1}, nil
+}
+
+func (stubQuestionService) GetAllQuestions() ([]models.Question, error) {
+	return []models.Question{{ID: 1, Text: "What is Go?", CreatedAt: time.Now(), Version: 1}}, nil
+}
+
+func (s stubQuestionService) GetAllQuestionsWithAnswers() ([]models.Question, error) {
+	question, _ := s.GetQuestionByID(1)
+	return []models.Question{*question}, nil
+}
+
+func (stubQuestionService) GetQuestionByID(id int) (*models.Question, error) {
+	if id != 1 {
+		return nil, errors.New("question not found")
+	}
+	return &models.Question{ID: 1, Text: "What is Go?", CreatedAt: time.Now(), Version: 1, Answers: []models.Answer{
+		{ID: 1, QuestionID: 1, UserID: "user-1", Text: "A language", CreatedAt: time.Now(), Version: 1},
+	}}, nil
+}
+
+func (stubQuestionService) DeleteQuestion(id, version int) error {
+	if version != 0 && version != 1 {
+		return errors.New("version mismatch")
+	}
+	return nil
+}
+
+func (stubQuestionService) RecordView(id int, viewer string) error { return nil }
+
+type stubAnswerService struct{}
+
+func (stubAnswerService) CreateAnswer(questionID int, userID, text string) (*models.Answer, error) {
+	if userID == "" {
+		// The service requires an author, authenticated or named
+		return nil, errors.New("user_id is required")
+	}
+	return &models.Answer{ID: 1, QuestionID: questionID, UserID: userID, Text: text, CreatedAt: time.Now(), Version: 1}, nil
+}
+
+func (stubAnswerService) GetAnswerByID(id int) (*models.Answer, error) {
+	return &models.Answer{ID: id, QuestionID: 1, UserID: "user-1", Text: "A language", CreatedAt: time.Now(), Version: 1,
+		Question: models.Question{ID: 1, Text: "What is Go?", CreatedAt: time.Now(), Version: 1}}, nil
+}
+
+func (stubAnswerService) DeleteAnswer(id, version int) error {
+	return nil
+}
+
+// testFlags has no file, so every flag is off
+var testFlags, _ = flags.NewStore("")
+
+func testHandlers() Handlers {
+	return Handlers{
+		Question: handler.NewQuestionHandler(stubQuestionService{}),
+		Answer:   handler.NewAnswerHandler(stubAnswerService{}),
+		V2:       handler.NewV2Handler(stubQuestionService{}, stubAnswerService{}),
+		Admin:    handler.NewAdminHandler(service.NewDatasetService(stubDatasetRepo{})),
+		Flags:    handler.NewFlagsHandler(testFlags),
+	}
+}
+
+type stubDatasetRepo struct{}
+
+func (stubDatasetRepo) CreateInBatches(questions []models.Question, batchSize int) error {
+	return nil
+}
+
+func (stubDatasetRepo) GetPageWithAnswers(afterID, limit int) ([]models.Question, error) {
+	if afterID > 0 {
+		return nil, nil
+	}
+	return []models.Question{{ID: 1, Text: "What is Go?", Answers: []models.Answer{{ID: 1, QuestionID: 1, UserID: "alice", Text: "A language"}}}}, nil
+}
+
+func TestResponsesMatchSpec(t *testing.T) {
+	validator, err := openapi.NewValidator(openapi.Spec())
+	require.NoError(t, err)
+
+	var mismatches []string
+	report := func(r *http.Request, err error) {
+		mismatches = append(mismatches, r.Method+" "+r.URL.Path+": "+err.Error())
+	}
+	router := New(testHandlers(), Options{}, validator.ResponseMiddleware(report), validator.Middleware)
+
+	tests := []struct {
+		method string
+		path   string
+		body   string
+		status int
+	}{
+		{"GET", "/questions/", "", http.StatusOK},
+		{"POST", "/questions/", `{"text":"What is Go?"}`, http.StatusCreated},
+		{"POST", "/questions/", `{"text":42}`, http.StatusBadRequest},
+		{"POST", "/questions/", `{}`, http.StatusBadRequest},
+		{"POST", "/questions/", `{"text":"What is Go?","extra":1}`, http.StatusBadRequest},
+		{"GET", "/questions/1", "", http.StatusOK},
+		{"GET", "/questions/2", "", http.StatusNotFound},
+		{"GET", "/questions/abc", "", http.StatusBadRequest},
+		{"GET", "/questions/0", "", http.StatusBadRequest},
+		{"DELETE", "/questions/1", "", http.StatusNoContent},
+		{"POST", "/questions/1/answers/", `{"user_id":"user-1","text":"A language"}`, http.StatusCreated},
+		{"POST", "/questions/1/answers/", `{"text":"A language"}`, http.StatusBadRequest},
+		{"GET", "/answers/1", "", http.StatusOK},
+		{"DELETE", "/answers/1", "", http.StatusNoContent},
+		{"GET", "/v1/questions/1", "", http.StatusOK},
+		{"POST", "/v1/questions/1/answers/", `{"user_id":"user-1","text":"A language"}`, http.StatusCreated},
+		{"GET", "/v1/questions/?include=answers", "", http.StatusOK},
+		{"GET", "/v1/questions/1?fields=id,text&include=", "", http.StatusOK},
+		{"GET", "/v1/answers/1?include=question", "", http.StatusOK},
+		{"GET", "/v1/answers/1?fields=bogus", "", http.StatusBadRequest},
+		{"GET", "/v2/questions/", "", http.StatusOK},
+		{"GET", "/v2/questions/?fields=id,answers", "", http.StatusOK},
+		{"POST", "/v2/questions/", `{"text":"What is Go?"}`, http.StatusCreated},
+		{"GET", "/v2/questions/1", "", http.StatusOK},
+		{"GET", "/v2/questions/2", "", http.StatusNotFound},
+		{"DELETE", "/v2/questions/1", "", http.StatusNoContent},
+		{"POST", "/v2/questions/1/answers/", `{"user_id":"user-1","text":"A language"}`, http.StatusCreated},
+		{"GET", "/v2/answers/1", "", http.StatusOK},
+		{"GET", "/health", "", http.StatusOK},
+		{"GET", "/openapi.json", "", http.StatusOK},
+		{"GET", "/docs", "", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			if tt.body != "" {
+				req.Header.Set("Content-Type", "application/json")
+			}
+			if tt.method == "DELETE" {
+				req.Header.Set("If-Match", `W/"1"`)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			assert.Equal(t, tt.status, w.Code, w.Body.String())
+		})
+	}
+	assert.Empty(t, mismatches)
+}
+
+func TestStrictDecodingResponsesMatchSpec(t *testing.T) {
+	validator, err := openapi.NewValidator(openapi.Spec())
+	require.NoError(t, err)
+
+	var mismatches []string
+	report := func(r *http.Request, err error) {
+		mismatches = append(mismatches, r.Method+" "+r.URL.Path+": "+err.Error())
+	}
+	// No request validation here so the handlers' own checks are exercised
+	router := New(testHandlers(), Options{}, validator.ResponseMiddleware(report))
+
+	tests := []struct {
+		name        string
+		path        string
+		contentType string
+		body        string
+		status      int
+	}{
+		{"wrong content type", "/questions/", "text/plain", `{"text":"What is Go?"}`, http.StatusUnsupportedMediaType},
+		{"unknown field", "/questions/", "application/json", `{"text":"What is Go?","extra":1}`, http.StatusBadRequest},
+		{"trailing data", "/questions/", "application/json", `{"text":"What is Go?"} {}`, http.StatusBadRequest},
+		{"too large", "/questions/", "application/json", `{"text":"` + strings.Repeat("a", 1<<20) + `"}`, http.StatusRequestEntityTooLarge},
+		{"field errors", "/questions/1/answers/", "application/json", `{"user_id":"bad id","text":""}`, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			assert.Equal(t, tt.status, w.Code, w.Body.String())
+		})
+	}
+	assert.Empty(t, mismatches)
+}
+
+func TestVersioning(t *testing.T) {
+	sunset := time.Date(2027, 6, 30, 0, 0, 0, 0, time.UTC)
+	deprecatedAt := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+	router := New(testHandlers(), Options{DeprecatedAt: deprecatedAt, Sunset: sunset})
+
+	serve := func(path, accept string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", path, nil)
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+		return w
+	}
+
+	t.Run("v1 path is not deprecated", func(t *testing.T) {
+		w := serve("/v1/answers/1", "")
+		assert.Empty(t, w.Header().Get("Deprecation"))
+		assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+		assert.Contains(t, w.Body.String(), `"user_id":"user-1"`)
+	})
+
+	t.Run("v2 path uses v2 DTOs", func(t *testing.T) {
+		w := serve("/v2/answers/1", "")
+		assert.Equal(t, handler.MediaTypeV2, w.Header().Get("Content-Type"))
+		assert.Contains(t, w.Body.String(), `"author":{"id":"user-1"}`)
+		assert.NotContains(t, w.Body.String(), `"question"`)
+	})
+
+	t.Run("unversioned path is a deprecated v1 alias", func(t *testing.T) {
+		w := serve("/answers/1", "application/json")
+		assert.Equal(t, "@1792368000", w.Header().Get("Deprecation"))
+		assert.Equal(t, "Wed, 30 Jun 2027 00:00:00 GMT", w.Header().Get("Sunset"))
+		assert.Equal(t, `</v1/answers/1>; rel="successor-version"`, w.Header().Get("Link"))
+		assert.Equal(t, "Accept", w.Header().Get("Vary"))
+		assert.Contains(t, w.Body.String(), `"user_id":"user-1"`)
+	})
+
+	t.Run("unversioned path negotiates v2", func(t *testing.T) {
+		w := serve("/answers/1", "text/html, application/vnd.qa.v2+json;q=0.9")
+		assert.Empty(t, w.Header().Get("Deprecation"))
+		assert.Equal(t, handler.MediaTypeV2, w.Header().Get("Content-Type"))
+		assert.Contains(t, w.Body.String(), `"author":{"id":"user-1"}`)
+	})
+
+	t.Run("v2 list is wrapped", func(t *testing.T) {
+		w := serve("/questions/", handler.MediaTypeV2)
+		assert.True(t, strings.HasPrefix(w.Body.String(), `{"data":[`))
+	})
+}
+
+func TestConditionalRequests(t *testing.T) {
+	validator, err := openapi.NewValidator(openapi.Spec())
+	require.NoError(t, err)
+
+	var mismatches []string
+	report := func(r *http.Request, err error) {
+		mismatches = append(mismatches, r.Method+" "+r.URL.Path+": "+err.Error())
+	}
+	router := New(testHandlers(), Options{}, validator.ResponseMiddleware(report), validator.Middleware)
+
+	serve := func(method, path string, header http.Header) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		for key, values := range header {
+			req.Header[key] = values
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	first := serve("GET", "/v1/questions/1", nil)
+	require.Equal(t, http.StatusOK, first.Code)
+	etag := first.Header().Get("ETag")
+	assert.Equal(t, `W/"1"`, etag)
+
+	t.Run("If-None-Match with current ETag", func(t *testing.T) {
+		w := serve("GET", "/v1/questions/1", http.Header{"If-None-Match": {etag}})
+		assert.Equal(t, http.StatusNotModified, w.Code)
+		assert.Empty(t, w.Body.String())
+		assert.Equal(t, etag, w.Header().Get("ETag"))
+	})
+
+	t.Run("If-None-Match with stale ETag", func(t *testing.T) {
+		w := serve("GET", "/v1/questions/1", http.Header{"If-None-Match": {`W/"0"`}})
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("list ETag", func(t *testing.T) {
+		list := serve("GET", "/v1/questions/", nil)
+		require.NotEmpty(t, list.Header().Get("ETag"))
+		w := serve("GET", "/v1/questions/", http.Header{"If-None-Match": {list.Header().Get("ETag")}})
+		assert.Equal(t, http.StatusNotModified, w.Code)
+	})
+
+	t.Run("answer ETag covers included question", func(t *testing.T) {
+		assert.Equal(t, `W/"1"`, serve("GET", "/v1/answers/1", nil).Header().Get("ETag"))
+		assert.Regexp(t, `^W/"1\.1\.[0-9a-f]{8}"$`, serve("GET", "/v1/answers/1?include=question", nil).Header().Get("ETag"))
+	})
+
+	t.Run("ETag covers fields and include", func(t *testing.T) {
+		sparse := serve("GET", "/v1/questions/1?fields=text", nil).Header().Get("ETag")
+		assert.NotEqual(t, etag, sparse)
+		assert.Equal(t, sparse, serve("GET", "/v1/questions/1?include=answers&fields=text", nil).Header().Get("ETag"),
+			"relations asked for in fields are part of the same representation")
+
+		w := serve("GET", "/v1/questions/1", http.Header{"If-None-Match": {sparse}})
+		assert.Equal(t, http.StatusOK, w.Code, "a sparse copy does not validate the full one")
+		w = serve("GET", "/v1/questions/1?fields=text", http.Header{"If-None-Match": {sparse}})
+		assert.Equal(t, http.StatusNotModified, w.Code)
+	})
+
+	t.Run("delete without If-Match", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("DELETE", "/v1/questions/1", nil))
+		assert.Equal(t, http.StatusPreconditionRequired, w.Code)
+	})
+
+	t.Run("delete with stale If-Match", func(t *testing.T) {
+		w := serve("DELETE", "/v1/questions/1", http.Header{"If-Match": {`W/"2"`}})
+		assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+	})
+
+	t.Run("delete with current If-Match", func(t *testing.T) {
+		w := serve("DELETE", "/v1/questions/1", http.Header{"If-Match": {etag}})
+		assert.Equal(t, http.StatusNoContent, w.Code)
+	})
+
+	t.Run("delete with wildcard", func(t *testing.T) {
+		w := serve("DELETE", "/v1/questions/1", http.Header{"If-Match": {"*"}})
+		assert.Equal(t, http.StatusNoContent, w.Code)
+	})
+
+	assert.Empty(t, mismatches)
+}
+
+func TestAdminRoutes(t *testing.T) {
+	validator, err := openapi.NewValidator(openapi.Spec())
+	require.NoError(t, err)
+
+	var mismatches []string
+	report := func(r *http.Request, err error) {
+		mismatches = append(mismatches, r.Method+" "+r.URL.Path+": "+err.Error())
+	}
+	router := New(testHandlers(), Options{}, validator.ResponseMiddleware(report), validator.Middleware)
+
+	tests := []struct {
+		name        string
+		method      string
+		path        string
+		contentType string
+		body        string
+		status      int
+		contains    string
+	}{
+		{"ndjson import", "POST", "/admin/import", "application/x-ndjson", `{"text":"Q","answers":[{"user_id":"alice","text":"A"}]}` + "\n" + `{"text":""}`, http.StatusOK, `"questions":1`},
+		{"csv dry run", "POST", "/admin/import?dry_run=true", "text/csv", "question_text,answer_user_id,answer_text\nQ,alice,A\n", http.StatusOK, `"dry_run":true`},
+		{"format overrides content type", "POST", "/admin/import?format=csv", "text/plain", "question_text\nQ\n", http.StatusOK, `"questions":1`},
+		{"unsupported content type", "POST", "/admin/import", "application/json", `{"text":"Q"}`, http.StatusUnsupportedMediaType, ""},
+		{"bad csv header", "POST", "/admin/import", "text/csv", "colour\n", http.StatusBadRequest, "unknown CSV column"},
+		{"bad dry_run", "POST", "/admin/import?dry_run=maybe", "text/csv", "question_text\n", http.StatusBadRequest, ""},
+		{"ndjson export", "GET", "/admin/export", "", "", http.StatusOK, `"user_id":"alice"`},
+		{"csv export", "GET", "/admin/export?format=csv", "", "", http.StatusOK, "question_id,question_text"},
+		{"bad export format", "GET", "/admin/export?format=xml", "", "", http.StatusBadRequest, ""},
+		{"feature flags", "GET", "/admin/flags?user_id=alice", "", "", http.StatusOK, `"enabled_for_user":false`},
+		{"reload feature flags", "POST", "/admin/flags/reload", "", "", http.StatusOK, `"flags"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			assert.Equal(t, tt.status, w.Code, w.Body.String())
+			assert.Contains(t, w.Body.String(), tt.contains)
+		})
+	}
+	assert.Empty(t, mismatches)
+
+	t.Run("export negotiates CSV through Accept", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/admin/export", nil)
+		req.Header.Set("Accept", "text/csv")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+	})
+
+	t.Run("admin middleware guards the routes", func(t *testing.T) {
+		forbid := func(http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				http.Error(w, "Forbidden", http.StatusForbidden)
+			})
+		}
+		router := New(testHandlers(), Options{Admin: []mux.MiddlewareFunc{forbid}})
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/admin/export", nil))
+		assert.Equal(t, http.StatusForbidden, w.Code)
+
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/debug/vars", nil))
+		assert.Equal(t, http.StatusForbidden, w.Code)
+
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/health", nil))
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("metrics leave out the command line", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/debug/vars", nil))
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var metrics map[string]json.RawMessage
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &metrics))
+		assert.Contains(t, metrics, "memstats")
+		assert.NotContains(t, metrics, "cmdline")
+	})
+}
@@ -3,25 +3,35 @@ package service
 import (
 	"errors"
 	"qa-api/internal/models"
-	"qa-api/internal/repository"
+	"qa-api/internal/validation"
 	"strings"
+
+	"gorm.io/gorm"
 )
 
 // AnswerService handles business logic for answers
 type AnswerService struct {
-	answerRepo   *repository.AnswerRepository
-	questionRepo *repository.QuestionRepository
+	answerRepo   AnswerRepositoryInterface
+	questionRepo QuestionRepositoryInterface
 }
 
 // NewAnswerService creates a new AnswerService
-func NewAnswerService(answerRepo *repository.AnswerRepository, questionRepo *repository.QuestionRepository) *AnswerService {
+func NewAnswerService(answerRepo AnswerRepositoryInterface, questionRepo QuestionRepositoryInterface) *AnswerService {
 	return &AnswerService{
 		answerRepo:   answerRepo,
 		questionRepo: questionRepo,
 	}
 }
 
-// CreateAnswer creates a new answer for a question
+// newAnswer holds the fields of an answer to create, checked here for every
+// transport
+type newAnswer struct {
+	UserID string `json:"user_id" validate:"required,userid"`
+	Text   string `json:"text" validate:"required,max=10000,nocontrol"`
+}
+
+// CreateAnswer creates a new answer for a question. Invalid fields are
+// reported as validation.Errors.
 func (s *AnswerService) CreateAnswer(questionID int, userID, text string) (*models.Answer, error) {
 	text = strings.TrimSpace(text)
 	if text == "" {
@@ -33,6 +43,11 @@ func (s *AnswerService) CreateAnswer(questionID int, userID, text string) (*mode
 		return nil, errors.New("user_id cannot be empty")
 	}
 
+	input := newAnswer{UserID: userID, Text: text}
+	if errs := validation.Struct(&input); errs != nil {
+		return nil, errs
+	}
+
 	// Check if question exists
 	exists, err := s.questionRepo.Exists(questionID)
 	if err != nil {
@@ -44,8 +59,8 @@ func (s *AnswerService) CreateAnswer(questionID int, userID, text string) (*mode
 
 	answer := &models.Answer{
 		QuestionID: questionID,
-		UserID:     userID,
-		Text:       text,
+		UserID:     input.UserID,
+		Text:       input.Text,
 	}
 
 	if err := s.answerRepo.Create(answer); err != nil {
@@ -64,8 +79,8 @@ func (s *AnswerService) GetAnswerByID(id int) (*models.Answer, error) {
 	return answer, nil
 }
 
-// DeleteAnswer deletes an answer by ID
-func (s *AnswerService) DeleteAnswer(id int) error {
+// DeleteAnswer deletes an answer by ID if its version matches (0 matches any)
+func (s *AnswerService) DeleteAnswer(id, version int) error {
 	answer, err := s.answerRepo.GetByID(id)
 	if err != nil {
 		return errors.New("answer not found")
@@ -74,7 +89,24 @@ func (s *AnswerService) DeleteAnswer(id int) error {
 		return errors.New("answer not found")
 	}
 
-	return s.answerRepo.Delete(id)
+	// A concurrent delete may win the row lock after the lookup above
+	err = s.answerRepo.Delete(id, version)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return errors.New("answer not found")
+	}
+	return err
+}
+
+// RestoreAnswer undoes the deletion of an answer whose question still exists
+func (s *AnswerService) RestoreAnswer(id int) (*models.Answer, error) {
+	answer, err := s.answerRepo.Restore(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.New("answer or its question not found")
+	}
+	if err != nil && err.Error() == "not deleted" {
+		return nil, errors.New("answer is not deleted")
+	}
+	return answer, err
 }
 
 
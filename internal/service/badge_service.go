@@ -0,0 +1,42 @@
+package service
+
+import (
+	"errors"
+	"qa-api/internal/badges"
+	"qa-api/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// BadgeService handles business logic for badges. Awarding them is up to
+// the badges.Engine, which follows the domain events.
+type BadgeService struct {
+	rules     []badges.Rule
+	badgeRepo BadgeRepositoryInterface
+	userRepo  UserRepositoryInterface
+}
+
+// NewBadgeService creates a new BadgeService
+func NewBadgeService(rules []badges.Rule, badgeRepo BadgeRepositoryInterface, userRepo UserRepositoryInterface) *BadgeService {
+	return &BadgeService{
+		rules:     rules,
+		badgeRepo: badgeRepo,
+		userRepo:  userRepo,
+	}
+}
+
+// GetBadges returns the badges that can be earned
+func (s *BadgeService) GetBadges() []badges.Rule {
+	return s.rules
+}
+
+// GetUserBadges retrieves the badges of a user in the order they were awarded
+func (s *BadgeService) GetUserBadges(userID string) ([]models.BadgeAward, error) {
+	if _, err := s.userRepo.GetByID(userID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("user not found")
+		}
+		return nil, err
+	}
+	return s.badgeRepo.Awards(userID)
+}
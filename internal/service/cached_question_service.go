@@ -0,0 +1,84 @@
+package service
+
+import (
+	"qa-api/internal/cache"
+	"qa-api/internal/events"
+	"qa-api/internal/models"
+	"strconv"
+	"sync/atomic"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// CachedQuestionService serves GetQuestionByID through a read-through cache
+// and delegates everything else. Cached questions are shared between
+// callers and must be treated as read-only.
+type CachedQuestionService struct {
+	QuestionServiceInterface
+	cache cache.Cache[int, *models.Question]
+	group singleflight.Group
+
+	// generation changes on every invalidation, so a load that raced with
+	// one does not put data it read before the change back into the cache
+	generation atomic.Uint64
+}
+
+// NewCachedQuestionService wraps a question service with a cache
+func NewCachedQuestionService(next QuestionServiceInterface, c cache.Cache[int, *models.Question]) *CachedQuestionService {
+	return &CachedQuestionService{
+		QuestionServiceInterface: next,
+		cache:                    c,
+	}
+}
+
+// GetQuestionByID returns the cached question or loads it. Concurrent misses
+// for the same ID share a single load.
+func (s *CachedQuestionService) GetQuestionByID(id int) (*models.Question, error) {
+	if question, ok := s.cache.Get(id); ok {
+		return question, nil
+	}
+
+	value, err, _ := s.group.Do(strconv.Itoa(id), func() (interface{}, error) {
+		generation := s.generation.Load()
+		question, err := s.QuestionServiceInterface.GetQuestionByID(id)
+		if err != nil {
+			return nil, err
+		}
+		if s.generation.Load() == generation {
+			s.cache.Set(id, question)
+		}
+		return question, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return value.(*models.Question), nil
+}
+
+// DeleteQuestion deletes the question and drops it from the cache
+func (s *CachedQuestionService) DeleteQuestion(id, version int) error {
+	err := s.QuestionServiceInterface.DeleteQuestion(id, version)
+	s.invalidate(id)
+	return err
+}
+
+// Invalidate drops the question an event changed. Answers are embedded in
+// the cached question, so answer events invalidate their question too;
+// votes change neither.
+func (s *CachedQuestionService) Invalidate(event events.Event) {
+	switch event.Type {
+	case events.QuestionDeleted, events.QuestionUpdated, events.AnswerCreated, events.AnswerDeleted, events.AnswerAccepted:
+		s.invalidate(event.QuestionID)
+	}
+}
+
+// Purge drops every cached question, e.g. after missing events
+func (s *CachedQuestionService) Purge() {
+	s.generation.Add(1)
+	s.cache.Purge()
+}
+
+func (s *CachedQuestionService) invalidate(id int) {
+	s.generation.Add(1)
+	s.cache.Delete(id)
+}
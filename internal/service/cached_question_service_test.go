@@ -0,0 +1,114 @@
+package service
+
+import (
+	"errors"
+	"qa-api/internal/cache"
+	"qa-api/internal/events"
+	"qa-api/internal/models"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingQuestionService counts loads and can hold them until released
+type countingQuestionService struct {
+	QuestionServiceInterface
+	loads   atomic.Int32
+	release chan struct{}
+}
+
+func (s *countingQuestionService) GetQuestionByID(id int) (*models.Question, error) {
+	s.loads.Add(1)
+	if s.release != nil {
+		<-s.release
+	}
+	if id == 404 {
+		return nil, errors.New("question not found")
+	}
+	return &models.Question{ID: id, Text: "What is Go?", Version: int(s.loads.Load())}, nil
+}
+
+func (s *countingQuestionService) DeleteQuestion(id, version int) error {
+	return nil
+}
+
+func TestCachedQuestionService_ReadThrough(t *testing.T) {
+	next := &countingQuestionService{}
+	svc := NewCachedQuestionService(next, cache.NewLRU[int, *models.Question](10, time.Minute))
+
+	first, err := svc.GetQuestionByID(1)
+	require.NoError(t, err)
+	second, err := svc.GetQuestionByID(1)
+	require.NoError(t, err)
+
+	assert.Same(t, first, second)
+	assert.Equal(t, int32(1), next.loads.Load())
+}
+
+func TestCachedQuestionService_DoesNotCacheErrors(t *testing.T) {
+	next := &countingQuestionService{}
+	svc := NewCachedQuestionService(next, cache.NewLRU[int, *models.Question](10, time.Minute))
+
+	_, err := svc.GetQuestionByID(404)
+	assert.EqualError(t, err, "question not found")
+	_, err = svc.GetQuestionByID(404)
+	assert.Error(t, err)
+	assert.Equal(t, int32(2), next.loads.Load())
+}
+
+func TestCachedQuestionService_CollapsesConcurrentMisses(t *testing.T) {
+	next := &countingQuestionService{release: make(chan struct{})}
+	svc := NewCachedQuestionService(next, cache.NewLRU[int, *models.Question](10, time.Minute))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			question, err := svc.GetQuestionByID(1)
+			assert.NoError(t, err)
+			assert.Equal(t, 1, question.ID)
+		}()
+	}
+
+	// Give the goroutines time to pile up behind the first load
+	time.Sleep(50 * time.Millisecond)
+	close(next.release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), next.loads.Load())
+}
+
+func TestCachedQuestionService_Invalidation(t *testing.T) {
+	next := &countingQuestionService{}
+	svc := NewCachedQuestionService(next, cache.NewLRU[int, *models.Question](10, time.Minute))
+
+	load := func() int {
+		question, err := svc.GetQuestionByID(1)
+		require.NoError(t, err)
+		return question.Version
+	}
+
+	assert.Equal(t, 1, load())
+	svc.Invalidate(events.Event{Type: events.QuestionCreated, QuestionID: 1})
+	assert.Equal(t, 1, load(), "creating a question does not change question 1")
+
+	svc.Invalidate(events.Event{Type: events.AnswerCreated, QuestionID: 1})
+	assert.Equal(t, 2, load())
+
+	svc.Invalidate(events.Event{Type: events.AnswerDeleted, QuestionID: 2})
+	assert.Equal(t, 2, load(), "other questions are untouched")
+
+	svc.Invalidate(events.Event{Type: events.QuestionUpdated, QuestionID: 1})
+	assert.Equal(t, 3, load(), "a recount changes the answer count")
+
+	require.NoError(t, svc.DeleteQuestion(1, 0))
+	assert.Equal(t, 4, load())
+
+	svc.Purge()
+	assert.Equal(t, 5, load())
+}
@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"qa-api/internal/dataset"
+	"qa-api/internal/models"
+	"qa-api/internal/validation"
+)
+
+const (
+	// importBatchSize is the number of questions inserted per transaction
+	importBatchSize = 500
+	// exportPageSize is the number of questions loaded per query
+	exportPageSize = 500
+	// maxImportErrors caps the line errors kept in a report
+	maxImportErrors = 1000
+)
+
+// ImportError describes why a line of an import was rejected
+type ImportError struct {
+	Line    int               `json:"line"`
+	Message string            `json:"message"`
+	Fields  validation.Errors `json:"fields,omitempty"`
+}
+
+// ImportReport summarizes an import. Questions and Answers count the rows
+// that were inserted, or that would be inserted on a dry run.
+type ImportReport struct {
+	DryRun          bool          `json:"dry_run"`
+	Questions       int           `json:"questions"`
+	Answers         int           `json:"answers"`
+	Rejected        int           `json:"rejected"`
+	Errors          []ImportError `json:"errors"`
+	ErrorsTruncated bool          `json:"errors_truncated,omitempty"`
+	Error           string        `json:"error,omitempty"`
+}
+
+func (r *ImportReport) reject(line int, message string, fields validation.Errors) {
+	r.Rejected++
+	if len(r.Errors) >= maxImportErrors {
+		r.ErrorsTruncated = true
+		return
+	}
+	r.Errors = append(r.Errors, ImportError{Line: line, Message: message, Fields: fields})
+}
+
+// DatasetService handles bulk import and export of questions with answers
+type DatasetService struct {
+	repo DatasetRepositoryInterface
+}
+
+// NewDatasetService creates a new DatasetService
+func NewDatasetService(repo DatasetRepositoryInterface) *DatasetService {
+	return &DatasetService{repo: repo}
+}
+
+// Import validates every record and inserts the valid ones in batches.
+// Invalid records are reported by line and skipped. When the input cannot be
+// read to the end, the records before the failure are still imported and
+// report.Error says why it stopped. A database error aborts the import with
+// the batches inserted before it committed.
+func (s *DatasetService) Import(ctx context.Context, reader dataset.Reader, dryRun bool) (*ImportReport, error) {
+	report := &ImportReport{DryRun: dryRun, Errors: []ImportError{}}
+	var batch []models.Question
+	answers := 0
+
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		if !dryRun {
+			if err := s.repo.CreateInBatches(batch, importBatchSize); err != nil {
+				return fmt.Errorf("failed to insert questions: %w", err)
+			}
+		}
+		report.Questions += len(batch)
+		report.Answers += answers
+		batch, answers = nil, 0
+		return nil
+	}
+	fail := func(err error) (*ImportReport, error) {
+		report.Error = err.Error()
+		return report, err
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return fail(err)
+		}
+
+		record, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			// The rest of the input is unreadable; keep what was valid so far
+			report.Error = err.Error()
+			break
+		}
+		if record.Err != nil {
+			report.reject(record.Line, record.Err.Error(), nil)
+			continue
+		}
+		if errs := record.Question.Validate(); errs != nil {
+			report.reject(record.Line, "validation failed", errs)
+			continue
+		}
+
+		batch = append(batch, record.Question.Model())
+		answers += len(record.Question.Answers)
+		if len(batch) >= importBatchSize {
+			if err := flush(); err != nil {
+				return fail(err)
+			}
+		}
+	}
+
+	if err := flush(); err != nil {
+		return fail(err)
+	}
+	return report, nil
+}
+
+// Export writes every question with its answers, a page at a time, and
+// flushes the writer after each page
+func (s *DatasetService) Export(ctx context.Context, writer dataset.Writer) error {
+	afterID := 0
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		questions, err := s.repo.GetPageWithAnswers(afterID, exportPageSize)
+		if err != nil {
+			return fmt.Errorf("failed to load questions: %w", err)
+		}
+		for _, question := range questions {
+			if err := writer.Write(dataset.FromModel(question)); err != nil {
+				return err
+			}
+		}
+		if err := writer.Flush(); err != nil {
+			return err
+		}
+		if len(questions) < exportPageSize {
+			return nil
+		}
+		afterID = questions[len(questions)-1].ID
+	}
+}
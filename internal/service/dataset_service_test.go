@@ -0,0 +1,156 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"qa-api/internal/dataset"
+	"qa-api/internal/models"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockDatasetRepository is a mock implementation of DatasetRepositoryInterface
+type MockDatasetRepository struct {
+	mock.Mock
+}
+
+func (m *MockDatasetRepository) CreateInBatches(questions []models.Question, batchSize int) error {
+	args := m.Called(questions, batchSize)
+	return args.Error(0)
+}
+
+func (m *MockDatasetRepository) GetPageWithAnswers(afterID, limit int) ([]models.Question, error) {
+	args := m.Called(afterID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Question), args.Error(1)
+}
+
+func ndjsonReader(t *testing.T, input string) dataset.Reader {
+	reader, err := dataset.NewReader(dataset.FormatNDJSON, strings.NewReader(input))
+	require.NoError(t, err)
+	return reader
+}
+
+func TestDatasetService_Import(t *testing.T) {
+	input := `{"text":"What is Go?","answers":[{"user_id":"alice","text":"A language"}]}
+{"text":""}
+not json
+{"text":"Why?","answers":[{"user_id":"bad id","text":"Because"}]}
+{"text":"Unanswered"}
+`
+
+	t.Run("inserts valid questions and reports the rest", func(t *testing.T) {
+		mockRepo := new(MockDatasetRepository)
+		service := NewDatasetService(mockRepo)
+
+		mockRepo.On("CreateInBatches", mock.MatchedBy(func(questions []models.Question) bool {
+			return len(questions) == 2 && questions[0].Text == "What is Go?" &&
+				len(questions[0].Answers) == 1 && questions[1].Text == "Unanswered"
+		}), importBatchSize).Return(nil)
+
+		report, err := service.Import(context.Background(), ndjsonReader(t, input), false)
+
+		require.NoError(t, err)
+		assert.Equal(t, 2, report.Questions)
+		assert.Equal(t, 1, report.Answers)
+		assert.Equal(t, 3, report.Rejected)
+		require.Len(t, report.Errors, 3)
+		assert.Equal(t, 2, report.Errors[0].Line)
+		assert.Equal(t, "text", report.Errors[0].Fields[0].Field)
+		assert.Equal(t, 3, report.Errors[1].Line)
+		assert.Equal(t, 4, report.Errors[2].Line)
+		assert.Equal(t, "answers[0].user_id", report.Errors[2].Fields[0].Field)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("dry run", func(t *testing.T) {
+		mockRepo := new(MockDatasetRepository)
+		service := NewDatasetService(mockRepo)
+
+		report, err := service.Import(context.Background(), ndjsonReader(t, input), true)
+
+		require.NoError(t, err)
+		assert.True(t, report.DryRun)
+		assert.Equal(t, 2, report.Questions)
+		assert.Equal(t, 3, report.Rejected)
+		mockRepo.AssertNotCalled(t, "CreateInBatches", mock.Anything, mock.Anything)
+	})
+
+	t.Run("batches", func(t *testing.T) {
+		mockRepo := new(MockDatasetRepository)
+		service := NewDatasetService(mockRepo)
+
+		var lines strings.Builder
+		for i := 0; i < importBatchSize+1; i++ {
+			fmt.Fprintf(&lines, "{\"text\":\"Question %d\"}\n", i)
+		}
+		mockRepo.On("CreateInBatches", mock.MatchedBy(func(q []models.Question) bool { return len(q) == importBatchSize }), importBatchSize).Return(nil).Once()
+		mockRepo.On("CreateInBatches", mock.MatchedBy(func(q []models.Question) bool { return len(q) == 1 }), importBatchSize).Return(nil).Once()
+
+		report, err := service.Import(context.Background(), ndjsonReader(t, lines.String()), false)
+
+		require.NoError(t, err)
+		assert.Equal(t, importBatchSize+1, report.Questions)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("database error aborts", func(t *testing.T) {
+		mockRepo := new(MockDatasetRepository)
+		service := NewDatasetService(mockRepo)
+
+		mockRepo.On("CreateInBatches", mock.Anything, importBatchSize).Return(errors.New("connection lost"))
+
+		report, err := service.Import(context.Background(), ndjsonReader(t, input), false)
+
+		assert.EqualError(t, err, "failed to insert questions: connection lost")
+		assert.Equal(t, 0, report.Questions)
+		assert.Equal(t, err.Error(), report.Error)
+	})
+
+	t.Run("unreadable input keeps what came before", func(t *testing.T) {
+		mockRepo := new(MockDatasetRepository)
+		service := NewDatasetService(mockRepo)
+
+		long := `{"text":"ok"}` + "\n" + strings.Repeat("x", 2<<20) + "\n"
+		mockRepo.On("CreateInBatches", mock.Anything, importBatchSize).Return(nil)
+
+		report, err := service.Import(context.Background(), ndjsonReader(t, long), false)
+
+		require.NoError(t, err)
+		assert.Equal(t, 1, report.Questions)
+		assert.Contains(t, report.Error, "line 2 exceeds")
+	})
+}
+
+func TestDatasetService_Export(t *testing.T) {
+	mockRepo := new(MockDatasetRepository)
+	service := NewDatasetService(mockRepo)
+
+	page := make([]models.Question, exportPageSize)
+	for i := range page {
+		page[i] = models.Question{ID: i + 1, Text: "Q"}
+	}
+	page[0].Answers = []models.Answer{{ID: 1, QuestionID: 1, UserID: "alice", Text: "A"}}
+	mockRepo.On("GetPageWithAnswers", 0, exportPageSize).Return(page, nil)
+	mockRepo.On("GetPageWithAnswers", exportPageSize, exportPageSize).Return([]models.Question{{ID: exportPageSize + 1, Text: "Last"}}, nil)
+
+	var buf bytes.Buffer
+	writer, err := dataset.NewWriter(dataset.FormatNDJSON, &buf)
+	require.NoError(t, err)
+
+	require.NoError(t, service.Export(context.Background(), writer))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Len(t, lines, exportPageSize+1)
+	assert.Contains(t, lines[0], `"answers":[{"id":1,"user_id":"alice","text":"A"`)
+	assert.Contains(t, lines[exportPageSize], `"text":"Last"`)
+	mockRepo.AssertExpectations(t)
+}
@@ -1,20 +1,149 @@
 package service
 
-import "qa-api/internal/models"
+import (
+	"context"
+	"qa-api/internal/badges"
+	"qa-api/internal/dataset"
+	"qa-api/internal/models"
+	"time"
+)
 
-// QuestionServiceInterface defines the interface for question service
+// QuestionServiceInterface defines the interface for question service.
+// Delete methods take the expected version for optimistic locking; 0 skips the check.
 type QuestionServiceInterface interface {
-	CreateQuestion(text string) (*models.Question, error)
+	CreateQuestion(userID, text string) (*models.Question, error)
 	GetAllQuestions() ([]models.Question, error)
+	GetAllQuestionsWithAnswers() ([]models.Question, error)
 	GetQuestionByID(id int) (*models.Question, error)
-	DeleteQuestion(id int) error
+	DeleteQuestion(id, version int) error
+	RecordView(id int, viewer string) error
 }
 
 // AnswerServiceInterface defines the interface for answer service
 type AnswerServiceInterface interface {
 	CreateAnswer(questionID int, userID, text string) (*models.Answer, error)
 	GetAnswerByID(id int) (*models.Answer, error)
-	DeleteAnswer(id int) error
+	DeleteAnswer(id, version int) error
+}
+
+// UserServiceInterface defines the interface for user profiles and reputation
+type UserServiceInterface interface {
+	GetProfile(id string) (*Profile, error)
+	UpdateProfile(user *models.User) error
+	Leaderboard(limit int) ([]models.Standing, error)
+}
+
+// VoteServiceInterface defines the interface for voting on and accepting answers
+type VoteServiceInterface interface {
+	Vote(answerID int, userID string, value int) error
+	Retract(answerID int, userID string) error
+	Accept(answerID int, userID string) error
+}
+
+// BadgeServiceInterface defines the interface for badges
+type BadgeServiceInterface interface {
+	GetBadges() []badges.Rule
+	GetUserBadges(userID string) ([]models.BadgeAward, error)
+}
+
+// NotificationServiceInterface defines the interface for notification inboxes,
+// their preferences and question follows
+type NotificationServiceInterface interface {
+	GetInbox(userID string, beforeID int64, limit int) (*Inbox, error)
+	MarkRead(userID string, id int64) error
+	MarkAllRead(userID string) error
+	GetPreferences(userID string) (map[string]bool, error)
+	UpdatePreferences(userID string, preferences map[string]bool) (map[string]bool, error)
+	Follow(questionID int, userID string) error
+	Unfollow(questionID int, userID string) error
+}
+
+// WebhookServiceInterface defines the interface for webhook service
+type WebhookServiceInterface interface {
+	CreateWebhook(url string, eventTypes []string, questionID *int, secret string) (*models.Webhook, error)
+	GetDeliveries(webhookID int) ([]models.WebhookDelivery, error)
+	DeleteWebhook(id int) error
+}
+
+// DatasetServiceInterface defines the interface for bulk import and export
+type DatasetServiceInterface interface {
+	Import(ctx context.Context, reader dataset.Reader, dryRun bool) (*ImportReport, error)
+	Export(ctx context.Context, writer dataset.Writer) error
+}
+
+// QuestionRepositoryInterface defines the interface for question repository
+type QuestionRepositoryInterface interface {
+	Create(question *models.Question) error
+	GetAll() ([]models.Question, error)
+	GetAllWithAnswers() ([]models.Question, error)
+	GetByID(id int) (*models.Question, error)
+	Delete(id, version int) error
+	Restore(id int) (*models.Question, error)
+	RecountAnswers() (int64, error)
+	Purge(before time.Time) (questions, answers int64, err error)
+	Exists(id int) (bool, error)
+	RecordViews(views map[int]int, milestones []int) error
+}
+
+// AnswerRepositoryInterface defines the interface for answer repository
+type AnswerRepositoryInterface interface {
+	Create(answer *models.Answer) error
+	GetByID(id int) (*models.Answer, error)
+	Delete(id, version int) error
+	Restore(id int) (*models.Answer, error)
+}
+
+// DatasetRepositoryInterface defines the storage used by bulk import and export
+type DatasetRepositoryInterface interface {
+	CreateInBatches(questions []models.Question, batchSize int) error
+	GetPageWithAnswers(afterID, limit int) ([]models.Question, error)
+}
+
+// UserRepositoryInterface defines the interface for user repository
+type UserRepositoryInterface interface {
+	GetByID(id string) (*models.User, error)
+	Save(user *models.User) error
+	GetQuestions(userID string) ([]models.Question, error)
+	GetAnswers(userID string) ([]models.Answer, error)
+}
+
+// VoteRepositoryInterface defines the interface for vote repository.
+// Accepting an answer is the asker's vote for it and lives here too.
+type VoteRepositoryInterface interface {
+	Cast(answerID int, userID string, value int) error
+	Accept(answerID int) error
+}
+
+// ReputationRepositoryInterface defines the reads of the reputation ledger
+type ReputationRepositoryInterface interface {
+	Total(userID string) (int, error)
+	Leaderboard(limit int) ([]models.Standing, error)
+}
+
+// BadgeRepositoryInterface defines the reads of badge awards
+type BadgeRepositoryInterface interface {
+	Awards(userID string) ([]models.BadgeAward, error)
+}
+
+// NotificationRepositoryInterface defines the interface for notification
+// repository
+type NotificationRepositoryInterface interface {
+	List(userID string, beforeID int64, limit int) ([]models.Notification, error)
+	UnreadCount(userID string) (int64, error)
+	MarkRead(userID string, id int64) error
+	MarkAllRead(userID string) (int64, error)
+	GetPreferences(userID string) (map[string]bool, error)
+	SavePreferences(userID string, preferences map[string]bool) error
+	Follow(questionID int, userID string) error
+	Unfollow(questionID int, userID string) error
+}
+
+// WebhookRepositoryInterface defines the interface for webhook repository
+type WebhookRepositoryInterface interface {
+	Create(webhook *models.Webhook) error
+	GetByID(id int) (*models.Webhook, error)
+	Delete(id int) error
+	GetDeliveries(webhookID int) ([]models.WebhookDelivery, error)
 }
 
 
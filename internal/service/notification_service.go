@@ -0,0 +1,141 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"qa-api/internal/models"
+	"qa-api/internal/notify"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// MaxNotificationPage limits the number of notifications returned at once
+const MaxNotificationPage = 100
+
+// Inbox is a page of the notifications of a user, newest first, with the
+// number of all their unread ones
+type Inbox struct {
+	Notifications []models.Notification
+	UnreadCount   int64
+}
+
+// NotificationService handles business logic for notification inboxes.
+// Creating notifications is up to the notify.Notifier, which follows the
+// domain events.
+type NotificationService struct {
+	notificationRepo NotificationRepositoryInterface
+}
+
+// NewNotificationService creates a new NotificationService
+func NewNotificationService(notificationRepo NotificationRepositoryInterface) *NotificationService {
+	return &NotificationService{notificationRepo: notificationRepo}
+}
+
+// GetInbox retrieves up to limit notifications of a user older than
+// beforeID, or the newest ones when beforeID is 0
+func (s *NotificationService) GetInbox(userID string, beforeID int64, limit int) (*Inbox, error) {
+	if strings.TrimSpace(userID) == "" {
+		return nil, errors.New("user_id cannot be empty")
+	}
+	if limit < 1 || limit > MaxNotificationPage {
+		return nil, fmt.Errorf("limit must be between 1 and %d", MaxNotificationPage)
+	}
+
+	inbox := &Inbox{}
+	var err error
+	if inbox.Notifications, err = s.notificationRepo.List(userID, beforeID, limit); err != nil {
+		return nil, err
+	}
+	if inbox.UnreadCount, err = s.notificationRepo.UnreadCount(userID); err != nil {
+		return nil, err
+	}
+	return inbox, nil
+}
+
+// MarkRead marks a notification of a user as read
+func (s *NotificationService) MarkRead(userID string, id int64) error {
+	if strings.TrimSpace(userID) == "" {
+		return errors.New("user_id cannot be empty")
+	}
+	err := s.notificationRepo.MarkRead(userID, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return errors.New("notification not found")
+	}
+	return err
+}
+
+// MarkAllRead marks every notification of a user as read
+func (s *NotificationService) MarkAllRead(userID string) error {
+	if strings.TrimSpace(userID) == "" {
+		return errors.New("user_id cannot be empty")
+	}
+	_, err := s.notificationRepo.MarkAllRead(userID)
+	return err
+}
+
+// GetPreferences returns whether each notification kind is on for a user;
+// kinds they never changed are on
+func (s *NotificationService) GetPreferences(userID string) (map[string]bool, error) {
+	if strings.TrimSpace(userID) == "" {
+		return nil, errors.New("user_id cannot be empty")
+	}
+	saved, err := s.notificationRepo.GetPreferences(userID)
+	if err != nil {
+		return nil, err
+	}
+	preferences := make(map[string]bool, len(notify.Kinds))
+	for _, kind := range notify.Kinds {
+		enabled, ok := saved[kind]
+		preferences[kind] = !ok || enabled
+	}
+	return preferences, nil
+}
+
+// UpdatePreferences turns the given kinds on or off for a user, leaving
+// the others as they are, and returns all of them
+func (s *NotificationService) UpdatePreferences(userID string, preferences map[string]bool) (map[string]bool, error) {
+	userID = strings.TrimSpace(userID)
+	if userID == "" {
+		return nil, errors.New("user_id cannot be empty")
+	}
+	for kind := range preferences {
+		if !knownKind(kind) {
+			return nil, fmt.Errorf("unknown notification kind %q", kind)
+		}
+	}
+	if err := s.notificationRepo.SavePreferences(userID, preferences); err != nil {
+		return nil, err
+	}
+	return s.GetPreferences(userID)
+}
+
+// Follow makes a user hear about new answers to a question
+func (s *NotificationService) Follow(questionID int, userID string) error {
+	userID = strings.TrimSpace(userID)
+	if userID == "" {
+		return errors.New("user_id cannot be empty")
+	}
+	err := s.notificationRepo.Follow(questionID, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return errors.New("question not found")
+	}
+	return err
+}
+
+// Unfollow stops a user hearing about new answers to a question
+func (s *NotificationService) Unfollow(questionID int, userID string) error {
+	if strings.TrimSpace(userID) == "" {
+		return errors.New("user_id cannot be empty")
+	}
+	return s.notificationRepo.Unfollow(questionID, userID)
+}
+
+func knownKind(kind string) bool {
+	for _, known := range notify.Kinds {
+		if kind == known {
+			return true
+		}
+	}
+	return false
+}
@@ -1,33 +1,83 @@
 package service
 
 import (
+	"context"
 	"errors"
+	"log"
+	"qa-api/internal/cache"
 	"qa-api/internal/models"
-	"qa-api/internal/repository"
+	"qa-api/internal/validation"
 	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Repeated views of a question by the same user within viewWindow count
+// once. Up to maxViewers recent views are remembered, per instance, so a
+// user whose requests reach several instances counts once on each.
+const (
+	viewWindow = time.Hour
+	maxViewers = 100000
 )
 
+// view identifies a viewer of a question
+type view struct {
+	questionID int
+	viewer     string
+}
+
 // QuestionService handles business logic for questions
 type QuestionService struct {
-	questionRepo *repository.QuestionRepository
+	questionRepo   QuestionRepositoryInterface
+	views          *cache.LRU[view, bool]
+	viewMilestones []int
+
+	// pendingViews counts the views not written yet, by question ID
+	viewsMu      sync.Mutex
+	pendingViews map[int]int
 }
 
 // NewQuestionService creates a new QuestionService
-func NewQuestionService(questionRepo *repository.QuestionRepository) *QuestionService {
+func NewQuestionService(questionRepo QuestionRepositoryInterface) *QuestionService {
 	return &QuestionService{
 		questionRepo: questionRepo,
+		views:        cache.NewLRU[view, bool](maxViewers, viewWindow),
+		pendingViews: make(map[int]int),
 	}
 }
 
-// CreateQuestion creates a new question
-func (s *QuestionService) CreateQuestion(text string) (*models.Question, error) {
+// SetViewMilestones sets the view counts that record a QuestionViewed
+// event, typically the thresholds of the badges for views
+func (s *QuestionService) SetViewMilestones(milestones []int) {
+	s.viewMilestones = milestones
+}
+
+// newQuestion holds the fields of a question to create. Every transport
+// creates questions through the service, so the rules are checked here.
+type newQuestion struct {
+	UserID string `json:"user_id" validate:"userid"`
+	Text   string `json:"text" validate:"required,max=2000,nocontrol"`
+}
+
+// CreateQuestion creates a new question; userID is its author and may be
+// empty for anonymous questions. Invalid fields are reported as
+// validation.Errors.
+func (s *QuestionService) CreateQuestion(userID, text string) (*models.Question, error) {
 	text = strings.TrimSpace(text)
 	if text == "" {
 		return nil, errors.New("question text cannot be empty")
 	}
 
+	input := newQuestion{UserID: strings.TrimSpace(userID), Text: text}
+	if errs := validation.Struct(&input); errs != nil {
+		return nil, errs
+	}
+
 	question := &models.Question{
-		Text: text,
+		UserID: input.UserID,
+		Text:   input.Text,
 	}
 
 	if err := s.questionRepo.Create(question); err != nil {
@@ -42,6 +92,11 @@ func (s *QuestionService) GetAllQuestions() ([]models.Question, error) {
 	return s.questionRepo.GetAll()
 }
 
+// GetAllQuestionsWithAnswers retrieves all questions with their answers
+func (s *QuestionService) GetAllQuestionsWithAnswers() ([]models.Question, error) {
+	return s.questionRepo.GetAllWithAnswers()
+}
+
 // GetQuestionByID retrieves a question by ID with its answers
 func (s *QuestionService) GetQuestionByID(id int) (*models.Question, error) {
 	question, err := s.questionRepo.GetByID(id)
@@ -51,8 +106,8 @@ func (s *QuestionService) GetQuestionByID(id int) (*models.Question, error) {
 	return question, nil
 }
 
-// DeleteQuestion deletes a question by ID
-func (s *QuestionService) DeleteQuestion(id int) error {
+// DeleteQuestion deletes a question by ID if its version matches (0 matches any)
+func (s *QuestionService) DeleteQuestion(id, version int) error {
 	exists, err := s.questionRepo.Exists(id)
 	if err != nil {
 		return err
@@ -61,7 +116,103 @@ func (s *QuestionService) DeleteQuestion(id int) error {
 		return errors.New("question not found")
 	}
 
-	return s.questionRepo.Delete(id)
+	// A concurrent delete may win the row lock after the check above
+	err = s.questionRepo.Delete(id, version)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return errors.New("question not found")
+	}
+	return err
+}
+
+// RecordView counts a view of a question by viewer, an authenticated user.
+// Anonymous views (empty viewer) are not counted, since clients behind one
+// address cannot be told apart, and repeated views by the same user within
+// the window are ignored. Views are buffered and written by FlushViews.
+func (s *QuestionService) RecordView(id int, viewer string) error {
+	if viewer == "" {
+		return nil
+	}
+
+	s.viewsMu.Lock()
+	defer s.viewsMu.Unlock()
+	key := view{questionID: id, viewer: viewer}
+	if _, seen := s.views.Get(key); seen {
+		return nil
+	}
+	s.views.Set(key, true)
+	s.pendingViews[id]++
+	return nil
+}
+
+// FlushViews writes the buffered views in one batch. Views that fail to be
+// written stay buffered for the next flush.
+func (s *QuestionService) FlushViews() error {
+	s.viewsMu.Lock()
+	views := s.pendingViews
+	s.pendingViews = make(map[int]int)
+	s.viewsMu.Unlock()
+	if len(views) == 0 {
+		return nil
+	}
+
+	err := s.questionRepo.RecordViews(views, s.viewMilestones)
+	if err != nil {
+		s.viewsMu.Lock()
+		for id, count := range views {
+			s.pendingViews[id] += count
+		}
+		s.viewsMu.Unlock()
+	}
+	return err
+}
+
+// FlushViewsEvery flushes the buffered views every interval until ctx is
+// cancelled, and once more before it returns
+func (s *QuestionService) FlushViewsEvery(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			if err := s.FlushViews(); err != nil {
+				log.Printf("Failed to write question views: %v", err)
+			}
+			return
+		case <-ticker.C:
+			if err := s.FlushViews(); err != nil {
+				log.Printf("Failed to write question views: %v", err)
+			}
+		}
+	}
+}
+
+// RestoreQuestion undoes the deletion of a question and its answers
+func (s *QuestionService) RestoreQuestion(id int) (*models.Question, error) {
+	question, err := s.questionRepo.Restore(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.New("question not found")
+	}
+	if err != nil && err.Error() == "not deleted" {
+		return nil, errors.New("question is not deleted")
+	}
+	return question, err
+}
+
+// RecountAnswers rebuilds the denormalized answer counts of all questions
+// and returns how many were wrong
+func (s *QuestionService) RecountAnswers() (int64, error) {
+	return s.questionRepo.RecountAnswers()
+}
+
+// PurgeDeleted permanently removes the questions and answers deleted more
+// than olderThan ago and returns how many of each were removed. Purged
+// content can no longer be restored.
+func (s *QuestionService) PurgeDeleted(olderThan time.Duration) (questions, answers int64, err error) {
+	if olderThan <= 0 {
+		return 0, 0, errors.New("age must be positive")
+	}
+	return s.questionRepo.Purge(time.Now().Add(-olderThan))
 }
 
 
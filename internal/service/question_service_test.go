@@ -3,12 +3,15 @@ package service
 import (
 	"errors"
 	"qa-api/internal/models"
-	"qa-api/internal/repository"
+	"qa-api/internal/validation"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
 )
 
 // MockQuestionRepository is a mock implementation of QuestionRepository
@@ -29,6 +32,14 @@ func (m *MockQuestionRepository) GetAll() ([]models.Question, error) {
 	return args.Get(0).([]models.Question), args.Error(1)
 }
 
+func (m *MockQuestionRepository) GetAllWithAnswers() ([]models.Question, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Question), args.Error(1)
+}
+
 func (m *MockQuestionRepository) GetByID(id int) (*models.Question, error) {
 	args := m.Called(id)
 	if args.Get(0) == nil {
@@ -37,42 +48,61 @@ func (m *MockQuestionRepository) GetByID(id int) (*models.Question, error) {
 	return args.Get(0).(*models.Question), args.Error(1)
 }
 
-func (m *MockQuestionRepository) Delete(id int) error {
-	args := m.Called(id)
+func (m *MockQuestionRepository) Delete(id, version int) error {
+	args := m.Called(id, version)
 	return args.Error(0)
 }
 
+func (m *MockQuestionRepository) Restore(id int) (*models.Question, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Question), args.Error(1)
+}
+
+func (m *MockQuestionRepository) RecountAnswers() (int64, error) {
+	args := m.Called()
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockQuestionRepository) Purge(before time.Time) (int64, int64, error) {
+	args := m.Called(before)
+	return args.Get(0).(int64), args.Get(1).(int64), args.Error(2)
+}
+
 func (m *MockQuestionRepository) Exists(id int) (bool, error) {
 	args := m.Called(id)
 	return args.Bool(0), args.Error(1)
 }
 
+func (m *MockQuestionRepository) RecordViews(views map[int]int, milestones []int) error {
+	args := m.Called(views, milestones)
+	return args.Error(0)
+}
+
 func TestQuestionService_CreateQuestion(t *testing.T) {
 	mockRepo := new(MockQuestionRepository)
 	service := NewQuestionService(mockRepo)
 
 	t.Run("successful creation", func(t *testing.T) {
-		question := &models.Question{
-			ID:        1,
-			Text:      "Test question",
-			CreatedAt: time.Now(),
-		}
 		mockRepo.On("Create", mock.AnythingOfType("*models.Question")).Return(nil).Run(func(args mock.Arguments) {
 			q := args.Get(0).(*models.Question)
 			q.ID = 1
 			q.CreatedAt = time.Now()
 		})
 
-		result, err := service.CreateQuestion("Test question")
+		result, err := service.CreateQuestion(" alice ", "Test question")
 
 		assert.NoError(t, err)
 		assert.NotNil(t, result)
 		assert.Equal(t, "Test question", result.Text)
+		assert.Equal(t, "alice", result.UserID)
 		mockRepo.AssertExpectations(t)
 	})
 
 	t.Run("empty text", func(t *testing.T) {
-		result, err := service.CreateQuestion("")
+		result, err := service.CreateQuestion("", "")
 
 		assert.Error(t, err)
 		assert.Nil(t, result)
@@ -80,11 +110,23 @@ func TestQuestionService_CreateQuestion(t *testing.T) {
 	})
 
 	t.Run("whitespace only", func(t *testing.T) {
-		result, err := service.CreateQuestion("   ")
+		result, err := service.CreateQuestion("", "   ")
 
 		assert.Error(t, err)
 		assert.Nil(t, result)
 	})
+
+	t.Run("invalid fields", func(t *testing.T) {
+		_, err := service.CreateQuestion("bad user", "Test\x00question")
+
+		var invalid validation.Errors
+		require.ErrorAs(t, err, &invalid)
+		assert.Equal(t, []string{"user_id", "text"}, []string{invalid[0].Field, invalid[1].Field})
+
+		_, err = service.CreateQuestion("", strings.Repeat("a", 2001))
+		require.ErrorAs(t, err, &invalid)
+		assert.Equal(t, "too_long", invalid[0].Code)
+	})
 }
 
 func TestQuestionService_DeleteQuestion(t *testing.T) {
@@ -93,9 +135,9 @@ func TestQuestionService_DeleteQuestion(t *testing.T) {
 
 	t.Run("successful deletion", func(t *testing.T) {
 		mockRepo.On("Exists", 1).Return(true, nil)
-		mockRepo.On("Delete", 1).Return(nil)
+		mockRepo.On("Delete", 1, 0).Return(nil)
 
-		err := service.DeleteQuestion(1)
+		err := service.DeleteQuestion(1, 0)
 
 		assert.NoError(t, err)
 		mockRepo.AssertExpectations(t)
@@ -104,14 +146,81 @@ func TestQuestionService_DeleteQuestion(t *testing.T) {
 	t.Run("question not found", func(t *testing.T) {
 		mockRepo.On("Exists", 999).Return(false, nil)
 
-		err := service.DeleteQuestion(999)
+		err := service.DeleteQuestion(999, 0)
+
+		assert.Error(t, err)
+		assert.Equal(t, "question not found", err.Error())
+	})
+
+	t.Run("deleted concurrently", func(t *testing.T) {
+		mockRepo.On("Exists", 2).Return(true, nil)
+		mockRepo.On("Delete", 2, 0).Return(gorm.ErrRecordNotFound)
+
+		err := service.DeleteQuestion(2, 0)
 
 		assert.Error(t, err)
 		assert.Equal(t, "question not found", err.Error())
 	})
 }
 
+func TestQuestionService_RestoreQuestion(t *testing.T) {
+	mockRepo := new(MockQuestionRepository)
+	service := NewQuestionService(mockRepo)
+
+	t.Run("successful restore", func(t *testing.T) {
+		mockRepo.On("Restore", 1).Return(&models.Question{ID: 1, Text: "Back"}, nil)
+
+		result, err := service.RestoreQuestion(1)
 
+		assert.NoError(t, err)
+		assert.Equal(t, "Back", result.Text)
+	})
+
+	t.Run("question not found", func(t *testing.T) {
+		mockRepo.On("Restore", 2).Return(nil, gorm.ErrRecordNotFound)
+
+		_, err := service.RestoreQuestion(2)
+
+		assert.EqualError(t, err, "question not found")
+	})
 
+	t.Run("question not deleted", func(t *testing.T) {
+		mockRepo.On("Restore", 3).Return(nil, errors.New("not deleted"))
 
+		_, err := service.RestoreQuestion(3)
 
+		assert.EqualError(t, err, "question is not deleted")
+	})
+}
+
+func TestQuestionService_RecordView(t *testing.T) {
+	mockRepo := new(MockQuestionRepository)
+	service := NewQuestionService(mockRepo)
+	service.SetViewMilestones([]int{100})
+
+	t.Run("repeated and anonymous views are not counted", func(t *testing.T) {
+		mockRepo.On("RecordViews", map[int]int{1: 2, 2: 1}, []int{100}).Return(nil).Once()
+
+		assert.NoError(t, service.RecordView(1, "alice"))
+		assert.NoError(t, service.RecordView(1, "alice"))
+		assert.NoError(t, service.RecordView(1, "bob"))
+		assert.NoError(t, service.RecordView(1, ""))
+		assert.NoError(t, service.RecordView(2, "alice"))
+		mockRepo.AssertNotCalled(t, "RecordViews", mock.Anything, mock.Anything)
+
+		require.NoError(t, service.FlushViews())
+		require.NoError(t, service.FlushViews(), "nothing is left to write")
+		mockRepo.AssertNumberOfCalls(t, "RecordViews", 1)
+	})
+
+	t.Run("failed views are kept for the next flush", func(t *testing.T) {
+		mockRepo.On("RecordViews", map[int]int{3: 1}, []int{100}).Return(errors.New("connection refused")).Once()
+		mockRepo.On("RecordViews", map[int]int{3: 2}, []int{100}).Return(nil).Once()
+
+		assert.NoError(t, service.RecordView(3, "alice"))
+		assert.Error(t, service.FlushViews())
+		assert.NoError(t, service.RecordView(3, "bob"))
+		assert.NoError(t, service.FlushViews())
+		mockRepo.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"qa-api/internal/models"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// MaxLeaderboardSize limits the number of users on the leaderboard
+const MaxLeaderboardSize = 100
+
+// Profile is a user with their contributions and reputation
+type Profile struct {
+	User       models.User
+	Reputation int
+	Questions  []models.Question
+	Answers    []models.Answer
+}
+
+// UserService handles business logic for user profiles and reputation
+type UserService struct {
+	userRepo       UserRepositoryInterface
+	reputationRepo ReputationRepositoryInterface
+}
+
+// NewUserService creates a new UserService
+func NewUserService(userRepo UserRepositoryInterface, reputationRepo ReputationRepositoryInterface) *UserService {
+	return &UserService{
+		userRepo:       userRepo,
+		reputationRepo: reputationRepo,
+	}
+}
+
+// GetProfile retrieves a user with their questions, answers and reputation
+func (s *UserService) GetProfile(id string) (*Profile, error) {
+	user, err := s.userRepo.GetByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.New("user not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	profile := &Profile{User: *user}
+	if profile.Questions, err = s.userRepo.GetQuestions(id); err != nil {
+		return nil, err
+	}
+	if profile.Answers, err = s.userRepo.GetAnswers(id); err != nil {
+		return nil, err
+	}
+	if profile.Reputation, err = s.reputationRepo.Total(id); err != nil {
+		return nil, err
+	}
+	return profile, nil
+}
+
+// UpdateProfile sets the profile fields of a user, creating the user if
+// they have not contributed yet
+func (s *UserService) UpdateProfile(user *models.User) error {
+	user.ID = strings.TrimSpace(user.ID)
+	if user.ID == "" {
+		return errors.New("user_id cannot be empty")
+	}
+	user.DisplayName = strings.TrimSpace(user.DisplayName)
+	user.AvatarURL = strings.TrimSpace(user.AvatarURL)
+	user.Bio = strings.TrimSpace(user.Bio)
+	return s.userRepo.Save(user)
+}
+
+// Leaderboard returns the limit users with the highest reputation
+func (s *UserService) Leaderboard(limit int) ([]models.Standing, error) {
+	if limit < 1 || limit > MaxLeaderboardSize {
+		return nil, fmt.Errorf("limit must be between 1 and %d", MaxLeaderboardSize)
+	}
+	return s.reputationRepo.Leaderboard(limit)
+}
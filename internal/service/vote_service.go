@@ -0,0 +1,82 @@
+package service
+
+import (
+	"errors"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// VoteService handles business logic for votes and accepted answers
+type VoteService struct {
+	voteRepo   VoteRepositoryInterface
+	answerRepo AnswerRepositoryInterface
+}
+
+// NewVoteService creates a new VoteService
+func NewVoteService(voteRepo VoteRepositoryInterface, answerRepo AnswerRepositoryInterface) *VoteService {
+	return &VoteService{
+		voteRepo:   voteRepo,
+		answerRepo: answerRepo,
+	}
+}
+
+// Vote casts an up (1) or down (-1) vote of a user on an answer, replacing
+// their earlier vote
+func (s *VoteService) Vote(answerID int, userID string, value int) error {
+	if value != 1 && value != -1 {
+		return errors.New("vote must be 1 or -1")
+	}
+	return s.cast(answerID, userID, value)
+}
+
+// Retract removes the vote of a user on an answer, if any
+func (s *VoteService) Retract(answerID int, userID string) error {
+	return s.cast(answerID, userID, 0)
+}
+
+func (s *VoteService) cast(answerID int, userID string, value int) error {
+	userID = strings.TrimSpace(userID)
+	if userID == "" {
+		return errors.New("user_id cannot be empty")
+	}
+
+	answer, err := s.answerRepo.GetByID(answerID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return errors.New("answer not found")
+	}
+	if err != nil {
+		return err
+	}
+	if answer.UserID == userID {
+		return errors.New("cannot vote on your own answer")
+	}
+
+	// A concurrent delete may remove the answer after the lookup above
+	err = s.voteRepo.Cast(answerID, userID, value)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return errors.New("answer not found")
+	}
+	return err
+}
+
+// Accept marks an answer as the accepted one of its question; only the
+// author of the question may do so
+func (s *VoteService) Accept(answerID int, userID string) error {
+	answer, err := s.answerRepo.GetByID(answerID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return errors.New("answer not found")
+	}
+	if err != nil {
+		return err
+	}
+	if answer.Question.UserID == "" || answer.Question.UserID != strings.TrimSpace(userID) {
+		return errors.New("only the author of the question can accept an answer")
+	}
+
+	err = s.voteRepo.Accept(answerID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return errors.New("answer not found")
+	}
+	return err
+}
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"qa-api/internal/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+// MockAnswerRepository is a mock implementation of AnswerRepository
+type MockAnswerRepository struct {
+	mock.Mock
+}
+
+func (m *MockAnswerRepository) Create(answer *models.Answer) error {
+	args := m.Called(answer)
+	return args.Error(0)
+}
+
+func (m *MockAnswerRepository) GetByID(id int) (*models.Answer, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Answer), args.Error(1)
+}
+
+func (m *MockAnswerRepository) Delete(id, version int) error {
+	args := m.Called(id, version)
+	return args.Error(0)
+}
+
+func (m *MockAnswerRepository) Restore(id int) (*models.Answer, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Answer), args.Error(1)
+}
+
+// MockVoteRepository is a mock implementation of VoteRepository
+type MockVoteRepository struct {
+	mock.Mock
+}
+
+func (m *MockVoteRepository) Cast(answerID int, userID string, value int) error {
+	args := m.Called(answerID, userID, value)
+	return args.Error(0)
+}
+
+func (m *MockVoteRepository) Accept(answerID int) error {
+	args := m.Called(answerID)
+	return args.Error(0)
+}
+
+func newVoteService() (*VoteService, *MockVoteRepository, *MockAnswerRepository) {
+	voteRepo := new(MockVoteRepository)
+	answerRepo := new(MockAnswerRepository)
+	answer := &models.Answer{ID: 1, QuestionID: 1, UserID: "bob", Question: models.Question{ID: 1, UserID: "alice"}}
+	answerRepo.On("GetByID", 1).Return(answer, nil)
+	answerRepo.On("GetByID", 2).Return(nil, gorm.ErrRecordNotFound)
+	return NewVoteService(voteRepo, answerRepo), voteRepo, answerRepo
+}
+
+func TestVoteService_Vote(t *testing.T) {
+	service, voteRepo, _ := newVoteService()
+	voteRepo.On("Cast", 1, "carol", -1).Return(nil)
+	voteRepo.On("Cast", 1, "carol", 0).Return(nil)
+
+	assert.NoError(t, service.Vote(1, " carol ", -1))
+	assert.NoError(t, service.Retract(1, "carol"))
+	voteRepo.AssertExpectations(t)
+
+	assert.EqualError(t, service.Vote(1, "carol", 2), "vote must be 1 or -1")
+	assert.EqualError(t, service.Vote(1, " ", 1), "user_id cannot be empty")
+	assert.EqualError(t, service.Vote(1, "bob", 1), "cannot vote on your own answer")
+	assert.EqualError(t, service.Vote(2, "carol", 1), "answer not found")
+	voteRepo.AssertNumberOfCalls(t, "Cast", 2)
+}
+
+func TestVoteService_Accept(t *testing.T) {
+	service, voteRepo, _ := newVoteService()
+	voteRepo.On("Accept", 1).Return(nil)
+
+	assert.NoError(t, service.Accept(1, "alice"))
+	assert.EqualError(t, service.Accept(1, "carol"), "only the author of the question can accept an answer")
+	assert.EqualError(t, service.Accept(2, "alice"), "answer not found")
+	voteRepo.AssertNumberOfCalls(t, "Accept", 1)
+}
@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"qa-api/internal/events"
+	"qa-api/internal/models"
+	"qa-api/internal/webhook"
+	"strings"
+)
+
+// webhookEventTypes lists the events a webhook can subscribe to
+var webhookEventTypes = map[string]bool{
+	events.QuestionCreated: true,
+	events.QuestionDeleted: true,
+	events.AnswerCreated:   true,
+	events.AnswerDeleted:   true,
+	events.AnswerAccepted:  true,
+	events.VoteCast:        true,
+}
+
+// WebhookService handles business logic for webhook subscriptions
+type WebhookService struct {
+	webhookRepo  WebhookRepositoryInterface
+	questionRepo QuestionRepositoryInterface
+	allowPrivate bool
+}
+
+// NewWebhookService creates a new WebhookService. Targets on loopback,
+// link-local and private addresses are rejected unless allowPrivate is set.
+func NewWebhookService(webhookRepo WebhookRepositoryInterface, questionRepo QuestionRepositoryInterface, allowPrivate bool) *WebhookService {
+	return &WebhookService{
+		webhookRepo:  webhookRepo,
+		questionRepo: questionRepo,
+		allowPrivate: allowPrivate,
+	}
+}
+
+// CreateWebhook creates a new webhook subscription. A random secret is
+// generated when none is given.
+func (s *WebhookService) CreateWebhook(targetURL string, eventTypes []string, questionID *int, secret string) (*models.Webhook, error) {
+	targetURL = strings.TrimSpace(targetURL)
+	if err := webhook.CheckURL(context.Background(), targetURL, s.allowPrivate); err != nil {
+		return nil, err
+	}
+
+	if len(eventTypes) == 0 {
+		return nil, errors.New("event_types cannot be empty")
+	}
+	for _, eventType := range eventTypes {
+		if !webhookEventTypes[eventType] {
+			return nil, fmt.Errorf("unknown event type: %s", eventType)
+		}
+	}
+
+	if questionID != nil {
+		exists, err := s.questionRepo.Exists(*questionID)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			return nil, errors.New("question not found")
+		}
+	}
+
+	secret = strings.TrimSpace(secret)
+	if secret == "" {
+		var err error
+		if secret, err = generateSecret(); err != nil {
+			return nil, err
+		}
+	}
+
+	subscription := &models.Webhook{
+		URL:        targetURL,
+		Secret:     secret,
+		EventTypes: eventTypes,
+		QuestionID: questionID,
+	}
+
+	if err := s.webhookRepo.Create(subscription); err != nil {
+		return nil, err
+	}
+
+	return subscription, nil
+}
+
+// GetDeliveries retrieves the delivery log of a webhook
+func (s *WebhookService) GetDeliveries(webhookID int) ([]models.WebhookDelivery, error) {
+	if _, err := s.webhookRepo.GetByID(webhookID); err != nil {
+		return nil, errors.New("webhook not found")
+	}
+	return s.webhookRepo.GetDeliveries(webhookID)
+}
+
+// DeleteWebhook deletes a webhook by ID
+func (s *WebhookService) DeleteWebhook(id int) error {
+	if _, err := s.webhookRepo.GetByID(id); err != nil {
+		return errors.New("webhook not found")
+	}
+	return s.webhookRepo.Delete(id)
+}
+
+func generateSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
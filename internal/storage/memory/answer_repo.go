@@ -0,0 +1,144 @@
+package memory
+
+import (
+	"qa-api/internal/events"
+	"qa-api/internal/models"
+	"qa-api/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+// AnswerRepository keeps answers in a Store
+type AnswerRepository struct {
+	store *Store
+}
+
+// NewAnswerRepository creates a new AnswerRepository
+func NewAnswerRepository(store *Store) *AnswerRepository {
+	return &AnswerRepository{store: store}
+}
+
+// Create creates a new answer, bumps the version of its question and
+// records an AnswerCreated event
+func (r *AnswerRepository) Create(answer *models.Answer) error {
+	s := r.store
+	return s.write(func() error {
+		if err := s.references(answer.QuestionID); err != nil {
+			return err
+		}
+		s.insertAnswer(answer)
+		event, err := events.NewAnswerEvent(events.AnswerCreated, answer)
+		if err != nil {
+			return err
+		}
+		s.bumpQuestionVersion(answer.QuestionID, 1)
+		s.appendEvent(event)
+		return nil
+	})
+}
+
+// GetByID retrieves an answer by ID together with its question
+func (r *AnswerRepository) GetByID(id int) (*models.Answer, error) {
+	s := r.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	answer, err := s.answer(id)
+	if err != nil {
+		return &models.Answer{}, err
+	}
+	result := copyAnswer(answer)
+	if question, err := s.question(answer.QuestionID); err == nil {
+		result.Question = copyQuestion(question)
+	}
+	return &result, nil
+}
+
+// GetByQuestionIDs retrieves up to limit answers of each of the given
+// questions with an ID greater than afterID, ordered by ID
+func (r *AnswerRepository) GetByQuestionIDs(questionIDs []int, afterID, limit int) ([]models.Answer, error) {
+	s := r.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	answers := s.liveAnswers(func(a *models.Answer) bool { return a.ID > afterID && contains(questionIDs, a.QuestionID) })
+	return firstPerKey(answers, limit, func(a models.Answer) int { return a.QuestionID }), nil
+}
+
+// GetByUserIDs retrieves up to limit answers of each of the given users with
+// an ID greater than afterID, ordered by ID
+func (r *AnswerRepository) GetByUserIDs(userIDs []string, afterID, limit int) ([]models.Answer, error) {
+	s := r.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	answers := s.liveAnswers(func(a *models.Answer) bool { return a.ID > afterID && contains(userIDs, a.UserID) })
+	return firstPerKey(answers, limit, func(a models.Answer) string { return a.UserID }), nil
+}
+
+// firstPerKey keeps the first limit answers of each key
+func firstPerKey[K comparable](answers []models.Answer, limit int, key func(models.Answer) K) []models.Answer {
+	counts := make(map[K]int)
+	kept := answers[:0]
+	for _, answer := range answers {
+		if counts[key(answer)] < limit {
+			counts[key(answer)]++
+			kept = append(kept, answer)
+		}
+	}
+	return kept
+}
+
+// Delete soft-deletes an answer by ID and records an AnswerDeleted event.
+// A non-zero version must match the stored one.
+func (r *AnswerRepository) Delete(id, version int) error {
+	s := r.store
+	return s.write(func() error {
+		answer, err := s.answer(id)
+		if err != nil {
+			return err
+		}
+		if version != 0 && answer.Version != version {
+			return repository.ErrVersionMismatch
+		}
+		event, err := events.NewAnswerEvent(events.AnswerDeleted, answer)
+		if err != nil {
+			return err
+		}
+
+		answer.DeletedAt = deletedNow()
+		s.bumpQuestionVersion(answer.QuestionID, -1)
+		s.appendEvent(event)
+		return nil
+	})
+}
+
+// Restore undoes the soft delete of an answer and records an AnswerCreated
+// event, so subscribers see it reappear. The question must not be deleted.
+func (r *AnswerRepository) Restore(id int) (*models.Answer, error) {
+	s := r.store
+	var result models.Answer
+	err := s.write(func() error {
+		answer, ok := s.answers[id]
+		if !ok {
+			return gorm.ErrRecordNotFound
+		}
+		if !answer.DeletedAt.Valid {
+			return repository.ErrNotDeleted
+		}
+		if _, err := s.question(answer.QuestionID); err != nil {
+			return err
+		}
+
+		result = copyAnswer(answer)
+		result.DeletedAt = gorm.DeletedAt{}
+		event, err := events.NewAnswerEvent(events.AnswerCreated, &result)
+		if err != nil {
+			return err
+		}
+
+		answer.DeletedAt = gorm.DeletedAt{}
+		s.bumpQuestionVersion(answer.QuestionID, 1)
+		s.appendEvent(event)
+		return nil
+	})
+	return &result, err
+}
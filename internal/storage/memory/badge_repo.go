@@ -0,0 +1,119 @@
+package memory
+
+import (
+	"qa-api/internal/models"
+	"sort"
+)
+
+// BadgeRepository keeps badges in a Store and sums up activity from it
+type BadgeRepository struct {
+	store *Store
+}
+
+// NewBadgeRepository creates a new BadgeRepository
+func NewBadgeRepository(store *Store) *BadgeRepository {
+	return &BadgeRepository{store: store}
+}
+
+// Activity sums up the live questions, answers and votes of a user
+func (r *BadgeRepository) Activity(userID string) (models.Activity, error) {
+	s := r.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var activity models.Activity
+	for _, question := range s.liveQuestions(func(q *models.Question) bool { return q.UserID == userID }) {
+		activity.Questions++
+		if question.ViewCount > activity.QuestionViews {
+			activity.QuestionViews = question.ViewCount
+		}
+	}
+	scores := make(map[int]int)
+	for _, answer := range s.liveAnswers(func(a *models.Answer) bool { return a.UserID == userID }) {
+		activity.Answers++
+		if question, err := s.question(answer.QuestionID); err == nil &&
+			question.AcceptedAnswerID != nil && *question.AcceptedAnswerID == answer.ID {
+			activity.AcceptedAnswers++
+		}
+		scores[answer.ID] = 0
+	}
+	for key, vote := range s.votes {
+		if key.userID == userID {
+			activity.VotesCast++
+		}
+		if _, ok := scores[key.answerID]; ok {
+			scores[key.answerID] += vote.Value
+		}
+	}
+	for _, score := range scores {
+		if score > activity.AnswerScore {
+			activity.AnswerScore = score
+		}
+	}
+	for _, entry := range s.ledger {
+		if entry.UserID == userID {
+			activity.Reputation += entry.Delta
+		}
+	}
+	return activity, nil
+}
+
+// Awards retrieves the badges of a user in the order they were awarded
+func (r *BadgeRepository) Awards(userID string) ([]models.BadgeAward, error) {
+	s := r.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	awards := []models.BadgeAward{}
+	for key, award := range s.awards {
+		if key.userID == userID {
+			awards = append(awards, *award)
+		}
+	}
+	sort.Slice(awards, func(i, j int) bool {
+		a, b := awards[i], awards[j]
+		if !a.AwardedAt.Equal(b.AwardedAt) {
+			return a.AwardedAt.Before(b.AwardedAt)
+		}
+		if a.Badge != b.Badge {
+			return a.Badge < b.Badge
+		}
+		return a.Tier < b.Tier
+	})
+	return awards, nil
+}
+
+// Award stores badges, skipping those the user already holds
+func (r *BadgeRepository) Award(awards []models.BadgeAward) error {
+	s := r.store
+	return s.write(func() error {
+		for _, award := range awards {
+			key := awardKey{userID: award.UserID, badge: award.Badge, tier: award.Tier}
+			if _, ok := s.awards[key]; ok {
+				continue
+			}
+			stored := award
+			s.awards[key] = &stored
+		}
+		return nil
+	})
+}
+
+// UserIDs retrieves up to limit user IDs greater than afterID, ordered
+func (r *BadgeRepository) UserIDs(afterID string, limit int) ([]string, error) {
+	s := r.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ids := []string{}
+	for id := range s.users {
+		if id > afterID {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	if len(ids) > limit {
+		ids = ids[:limit]
+	}
+	return ids, nil
+}
@@ -0,0 +1,52 @@
+package memory_test
+
+import (
+	"qa-api/internal/events"
+	"qa-api/internal/models"
+	"qa-api/internal/storage"
+	"qa-api/internal/storage/memory"
+	"qa-api/internal/storage/storagetest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConformance(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) storage.Backend {
+		return storage.NewMemory(memory.NewStore())
+	})
+}
+
+func TestSubscribe(t *testing.T) {
+	store := memory.NewStore()
+	backend := storage.NewMemory(store)
+	var received []events.Event
+	store.Subscribe(func(event events.Event) { received = append(received, event) })
+
+	question := &models.Question{Text: "question"}
+	require.NoError(t, backend.Questions.Create(question))
+	require.Error(t, backend.Answers.Create(&models.Answer{QuestionID: question.ID + 1, UserID: "alice", Text: "orphan"}))
+	require.NoError(t, backend.Answers.Create(&models.Answer{QuestionID: question.ID, UserID: "alice", Text: "answer"}))
+
+	require.Len(t, received, 2, "failed writes publish nothing")
+	assert.Equal(t, events.QuestionCreated, received[0].Type)
+	assert.Equal(t, events.AnswerCreated, received[1].Type)
+	assert.Equal(t, question.ID, received[1].QuestionID)
+}
+
+func TestReturnsCopies(t *testing.T) {
+	backend := storage.NewMemory(memory.NewStore())
+	question := &models.Question{Text: "question"}
+	require.NoError(t, backend.Questions.Create(question))
+	question.Text = "changed by the caller"
+
+	stored, err := backend.Questions.GetByID(question.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "question", stored.Text)
+
+	stored.Text = "changed again"
+	again, err := backend.Questions.GetByID(question.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "question", again.Text)
+}
@@ -0,0 +1,233 @@
+package memory
+
+import (
+	"qa-api/internal/models"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// NotificationRepository keeps notifications, their preferences and
+// question follows in a Store
+type NotificationRepository struct {
+	store *Store
+}
+
+// NewNotificationRepository creates a new NotificationRepository
+func NewNotificationRepository(store *Store) *NotificationRepository {
+	return &NotificationRepository{store: store}
+}
+
+// QuestionAuthor returns the author of a live question, or an empty string
+// for anonymous and deleted questions
+func (r *NotificationRepository) QuestionAuthor(questionID int) (string, error) {
+	s := r.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	question, err := s.question(questionID)
+	if err != nil {
+		return "", nil
+	}
+	return question.UserID, nil
+}
+
+// Followers returns the users following a question, ordered by user ID
+func (r *NotificationRepository) Followers(questionID int) ([]string, error) {
+	s := r.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var userIDs []string
+	for key := range s.follows {
+		if key.questionID == questionID {
+			userIDs = append(userIDs, key.userID)
+		}
+	}
+	sort.Strings(userIDs)
+	return userIDs, nil
+}
+
+// Muted returns those of userIDs who turned kind off
+func (r *NotificationRepository) Muted(kind string, userIDs []string) ([]string, error) {
+	s := r.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var muted []string
+	for _, userID := range userIDs {
+		if enabled, ok := s.preferences[preferenceKey{userID: userID, kind: kind}]; ok && !enabled {
+			muted = append(muted, userID)
+		}
+	}
+	return muted, nil
+}
+
+// Notify stores notifications, skipping those already stored for the same
+// event and user
+func (r *NotificationRepository) Notify(notifications []models.Notification) error {
+	s := r.store
+	return s.write(func() error {
+		for _, notification := range notifications {
+			if s.notified(notification.EventID, notification.UserID) {
+				continue
+			}
+			s.lastNotificationID++
+			stored := notification
+			stored.ID = s.lastNotificationID
+			if stored.CreatedAt.IsZero() {
+				stored.CreatedAt = time.Now()
+			}
+			s.notifications[stored.ID] = &stored
+		}
+		return nil
+	})
+}
+
+// notified reports whether a user already holds a notification of an event
+func (s *Store) notified(eventID int64, userID string) bool {
+	for _, notification := range s.notifications {
+		if notification.EventID == eventID && notification.UserID == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// List retrieves up to limit notifications of a user with an ID below
+// beforeID, newest first; beforeID 0 starts with the newest
+func (r *NotificationRepository) List(userID string, beforeID int64, limit int) ([]models.Notification, error) {
+	s := r.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	notifications := []models.Notification{}
+	for _, notification := range s.notifications {
+		if notification.UserID == userID && (beforeID <= 0 || notification.ID < beforeID) {
+			notifications = append(notifications, copyNotification(notification))
+		}
+	}
+	sort.Slice(notifications, func(i, j int) bool { return notifications[i].ID > notifications[j].ID })
+	if len(notifications) > limit {
+		notifications = notifications[:limit]
+	}
+	return notifications, nil
+}
+
+// UnreadCount returns the number of unread notifications of a user
+func (r *NotificationRepository) UnreadCount(userID string) (int64, error) {
+	s := r.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var count int64
+	for _, notification := range s.notifications {
+		if notification.UserID == userID && notification.ReadAt == nil {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// MarkRead marks a notification of a user as read; marking it again keeps
+// the first read time. Notifications of other users are not found.
+func (r *NotificationRepository) MarkRead(userID string, id int64) error {
+	s := r.store
+	return s.write(func() error {
+		notification, ok := s.notifications[id]
+		if !ok || notification.UserID != userID {
+			return gorm.ErrRecordNotFound
+		}
+		if notification.ReadAt == nil {
+			now := time.Now()
+			notification.ReadAt = &now
+		}
+		return nil
+	})
+}
+
+// MarkAllRead marks every unread notification of a user as read and
+// returns how many there were
+func (r *NotificationRepository) MarkAllRead(userID string) (int64, error) {
+	s := r.store
+	var count int64
+	err := s.write(func() error {
+		now := time.Now()
+		for _, notification := range s.notifications {
+			if notification.UserID == userID && notification.ReadAt == nil {
+				readAt := now
+				notification.ReadAt = &readAt
+				count++
+			}
+		}
+		return nil
+	})
+	return count, err
+}
+
+// GetPreferences returns the kinds a user turned on or off
+func (r *NotificationRepository) GetPreferences(userID string) (map[string]bool, error) {
+	s := r.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	preferences := make(map[string]bool)
+	for key, enabled := range s.preferences {
+		if key.userID == userID {
+			preferences[key.kind] = enabled
+		}
+	}
+	return preferences, nil
+}
+
+// SavePreferences turns the given kinds on or off for a user and leaves
+// the others as they are
+func (r *NotificationRepository) SavePreferences(userID string, preferences map[string]bool) error {
+	if len(preferences) == 0 {
+		return nil
+	}
+	s := r.store
+	return s.write(func() error {
+		s.ensureUser(userID, time.Now())
+		for kind, enabled := range preferences {
+			s.preferences[preferenceKey{userID: userID, kind: kind}] = enabled
+		}
+		return nil
+	})
+}
+
+// Follow makes a user follow a live question; following it again changes
+// nothing
+func (r *NotificationRepository) Follow(questionID int, userID string) error {
+	s := r.store
+	return s.write(func() error {
+		if _, err := s.question(questionID); err != nil {
+			return err
+		}
+		s.ensureUser(userID, time.Now())
+		key := followKey{questionID: questionID, userID: userID}
+		if _, ok := s.follows[key]; !ok {
+			s.follows[key] = &models.QuestionFollow{QuestionID: questionID, UserID: userID, CreatedAt: time.Now()}
+		}
+		return nil
+	})
+}
+
+// Unfollow stops a user following a question, if they did
+func (r *NotificationRepository) Unfollow(questionID int, userID string) error {
+	s := r.store
+	return s.write(func() error {
+		delete(s.follows, followKey{questionID: questionID, userID: userID})
+		return nil
+	})
+}
+
+func copyNotification(notification *models.Notification) models.Notification {
+	result := *notification
+	if notification.ReadAt != nil {
+		readAt := *notification.ReadAt
+		result.ReadAt = &readAt
+	}
+	return result
+}
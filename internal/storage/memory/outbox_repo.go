@@ -0,0 +1,127 @@
+package memory
+
+import (
+	"qa-api/internal/events"
+	"qa-api/internal/models"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// OutboxRepository keeps outbox events in a Store
+type OutboxRepository struct {
+	store *Store
+}
+
+// NewOutboxRepository creates a new OutboxRepository
+func NewOutboxRepository(store *Store) *OutboxRepository {
+	return &OutboxRepository{store: store}
+}
+
+// ClaimDue returns due undelivered events and pushes their next attempt
+// forward by lease, so concurrent dispatchers never pick the same batch
+func (r *OutboxRepository) ClaimDue(limit int, lease time.Duration) ([]events.Event, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	result := []events.Event{}
+	for _, record := range s.sortedOutbox() {
+		if len(result) == limit {
+			break
+		}
+		if record.DispatchedAt == nil && !record.NextAttemptAt.After(now) {
+			record.NextAttemptAt = now.Add(lease)
+			result = append(result, events.FromOutbox(*record))
+		}
+	}
+	return result, nil
+}
+
+// MarkDispatched marks an event as delivered
+func (r *OutboxRepository) MarkDispatched(id int64) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if record, ok := s.outbox[id]; ok {
+		now := time.Now()
+		record.DispatchedAt = &now
+	}
+	return nil
+}
+
+// MarkFailed records a failed delivery attempt and schedules a retry
+func (r *OutboxRepository) MarkFailed(id int64, attempts int, nextAttemptAt time.Time, lastError string) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if record, ok := s.outbox[id]; ok {
+		record.Attempts = attempts
+		record.NextAttemptAt = nextAttemptAt
+		record.LastError = lastError
+	}
+	return nil
+}
+
+// GetByID retrieves an event by ID
+func (r *OutboxRepository) GetByID(id int64) (events.Event, error) {
+	s := r.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	record, ok := s.outbox[id]
+	if !ok {
+		return events.Event{}, gorm.ErrRecordNotFound
+	}
+	return events.FromOutbox(*record), nil
+}
+
+// EventsSince retrieves up to limit events of a question with an ID greater than afterID
+func (r *OutboxRepository) EventsSince(questionID int, afterID int64, limit int) ([]events.Event, error) {
+	s := r.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := []events.Event{}
+	for _, record := range s.sortedOutbox() {
+		if len(result) == limit {
+			break
+		}
+		if record.QuestionID == questionID && record.ID > afterID {
+			result = append(result, events.FromOutbox(*record))
+		}
+	}
+	return result, nil
+}
+
+// EventsAfter retrieves up to limit events with an ID greater than afterID, ordered by ID
+func (r *OutboxRepository) EventsAfter(afterID int64, limit int) ([]events.Event, error) {
+	s := r.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := []events.Event{}
+	for _, record := range s.sortedOutbox() {
+		if len(result) == limit {
+			break
+		}
+		if record.ID > afterID {
+			result = append(result, events.FromOutbox(*record))
+		}
+	}
+	return result, nil
+}
+
+// sortedOutbox returns the outbox records ordered by ID
+func (s *Store) sortedOutbox() []*models.OutboxEvent {
+	records := make([]*models.OutboxEvent, 0, len(s.outbox))
+	for _, record := range s.outbox {
+		records = append(records, record)
+	}
+	sort.Slice(records, func(i, j int) bool { return records[i].ID < records[j].ID })
+	return records
+}
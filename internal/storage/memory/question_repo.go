@@ -0,0 +1,351 @@
+package memory
+
+import (
+	"qa-api/internal/events"
+	"qa-api/internal/models"
+	"qa-api/internal/repository"
+	"slices"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// QuestionRepository keeps questions in a Store
+type QuestionRepository struct {
+	store *Store
+}
+
+// NewQuestionRepository creates a new QuestionRepository
+func NewQuestionRepository(store *Store) *QuestionRepository {
+	return &QuestionRepository{store: store}
+}
+
+// Create creates a new question and records a QuestionCreated event
+func (r *QuestionRepository) Create(question *models.Question) error {
+	s := r.store
+	return s.write(func() error {
+		s.insertQuestion(question)
+		event, err := events.NewQuestionEvent(events.QuestionCreated, question)
+		if err != nil {
+			return err
+		}
+		s.appendEvent(event)
+		return nil
+	})
+}
+
+// GetAll retrieves all questions
+func (r *QuestionRepository) GetAll() ([]models.Question, error) {
+	s := r.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	questions := []models.Question{}
+	for _, question := range s.liveQuestions(all[models.Question]) {
+		questions = append(questions, copyQuestion(question))
+	}
+	return questions, nil
+}
+
+// GetAllWithAnswers retrieves all questions with their answers
+func (r *QuestionRepository) GetAllWithAnswers() ([]models.Question, error) {
+	return r.GetPageWithAnswers(0, -1)
+}
+
+// GetByID retrieves a question by ID with its answers
+func (r *QuestionRepository) GetByID(id int) (*models.Question, error) {
+	s := r.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	question, err := s.question(id)
+	if err != nil {
+		return &models.Question{}, err
+	}
+	result := s.withAnswers(question)
+	return &result, nil
+}
+
+// GetPage retrieves up to limit questions with an ID greater than afterID, ordered by ID
+func (r *QuestionRepository) GetPage(afterID, limit int) ([]models.Question, error) {
+	s := r.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	questions := []models.Question{}
+	for _, question := range s.liveQuestions(func(q *models.Question) bool { return q.ID > afterID }) {
+		if len(questions) == limit {
+			break
+		}
+		questions = append(questions, copyQuestion(question))
+	}
+	return questions, nil
+}
+
+// GetByIDs retrieves the questions with the given IDs, without their answers
+func (r *QuestionRepository) GetByIDs(ids []int) ([]models.Question, error) {
+	s := r.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	questions := []models.Question{}
+	for _, question := range s.liveQuestions(func(q *models.Question) bool { return contains(ids, q.ID) }) {
+		questions = append(questions, copyQuestion(question))
+	}
+	return questions, nil
+}
+
+// GetPageWithAnswers is GetPage with the answers of each question; a
+// negative limit returns every question
+func (r *QuestionRepository) GetPageWithAnswers(afterID, limit int) ([]models.Question, error) {
+	s := r.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	questions := []models.Question{}
+	for _, question := range s.liveQuestions(func(q *models.Question) bool { return q.ID > afterID }) {
+		if len(questions) == limit {
+			break
+		}
+		questions = append(questions, s.withAnswers(question))
+	}
+	return questions, nil
+}
+
+// CreateInBatches inserts questions together with their answers at once
+// and records a QuestionCreated event for every question and an
+// AnswerCreated event for every answer
+func (r *QuestionRepository) CreateInBatches(questions []models.Question, batchSize int) error {
+	s := r.store
+	return s.write(func() error {
+		for i := range questions {
+			questions[i].AnswerCount = len(questions[i].Answers)
+			s.insertQuestion(&questions[i])
+		}
+
+		records, err := events.NewCreatedEvents(questions)
+		if err != nil {
+			return err
+		}
+		for _, record := range records {
+			s.appendEvent(record)
+		}
+		return nil
+	})
+}
+
+// Delete soft-deletes a question by ID together with its answers and
+// records a QuestionDeleted event. A non-zero version must match the stored
+// one, otherwise ErrVersionMismatch is returned.
+func (r *QuestionRepository) Delete(id, version int) error {
+	s := r.store
+	return s.write(func() error {
+		question, err := s.question(id)
+		if err != nil {
+			return err
+		}
+		if version != 0 && question.Version != version {
+			return repository.ErrVersionMismatch
+		}
+		event, err := events.NewQuestionEvent(events.QuestionDeleted, question)
+		if err != nil {
+			return err
+		}
+
+		// The answers share the timestamp so Restore can tell them apart
+		// from answers deleted on their own earlier
+		deletedAt := deletedNow()
+		for _, answer := range s.answers {
+			if answer.QuestionID == id && !answer.DeletedAt.Valid {
+				answer.DeletedAt = deletedAt
+			}
+		}
+		question.DeletedAt = deletedAt
+		s.appendEvent(event)
+		return nil
+	})
+}
+
+// Restore undoes the soft delete of a question and of the answers deleted
+// with it, and records a QuestionCreated event
+func (r *QuestionRepository) Restore(id int) (*models.Question, error) {
+	s := r.store
+	var result models.Question
+	err := s.write(func() error {
+		question, ok := s.questions[id]
+		if !ok {
+			return gorm.ErrRecordNotFound
+		}
+		if !question.DeletedAt.Valid {
+			return repository.ErrNotDeleted
+		}
+
+		restored := copyQuestion(question)
+		restored.DeletedAt = gorm.DeletedAt{}
+		event, err := events.NewQuestionEvent(events.QuestionCreated, &restored)
+		if err != nil {
+			return err
+		}
+
+		for _, answer := range s.answers {
+			if answer.QuestionID == id && answer.DeletedAt.Valid && answer.DeletedAt.Time.Equal(question.DeletedAt.Time) {
+				answer.DeletedAt = gorm.DeletedAt{}
+			}
+		}
+		question.DeletedAt = gorm.DeletedAt{}
+		question.Version++
+		question.AnswerCount = len(s.liveAnswers(func(a *models.Answer) bool { return a.QuestionID == id }))
+		result = s.withAnswers(question)
+		s.appendEvent(event)
+		return nil
+	})
+	return &result, err
+}
+
+// RecordViews adds views, counts keyed by question ID, in one write;
+// deleted and unknown questions are skipped. Only a question passing one of
+// milestones records a QuestionViewed event.
+func (r *QuestionRepository) RecordViews(views map[int]int, milestones []int) error {
+	s := r.store
+	return s.write(func() error {
+		ids := make([]int, 0, len(views))
+		for id := range views {
+			ids = append(ids, id)
+		}
+		slices.Sort(ids)
+		for _, id := range ids {
+			question, err := s.question(id)
+			if err != nil {
+				continue
+			}
+			before := question.ViewCount
+			question.ViewCount += views[id]
+			reached := slices.ContainsFunc(milestones, func(milestone int) bool {
+				return before < milestone && milestone <= question.ViewCount
+			})
+			if !reached {
+				continue
+			}
+			event, err := events.NewViewEvent(question)
+			if err != nil {
+				return err
+			}
+			s.appendEvent(event)
+		}
+		return nil
+	})
+}
+
+// RecountAnswers rebuilds the denormalized answer counts and returns the
+// number of questions that were off. Changed questions get a new version
+// and a QuestionUpdated event.
+func (r *QuestionRepository) RecountAnswers() (int64, error) {
+	s := r.store
+	var updated int64
+	err := s.write(func() error {
+		counts := make(map[int]int)
+		for _, answer := range s.answers {
+			if !answer.DeletedAt.Valid {
+				counts[answer.QuestionID]++
+			}
+		}
+		for _, question := range s.liveQuestions(all[models.Question]) {
+			if question.AnswerCount != counts[question.ID] {
+				question.AnswerCount = counts[question.ID]
+				question.Version++
+				updated++
+
+				event, err := events.NewQuestionEvent(events.QuestionUpdated, question)
+				if err != nil {
+					return err
+				}
+				s.appendEvent(event)
+			}
+		}
+		return nil
+	})
+	return updated, err
+}
+
+// Purge permanently removes the questions and answers soft-deleted before
+// the given time and returns how many of each were removed. Votes and
+// follows go with them, as through the foreign keys. Questions whose
+// accepted answer is purged lose the mark, and the live ones get a new
+// version and a QuestionUpdated event.
+func (r *QuestionRepository) Purge(before time.Time) (questions, answers int64, err error) {
+	s := r.store
+	purged := func(deletedAt gorm.DeletedAt) bool {
+		return deletedAt.Valid && deletedAt.Time.Before(before)
+	}
+	err = s.write(func() error {
+		for id, answer := range s.answers {
+			if purged(answer.DeletedAt) || purged(s.questions[answer.QuestionID].DeletedAt) {
+				delete(s.answers, id)
+				answers++
+			}
+		}
+		for id, question := range s.questions {
+			if purged(question.DeletedAt) {
+				delete(s.questions, id)
+				questions++
+			}
+		}
+		for key := range s.votes {
+			if _, ok := s.answers[key.answerID]; !ok {
+				delete(s.votes, key)
+			}
+		}
+		for key := range s.follows {
+			if _, ok := s.questions[key.questionID]; !ok {
+				delete(s.follows, key)
+			}
+		}
+
+		var unaccepted []int
+		for id, question := range s.questions {
+			if question.AcceptedAnswerID == nil {
+				continue
+			}
+			if _, ok := s.answers[*question.AcceptedAnswerID]; !ok {
+				question.AcceptedAnswerID = nil
+				question.Version++
+				unaccepted = append(unaccepted, id)
+			}
+		}
+		slices.Sort(unaccepted)
+		for _, id := range unaccepted {
+			question, err := s.question(id)
+			if err != nil {
+				continue
+			}
+			event, err := events.NewQuestionEvent(events.QuestionUpdated, question)
+			if err != nil {
+				return err
+			}
+			s.appendEvent(event)
+		}
+		return nil
+	})
+	return questions, answers, err
+}
+
+// Exists checks if a question exists
+func (r *QuestionRepository) Exists(id int) (bool, error) {
+	s := r.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	_, err := s.question(id)
+	return err == nil, nil
+}
+
+func all[T any](*T) bool { return true }
+
+func contains[T comparable](list []T, value T) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,105 @@
+package memory
+
+import (
+	"qa-api/internal/models"
+	"sort"
+	"time"
+)
+
+// ReputationRepository keeps the reputation ledger in a Store
+type ReputationRepository struct {
+	store *Store
+}
+
+// NewReputationRepository creates a new ReputationRepository
+func NewReputationRepository(store *Store) *ReputationRepository {
+	return &ReputationRepository{store: store}
+}
+
+// Record appends entries to the ledger, skipping those already recorded
+// for the same event, user and reason
+func (r *ReputationRepository) Record(entries []models.ReputationEntry) error {
+	s := r.store
+	return s.write(func() error {
+		s.recordEntries(entries)
+		return nil
+	})
+}
+
+// Replace swaps the entries of the events up to throughEventID for entries
+// in one write; entries of later events are kept
+func (r *ReputationRepository) Replace(entries []models.ReputationEntry, throughEventID int64) error {
+	s := r.store
+	return s.write(func() error {
+		for key := range s.ledger {
+			if key.eventID <= throughEventID {
+				delete(s.ledger, key)
+			}
+		}
+		s.recordEntries(entries)
+		return nil
+	})
+}
+
+// recordEntries stores the entries the ledger does not hold yet
+func (s *Store) recordEntries(entries []models.ReputationEntry) {
+	for _, entry := range entries {
+		key := ledgerKey{eventID: entry.EventID, userID: entry.UserID, reason: entry.Reason}
+		if _, ok := s.ledger[key]; ok {
+			continue
+		}
+		stored := entry
+		s.lastEntryID++
+		stored.ID = s.lastEntryID
+		if stored.CreatedAt.IsZero() {
+			stored.CreatedAt = time.Now()
+		}
+		s.ledger[key] = &stored
+	}
+}
+
+// Total returns the reputation of a user, 0 for users without entries
+func (r *ReputationRepository) Total(userID string) (int, error) {
+	s := r.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	total := 0
+	for _, entry := range s.ledger {
+		if entry.UserID == userID {
+			total += entry.Delta
+		}
+	}
+	return total, nil
+}
+
+// Leaderboard returns the limit users with the highest reputation; ties are
+// ordered by user ID
+func (r *ReputationRepository) Leaderboard(limit int) ([]models.Standing, error) {
+	s := r.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	totals := make(map[string]int)
+	for _, entry := range s.ledger {
+		totals[entry.UserID] += entry.Delta
+	}
+	standings := []models.Standing{}
+	for userID, reputation := range totals {
+		standing := models.Standing{UserID: userID, Reputation: reputation}
+		if user, ok := s.users[userID]; ok {
+			standing.DisplayName = user.DisplayName
+		}
+		standings = append(standings, standing)
+	}
+	sort.Slice(standings, func(i, j int) bool {
+		if standings[i].Reputation != standings[j].Reputation {
+			return standings[i].Reputation > standings[j].Reputation
+		}
+		return standings[i].UserID < standings[j].UserID
+	})
+	if len(standings) > limit {
+		standings = standings[:limit]
+	}
+	return standings, nil
+}
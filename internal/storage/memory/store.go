@@ -0,0 +1,297 @@
+// Package memory keeps questions, answers, events and webhooks in process
+// memory. It mirrors the PostgreSQL repositories, soft deletes, versions
+// and foreign keys included, so the service behaves the same on it; data
+// is lost when the process exits.
+package memory
+
+import (
+	"fmt"
+	"qa-api/internal/events"
+	"qa-api/internal/models"
+	"sort"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Store holds the data of all repositories behind a single lock, so every
+// write is atomic like a database transaction
+type Store struct {
+	mu sync.RWMutex
+
+	questions     map[int]*models.Question
+	answers       map[int]*models.Answer
+	outbox        map[int64]*models.OutboxEvent
+	webhooks      map[int]*models.Webhook
+	deliveries    map[int64]*models.WebhookDelivery
+	users         map[string]*models.User
+	votes         map[voteKey]*models.Vote
+	ledger        map[ledgerKey]*models.ReputationEntry
+	awards        map[awardKey]*models.BadgeAward
+	notifications map[int64]*models.Notification
+	follows       map[followKey]*models.QuestionFollow
+	preferences   map[preferenceKey]bool
+
+	// Sequences are never rewound, like PostgreSQL's
+	lastQuestionID     int
+	lastAnswerID       int
+	lastEventID        int64
+	lastWebhookID      int
+	lastDeliveryID     int64
+	lastEntryID        int64
+	lastNotificationID int64
+
+	// pending collects the events of the running write; they reach the
+	// subscribers after it completes, as NOTIFY does after a commit
+	pending     []events.Event
+	subscribers []func(events.Event)
+}
+
+// NewStore creates an empty store
+func NewStore() *Store {
+	return &Store{
+		questions:     make(map[int]*models.Question),
+		answers:       make(map[int]*models.Answer),
+		outbox:        make(map[int64]*models.OutboxEvent),
+		webhooks:      make(map[int]*models.Webhook),
+		deliveries:    make(map[int64]*models.WebhookDelivery),
+		users:         make(map[string]*models.User),
+		votes:         make(map[voteKey]*models.Vote),
+		ledger:        make(map[ledgerKey]*models.ReputationEntry),
+		awards:        make(map[awardKey]*models.BadgeAward),
+		notifications: make(map[int64]*models.Notification),
+		follows:       make(map[followKey]*models.QuestionFollow),
+		preferences:   make(map[preferenceKey]bool),
+	}
+}
+
+// voteKey is the primary key of votes
+type voteKey struct {
+	answerID int
+	userID   string
+}
+
+// ledgerKey is the unique key of ledger entries
+type ledgerKey struct {
+	eventID int64
+	userID  string
+	reason  string
+}
+
+// awardKey is the primary key of badge awards
+type awardKey struct {
+	userID string
+	badge  string
+	tier   int
+}
+
+// followKey is the primary key of question follows
+type followKey struct {
+	questionID int
+	userID     string
+}
+
+// preferenceKey is the primary key of notification preferences
+type preferenceKey struct {
+	userID string
+	kind   string
+}
+
+// Subscribe registers fn to receive every event after the write that
+// recorded it completes
+func (s *Store) Subscribe(fn func(events.Event)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.subscribers = append(s.subscribers, fn)
+}
+
+// write runs fn under the write lock and then publishes the events it
+// recorded. fn must return any error before it changes data: there is no
+// rollback, only the pending events are discarded.
+func (s *Store) write(fn func() error) error {
+	s.mu.Lock()
+	err := fn()
+	pending := s.pending
+	s.pending = nil
+	subscribers := s.subscribers
+	s.mu.Unlock()
+
+	if err != nil {
+		return err
+	}
+	for _, event := range pending {
+		for _, subscriber := range subscribers {
+			subscriber(event)
+		}
+	}
+	return nil
+}
+
+// appendEvent stores an outbox record; the caller holds the write lock
+func (s *Store) appendEvent(record *models.OutboxEvent) {
+	s.lastEventID++
+	record.ID = s.lastEventID
+	record.CreatedAt = time.Now()
+	stored := *record
+	s.outbox[stored.ID] = &stored
+	s.pending = append(s.pending, events.FromOutbox(stored))
+}
+
+// question returns a live question, or ErrRecordNotFound like GORM's
+// default soft delete scope
+func (s *Store) question(id int) (*models.Question, error) {
+	question, ok := s.questions[id]
+	if !ok || question.DeletedAt.Valid {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return question, nil
+}
+
+// answer returns a live answer
+func (s *Store) answer(id int) (*models.Answer, error) {
+	answer, ok := s.answers[id]
+	if !ok || answer.DeletedAt.Valid {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return answer, nil
+}
+
+// insertQuestion assigns the question and its answers their IDs and
+// defaults and stores them
+func (s *Store) insertQuestion(question *models.Question) {
+	s.lastQuestionID++
+	question.ID = s.lastQuestionID
+	if question.CreatedAt.IsZero() {
+		question.CreatedAt = time.Now()
+	}
+	if question.Version == 0 {
+		question.Version = 1
+	}
+	stored := *question
+	stored.Answers = nil
+	s.questions[stored.ID] = &stored
+	s.ensureUser(question.UserID, question.CreatedAt)
+
+	for i := range question.Answers {
+		question.Answers[i].QuestionID = question.ID
+		s.insertAnswer(&question.Answers[i])
+	}
+}
+
+// insertAnswer assigns the answer its ID and defaults and stores it; the
+// caller checks its question with references
+func (s *Store) insertAnswer(answer *models.Answer) {
+	s.lastAnswerID++
+	answer.ID = s.lastAnswerID
+	if answer.CreatedAt.IsZero() {
+		answer.CreatedAt = time.Now()
+	}
+	if answer.Version == 0 {
+		answer.Version = 1
+	}
+	stored := *answer
+	stored.Question = models.Question{}
+	s.answers[stored.ID] = &stored
+	s.ensureUser(answer.UserID, answer.CreatedAt)
+}
+
+// ensureUser creates a user on their first contribution
+func (s *Store) ensureUser(id string, joinedAt time.Time) {
+	if _, ok := s.users[id]; ok || id == "" {
+		return
+	}
+	s.users[id] = &models.User{ID: id, JoinedAt: joinedAt}
+}
+
+// references enforces the foreign key of answers: like PostgreSQL it only
+// needs the question row, deleted or not
+func (s *Store) references(questionID int) error {
+	if _, ok := s.questions[questionID]; !ok {
+		return fmt.Errorf("answer violates foreign key: question %d does not exist", questionID)
+	}
+	return nil
+}
+
+// bumpQuestionVersion is the in-memory counterpart of the repository
+// function; like the UPDATE it skips deleted questions
+func (s *Store) bumpQuestionVersion(questionID, answerDelta int) {
+	if question, err := s.question(questionID); err == nil {
+		question.Version++
+		question.AnswerCount += answerDelta
+	}
+}
+
+// liveQuestions returns the questions that match keep, ordered by ID
+func (s *Store) liveQuestions(keep func(*models.Question) bool) []*models.Question {
+	var list []*models.Question
+	for _, question := range s.questions {
+		if !question.DeletedAt.Valid && keep(question) {
+			list = append(list, question)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+	return list
+}
+
+// liveAnswers returns the answers that match keep, ordered by ID
+func (s *Store) liveAnswers(keep func(*models.Answer) bool) []models.Answer {
+	list := []models.Answer{}
+	for _, answer := range s.answers {
+		if !answer.DeletedAt.Valid && keep(answer) {
+			list = append(list, copyAnswer(answer))
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+	return list
+}
+
+// withAnswers copies a question together with its live answers
+func (s *Store) withAnswers(question *models.Question) models.Question {
+	result := copyQuestion(question)
+	result.Answers = s.liveAnswers(func(a *models.Answer) bool { return a.QuestionID == question.ID })
+	return result
+}
+
+// copyQuestion returns a copy without associations, so callers never share
+// memory with the store
+func copyQuestion(question *models.Question) models.Question {
+	result := *question
+	result.Answers = nil
+	if question.AcceptedAnswerID != nil {
+		acceptedAnswerID := *question.AcceptedAnswerID
+		result.AcceptedAnswerID = &acceptedAnswerID
+	}
+	return result
+}
+
+func copyAnswer(answer *models.Answer) models.Answer {
+	result := *answer
+	result.Question = models.Question{}
+	return result
+}
+
+func copyWebhook(webhook *models.Webhook) models.Webhook {
+	result := *webhook
+	result.EventTypes = append(models.StringList{}, webhook.EventTypes...)
+	if webhook.QuestionID != nil {
+		questionID := *webhook.QuestionID
+		result.QuestionID = &questionID
+	}
+	return result
+}
+
+func copyDelivery(delivery *models.WebhookDelivery) models.WebhookDelivery {
+	result := *delivery
+	result.Webhook = nil
+	if delivery.DeliveredAt != nil {
+		deliveredAt := *delivery.DeliveredAt
+		result.DeliveredAt = &deliveredAt
+	}
+	return result
+}
+
+// deletedNow marks a row as soft-deleted at the current time
+func deletedNow() gorm.DeletedAt {
+	return gorm.DeletedAt{Time: time.Now(), Valid: true}
+}
@@ -0,0 +1,74 @@
+package memory
+
+import (
+	"qa-api/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// UserRepository keeps user profiles in a Store
+type UserRepository struct {
+	store *Store
+}
+
+// NewUserRepository creates a new UserRepository
+func NewUserRepository(store *Store) *UserRepository {
+	return &UserRepository{store: store}
+}
+
+// GetByID retrieves a user by ID
+func (r *UserRepository) GetByID(id string) (*models.User, error) {
+	s := r.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	user, ok := s.users[id]
+	if !ok {
+		return &models.User{}, gorm.ErrRecordNotFound
+	}
+	result := *user
+	return &result, nil
+}
+
+// Save creates the user or updates the profile fields of an existing one;
+// the join date of an existing user is kept and loaded into user
+func (r *UserRepository) Save(user *models.User) error {
+	s := r.store
+	return s.write(func() error {
+		stored, ok := s.users[user.ID]
+		if !ok {
+			if user.JoinedAt.IsZero() {
+				user.JoinedAt = time.Now()
+			}
+			stored = &models.User{ID: user.ID, JoinedAt: user.JoinedAt}
+			s.users[user.ID] = stored
+		}
+		stored.DisplayName = user.DisplayName
+		stored.AvatarURL = user.AvatarURL
+		stored.Bio = user.Bio
+		*user = *stored
+		return nil
+	})
+}
+
+// GetQuestions retrieves the questions asked by a user, ordered by ID
+func (r *UserRepository) GetQuestions(userID string) ([]models.Question, error) {
+	s := r.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	questions := []models.Question{}
+	for _, question := range s.liveQuestions(func(q *models.Question) bool { return q.UserID == userID }) {
+		questions = append(questions, copyQuestion(question))
+	}
+	return questions, nil
+}
+
+// GetAnswers retrieves the answers written by a user, ordered by ID
+func (r *UserRepository) GetAnswers(userID string) ([]models.Answer, error) {
+	s := r.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.liveAnswers(func(a *models.Answer) bool { return a.UserID == userID }), nil
+}
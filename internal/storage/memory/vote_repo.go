@@ -0,0 +1,90 @@
+package memory
+
+import (
+	"qa-api/internal/events"
+	"qa-api/internal/models"
+	"time"
+)
+
+// VoteRepository keeps votes and accepted answers in a Store
+type VoteRepository struct {
+	store *Store
+}
+
+// NewVoteRepository creates a new VoteRepository
+func NewVoteRepository(store *Store) *VoteRepository {
+	return &VoteRepository{store: store}
+}
+
+// Cast sets the vote of a user on an answer to value and records a VoteCast
+// event; 0 retracts the vote. Casting the vote already in place changes
+// nothing and records no event.
+func (r *VoteRepository) Cast(answerID int, userID string, value int) error {
+	s := r.store
+	return s.write(func() error {
+		answer, err := s.answer(answerID)
+		if err != nil {
+			return err
+		}
+		key := voteKey{answerID: answerID, userID: userID}
+		previous := 0
+		if vote, ok := s.votes[key]; ok {
+			previous = vote.Value
+		}
+		if value == previous {
+			return nil
+		}
+		event, err := events.NewVoteEvent(answer, userID, value, previous)
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case value == 0:
+			delete(s.votes, key)
+		case previous == 0:
+			s.ensureUser(userID, time.Now())
+			s.votes[key] = &models.Vote{AnswerID: answerID, UserID: userID, Value: value, CreatedAt: time.Now()}
+		default:
+			s.votes[key].Value = value
+		}
+		s.appendEvent(event)
+		return nil
+	})
+}
+
+// Accept makes an answer the accepted one of its question, replacing an
+// earlier choice, bumps the question version and records an AnswerAccepted
+// event. Accepting the answer already accepted changes nothing.
+func (r *VoteRepository) Accept(answerID int) error {
+	s := r.store
+	return s.write(func() error {
+		answer, err := s.answer(answerID)
+		if err != nil {
+			return err
+		}
+		question, err := s.question(answer.QuestionID)
+		if err != nil {
+			return err
+		}
+		if question.AcceptedAnswerID != nil && *question.AcceptedAnswerID == answerID {
+			return nil
+		}
+
+		// Like the SQL repository this sees deleted answers too
+		var previous *models.Answer
+		if question.AcceptedAnswerID != nil {
+			previous = s.answers[*question.AcceptedAnswerID]
+		}
+		event, err := events.NewAcceptEvent(answer, question.UserID, previous)
+		if err != nil {
+			return err
+		}
+
+		accepted := answerID
+		question.AcceptedAnswerID = &accepted
+		question.Version++
+		s.appendEvent(event)
+		return nil
+	})
+}
@@ -0,0 +1,183 @@
+package memory
+
+import (
+	"fmt"
+	"qa-api/internal/models"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// WebhookRepository keeps webhooks and their deliveries in a Store
+type WebhookRepository struct {
+	store *Store
+}
+
+// NewWebhookRepository creates a new WebhookRepository
+func NewWebhookRepository(store *Store) *WebhookRepository {
+	return &WebhookRepository{store: store}
+}
+
+// Create creates a new webhook
+func (r *WebhookRepository) Create(webhook *models.Webhook) error {
+	s := r.store
+	return s.write(func() error {
+		s.lastWebhookID++
+		webhook.ID = s.lastWebhookID
+		webhook.CreatedAt = time.Now()
+		stored := copyWebhook(webhook)
+		s.webhooks[stored.ID] = &stored
+		return nil
+	})
+}
+
+// GetByID retrieves a webhook by ID
+func (r *WebhookRepository) GetByID(id int) (*models.Webhook, error) {
+	s := r.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	webhook, ok := s.webhooks[id]
+	if !ok {
+		return &models.Webhook{}, gorm.ErrRecordNotFound
+	}
+	result := copyWebhook(webhook)
+	return &result, nil
+}
+
+// Delete deletes a webhook by ID together with its deliveries
+func (r *WebhookRepository) Delete(id int) error {
+	s := r.store
+	return s.write(func() error {
+		delete(s.webhooks, id)
+		for deliveryID, delivery := range s.deliveries {
+			if delivery.WebhookID == id {
+				delete(s.deliveries, deliveryID)
+			}
+		}
+		return nil
+	})
+}
+
+// FindForQuestion retrieves webhooks without a question filter or filtered by the given question
+func (r *WebhookRepository) FindForQuestion(questionID int) ([]models.Webhook, error) {
+	s := r.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	webhooks := []models.Webhook{}
+	for _, webhook := range s.webhooks {
+		if webhook.QuestionID == nil || *webhook.QuestionID == questionID {
+			webhooks = append(webhooks, copyWebhook(webhook))
+		}
+	}
+	sort.Slice(webhooks, func(i, j int) bool { return webhooks[i].ID < webhooks[j].ID })
+	return webhooks, nil
+}
+
+// GetDeliveries retrieves the deliveries of a webhook, newest first
+func (r *WebhookRepository) GetDeliveries(webhookID int) ([]models.WebhookDelivery, error) {
+	s := r.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	deliveries := []models.WebhookDelivery{}
+	for _, delivery := range s.deliveries {
+		if delivery.WebhookID == webhookID {
+			deliveries = append(deliveries, copyDelivery(delivery))
+		}
+	}
+	sort.Slice(deliveries, func(i, j int) bool { return deliveries[i].ID > deliveries[j].ID })
+	return deliveries, nil
+}
+
+// EnqueueDeliveries stores new deliveries, ignoring events already enqueued for a webhook
+func (r *WebhookRepository) EnqueueDeliveries(deliveries []models.WebhookDelivery) error {
+	s := r.store
+	return s.write(func() error {
+		for _, delivery := range deliveries {
+			if _, ok := s.webhooks[delivery.WebhookID]; !ok {
+				return fmt.Errorf("delivery violates foreign key: webhook %d does not exist", delivery.WebhookID)
+			}
+		}
+
+		enqueued := make(map[[2]int64]bool)
+		for _, delivery := range s.deliveries {
+			enqueued[[2]int64{int64(delivery.WebhookID), delivery.EventID}] = true
+		}
+		for i := range deliveries {
+			key := [2]int64{int64(deliveries[i].WebhookID), deliveries[i].EventID}
+			if enqueued[key] {
+				continue
+			}
+			enqueued[key] = true
+
+			s.lastDeliveryID++
+			deliveries[i].ID = s.lastDeliveryID
+			deliveries[i].CreatedAt = time.Now()
+			if deliveries[i].Status == "" {
+				deliveries[i].Status = models.DeliveryPending
+			}
+			stored := copyDelivery(&deliveries[i])
+			s.deliveries[stored.ID] = &stored
+		}
+		return nil
+	})
+}
+
+// ClaimDueDeliveries returns pending deliveries whose next attempt is due and
+// pushes it forward by lease, so concurrent workers never pick the same delivery
+func (r *WebhookRepository) ClaimDueDeliveries(limit int, lease time.Duration) ([]models.WebhookDelivery, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	deliveries := []models.WebhookDelivery{}
+	for _, delivery := range s.sortedDeliveries() {
+		if len(deliveries) == limit {
+			break
+		}
+		if delivery.Status != models.DeliveryPending || delivery.NextAttemptAt.After(now) {
+			continue
+		}
+		delivery.NextAttemptAt = now.Add(lease)
+
+		claimed := copyDelivery(delivery)
+		if webhook, ok := s.webhooks[delivery.WebhookID]; ok {
+			stored := copyWebhook(webhook)
+			claimed.Webhook = &stored
+		}
+		deliveries = append(deliveries, claimed)
+	}
+	return deliveries, nil
+}
+
+// UpdateDelivery saves the outcome of a delivery attempt
+func (r *WebhookRepository) UpdateDelivery(delivery *models.WebhookDelivery) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if stored, ok := s.deliveries[delivery.ID]; ok {
+		updated := copyDelivery(delivery)
+		stored.Status = updated.Status
+		stored.Attempts = updated.Attempts
+		stored.ResponseStatus = updated.ResponseStatus
+		stored.LastError = updated.LastError
+		stored.NextAttemptAt = updated.NextAttemptAt
+		stored.DeliveredAt = updated.DeliveredAt
+	}
+	return nil
+}
+
+// sortedDeliveries returns the deliveries ordered by ID
+func (s *Store) sortedDeliveries() []*models.WebhookDelivery {
+	deliveries := make([]*models.WebhookDelivery, 0, len(s.deliveries))
+	for _, delivery := range s.deliveries {
+		deliveries = append(deliveries, delivery)
+	}
+	sort.Slice(deliveries, func(i, j int) bool { return deliveries[i].ID < deliveries[j].ID })
+	return deliveries
+}
@@ -0,0 +1,171 @@
+// Package storage selects the backend the repositories run on: PostgreSQL
+// or SQLite through GORM (package repository) or an in-memory store for
+// local runs and tests (package memory). All of them honor the same
+// semantics, which the suite in package storagetest checks.
+package storage
+
+import (
+	"context"
+	"fmt"
+	"qa-api/internal/badges"
+	"qa-api/internal/config"
+	"qa-api/internal/database"
+	"qa-api/internal/events"
+	"qa-api/internal/graphapi"
+	"qa-api/internal/notify"
+	"qa-api/internal/repository"
+	"qa-api/internal/reputation"
+	"qa-api/internal/service"
+	"qa-api/internal/storage/memory"
+	"qa-api/internal/stream"
+	"qa-api/internal/webhook"
+	"time"
+)
+
+// Storage backends
+const (
+	SQL    = "sql"
+	Memory = "memory"
+)
+
+// QuestionStore is everything the service needs from question storage
+type QuestionStore interface {
+	service.QuestionRepositoryInterface
+	service.DatasetRepositoryInterface
+	graphapi.QuestionReader
+}
+
+// AnswerStore is everything the service needs from answer storage
+type AnswerStore interface {
+	service.AnswerRepositoryInterface
+	graphapi.AnswerReader
+}
+
+// OutboxStore is everything the service needs from the event outbox
+type OutboxStore interface {
+	events.Store
+	stream.EventLoader
+	stream.History
+	reputation.History
+}
+
+// UserStore is everything the service needs from user storage
+type UserStore interface {
+	service.UserRepositoryInterface
+}
+
+// VoteStore is everything the service needs from vote storage
+type VoteStore interface {
+	service.VoteRepositoryInterface
+}
+
+// ReputationStore is everything the service needs from the reputation ledger
+type ReputationStore interface {
+	service.ReputationRepositoryInterface
+	reputation.Store
+}
+
+// BadgeStore is everything the service needs from badge storage
+type BadgeStore interface {
+	service.BadgeRepositoryInterface
+	badges.Store
+}
+
+// NotificationStore is everything the service needs from notification storage
+type NotificationStore interface {
+	service.NotificationRepositoryInterface
+	notify.Store
+}
+
+// WebhookStore is everything the service needs from webhook storage
+type WebhookStore interface {
+	service.WebhookRepositoryInterface
+	webhook.SubscriptionStore
+	webhook.DeliveryStore
+}
+
+// Backend is a complete storage implementation
+type Backend struct {
+	Questions     QuestionStore
+	Answers       AnswerStore
+	Outbox        OutboxStore
+	Webhooks      WebhookStore
+	Users         UserStore
+	Votes         VoteStore
+	Reputation    ReputationStore
+	Badges        BadgeStore
+	Notifications NotificationStore
+
+	feed func(ctx context.Context, broker *stream.Broker) error
+}
+
+// Feed publishes every committed event to broker until ctx is cancelled:
+// through LISTEN/NOTIFY on PostgreSQL, so every replica sees every event, by
+// polling the outbox on SQLite and straight from the store in memory
+func (b Backend) Feed(ctx context.Context, broker *stream.Broker) error {
+	return b.feed(ctx, broker)
+}
+
+// pollInterval is how often the outbox is polled for new events on SQLite
+const pollInterval = 100 * time.Millisecond
+
+// New returns the backend of the given kind. The SQL backend uses the
+// connection opened by database.Init; databaseURL is the URL it was opened
+// with.
+func New(kind, databaseURL string) (Backend, error) {
+	switch kind {
+	case SQL:
+		return NewSQL(databaseURL), nil
+	case Memory:
+		return NewMemory(memory.NewStore()), nil
+	default:
+		return Backend{}, fmt.Errorf("unknown storage backend %q, expected %s or %s", kind, SQL, Memory)
+	}
+}
+
+// NewSQL returns the backend on the database opened by database.Init,
+// PostgreSQL or SQLite
+func NewSQL(databaseURL string) Backend {
+	outbox := repository.NewOutboxRepository()
+	backend := Backend{
+		Questions:     repository.NewQuestionRepository(),
+		Answers:       repository.NewAnswerRepository(),
+		Outbox:        outbox,
+		Webhooks:      repository.NewWebhookRepository(),
+		Users:         repository.NewUserRepository(),
+		Votes:         repository.NewVoteRepository(),
+		Reputation:    repository.NewReputationRepository(),
+		Badges:        repository.NewBadgeRepository(),
+		Notifications: repository.NewNotificationRepository(),
+	}
+	if database.Dialect() == config.DialectSQLite {
+		backend.feed = func(ctx context.Context, broker *stream.Broker) error {
+			return stream.NewPoller(outbox, broker, pollInterval).Run(ctx)
+		}
+	} else {
+		backend.feed = func(ctx context.Context, broker *stream.Broker) error {
+			return stream.NewListener(databaseURL, outbox, broker).Run(ctx)
+		}
+	}
+	return backend
+}
+
+// NewMemory returns a backend keeping its data in store
+func NewMemory(store *memory.Store) Backend {
+	return Backend{
+		Questions:     memory.NewQuestionRepository(store),
+		Answers:       memory.NewAnswerRepository(store),
+		Outbox:        memory.NewOutboxRepository(store),
+		Webhooks:      memory.NewWebhookRepository(store),
+		Users:         memory.NewUserRepository(store),
+		Votes:         memory.NewVoteRepository(store),
+		Reputation:    memory.NewReputationRepository(store),
+		Badges:        memory.NewBadgeRepository(store),
+		Notifications: memory.NewNotificationRepository(store),
+		feed: func(ctx context.Context, broker *stream.Broker) error {
+			store.Subscribe(broker.Publish)
+			<-ctx.Done()
+			return nil
+		},
+	}
+}
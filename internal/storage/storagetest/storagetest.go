@@ -0,0 +1,772 @@
+// Package storagetest is the conformance suite of the storage backends.
+// Every backend runs the same tests, so they cannot drift apart.
+package storagetest
+
+import (
+	"encoding/json"
+	"fmt"
+	"qa-api/internal/events"
+	"qa-api/internal/models"
+	"qa-api/internal/repository"
+	"qa-api/internal/storage"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// Run runs the suite; newBackend returns an empty backend for each test
+func Run(t *testing.T, newBackend func(t *testing.T) storage.Backend) {
+	tests := []struct {
+		name string
+		test func(t *testing.T, b storage.Backend)
+	}{
+		{"auto-increment IDs", testAutoIncrement},
+		{"answers bump their question", testAnswers},
+		{"answers need a question", testAnswerForeignKey},
+		{"delete cascades to answers", testCascadeDelete},
+		{"optimistic locking", testOptimisticLocking},
+		{"restore answer", testRestoreAnswer},
+		{"purge deleted content", testPurge},
+		{"ordering and paging", testOrdering},
+		{"bulk insert", testCreateInBatches},
+		{"outbox", testOutbox},
+		{"webhooks", testWebhooks},
+		{"users", testUsers},
+		{"votes and accepted answers", testVotes},
+		{"reputation ledger", testReputationLedger},
+		{"question views", testViews},
+		{"badges and activity", testBadges},
+		{"notifications and follows", testNotifications},
+		{"concurrent writes", testConcurrentWrites},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.test(t, newBackend(t))
+		})
+	}
+}
+
+func createQuestion(t *testing.T, b storage.Backend, text string) *models.Question {
+	t.Helper()
+	question := &models.Question{Text: text}
+	require.NoError(t, b.Questions.Create(question))
+	return question
+}
+
+func createAnswer(t *testing.T, b storage.Backend, questionID int, userID string) *models.Answer {
+	t.Helper()
+	answer := &models.Answer{QuestionID: questionID, UserID: userID, Text: "answer by " + userID}
+	require.NoError(t, b.Answers.Create(answer))
+	return answer
+}
+
+func ids[T any](list []T, id func(T) int) []int {
+	result := []int{}
+	for _, item := range list {
+		result = append(result, id(item))
+	}
+	return result
+}
+
+func questionID(q models.Question) int { return q.ID }
+func answerID(a models.Answer) int     { return a.ID }
+
+func eventTypes(list []events.Event) []string {
+	result := []string{}
+	for _, event := range list {
+		result = append(result, event.Type)
+	}
+	return result
+}
+
+func testAutoIncrement(t *testing.T, b storage.Backend) {
+	first := createQuestion(t, b, "first")
+	second := createQuestion(t, b, "second")
+	assert.Greater(t, first.ID, 0)
+	assert.Greater(t, second.ID, first.ID)
+	assert.Equal(t, 1, second.Version)
+	assert.False(t, second.CreatedAt.IsZero())
+
+	require.NoError(t, b.Questions.Delete(second.ID, 0))
+	third := createQuestion(t, b, "third")
+	assert.Greater(t, third.ID, second.ID, "IDs of deleted rows are not reused")
+}
+
+func testAnswers(t *testing.T, b storage.Backend) {
+	question := createQuestion(t, b, "question")
+	answer := createAnswer(t, b, question.ID, "alice")
+	assert.Greater(t, answer.ID, 0)
+	assert.Equal(t, 1, answer.Version)
+
+	stored, err := b.Questions.GetByID(question.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, stored.Version, "an answer changes the question representation")
+	assert.Equal(t, 1, stored.AnswerCount)
+	require.Len(t, stored.Answers, 1)
+	assert.Equal(t, "alice", stored.Answers[0].UserID)
+
+	loaded, err := b.Answers.GetByID(answer.ID)
+	require.NoError(t, err)
+	assert.Equal(t, question.ID, loaded.Question.ID, "the question is preloaded")
+	assert.Equal(t, "question", loaded.Question.Text)
+
+	_, err = b.Answers.GetByID(answer.ID + 1000)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	_, err = b.Questions.GetByID(question.ID + 1000)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	exists, err := b.Questions.Exists(question.ID)
+	require.NoError(t, err)
+	assert.True(t, exists)
+	exists, err = b.Questions.Exists(question.ID + 1000)
+	require.NoError(t, err)
+	assert.False(t, exists)
+}
+
+func testAnswerForeignKey(t *testing.T, b storage.Backend) {
+	err := b.Answers.Create(&models.Answer{QuestionID: 999, UserID: "alice", Text: "orphan"})
+	assert.Error(t, err)
+
+	answers, err := b.Answers.GetByUserIDs([]string{"alice"}, 0, 10)
+	require.NoError(t, err)
+	assert.Empty(t, answers, "a failed write leaves nothing behind")
+}
+
+func testCascadeDelete(t *testing.T, b storage.Backend) {
+	question := createQuestion(t, b, "question")
+	kept := createAnswer(t, b, question.ID, "alice")
+	earlier := createAnswer(t, b, question.ID, "bob")
+	require.NoError(t, b.Answers.Delete(earlier.ID, 0))
+
+	require.NoError(t, b.Questions.Delete(question.ID, 0))
+	_, err := b.Questions.GetByID(question.ID)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	_, err = b.Answers.GetByID(kept.ID)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound, "answers are deleted with their question")
+	answers, err := b.Answers.GetByQuestionIDs([]int{question.ID}, 0, 10)
+	require.NoError(t, err)
+	assert.Empty(t, answers)
+	exists, err := b.Questions.Exists(question.ID)
+	require.NoError(t, err)
+	assert.False(t, exists)
+	assert.ErrorIs(t, b.Questions.Delete(question.ID, 0), gorm.ErrRecordNotFound)
+
+	restored, err := b.Questions.Restore(question.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []int{kept.ID}, ids(restored.Answers, answerID), "answers deleted before the question stay deleted")
+	assert.Equal(t, 1, restored.AnswerCount)
+	_, err = b.Questions.Restore(question.ID)
+	assert.ErrorIs(t, err, repository.ErrNotDeleted)
+	_, err = b.Questions.Restore(question.ID + 1000)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	updated, err := b.Questions.RecountAnswers()
+	require.NoError(t, err)
+	assert.Zero(t, updated, "the counts were kept up to date")
+}
+
+func testOptimisticLocking(t *testing.T, b storage.Backend) {
+	question := createQuestion(t, b, "question")
+	answer := createAnswer(t, b, question.ID, "alice")
+
+	assert.ErrorIs(t, b.Answers.Delete(answer.ID, 2), repository.ErrVersionMismatch)
+	require.NoError(t, b.Answers.Delete(answer.ID, 1))
+	assert.ErrorIs(t, b.Answers.Delete(answer.ID, 0), gorm.ErrRecordNotFound)
+
+	assert.ErrorIs(t, b.Questions.Delete(question.ID, 1), repository.ErrVersionMismatch)
+	require.NoError(t, b.Questions.Delete(question.ID, 3))
+}
+
+func testRestoreAnswer(t *testing.T, b storage.Backend) {
+	question := createQuestion(t, b, "question")
+	answer := createAnswer(t, b, question.ID, "alice")
+
+	_, err := b.Answers.Restore(answer.ID)
+	assert.ErrorIs(t, err, repository.ErrNotDeleted)
+
+	require.NoError(t, b.Answers.Delete(answer.ID, 0))
+	restored, err := b.Answers.Restore(answer.ID)
+	require.NoError(t, err)
+	assert.Equal(t, answer.ID, restored.ID)
+	stored, err := b.Questions.GetByID(question.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, stored.AnswerCount)
+	assert.Equal(t, 4, stored.Version)
+
+	require.NoError(t, b.Answers.Delete(answer.ID, 0))
+	require.NoError(t, b.Questions.Delete(question.ID, 0))
+	_, err = b.Answers.Restore(answer.ID)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound, "the question is deleted")
+	_, err = b.Answers.Restore(answer.ID + 1000)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
+
+func testPurge(t *testing.T, b storage.Backend) {
+	question := createQuestion(t, b, "question")
+	accepted := createAnswer(t, b, question.ID, "alice")
+	kept := createAnswer(t, b, question.ID, "bob")
+	require.NoError(t, b.Votes.Accept(accepted.ID))
+	require.NoError(t, b.Answers.Delete(accepted.ID, 0))
+	deleted := createQuestion(t, b, "deleted")
+	createAnswer(t, b, deleted.ID, "carol")
+	require.NoError(t, b.Notifications.Follow(deleted.ID, "dave"))
+	require.NoError(t, b.Questions.Delete(deleted.ID, 0))
+
+	questions, answers, err := b.Questions.Purge(time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, [2]int64{0, 0}, [2]int64{questions, answers}, "recent deletes are kept")
+
+	questions, answers, err = b.Questions.Purge(time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, [2]int64{1, 2}, [2]int64{questions, answers})
+	_, err = b.Questions.Restore(deleted.ID)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	_, err = b.Answers.Restore(accepted.ID)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	followers, err := b.Notifications.Followers(deleted.ID)
+	require.NoError(t, err)
+	assert.Empty(t, followers)
+
+	stored, err := b.Questions.GetByID(question.ID)
+	require.NoError(t, err)
+	assert.Nil(t, stored.AcceptedAnswerID, "the purged answer is no longer accepted")
+	assert.Equal(t, []int{kept.ID}, ids(stored.Answers, answerID))
+	history, err := b.Outbox.EventsAfter(0, 100)
+	require.NoError(t, err)
+	assert.Equal(t, events.QuestionUpdated, eventTypes(history)[len(history)-1])
+}
+
+func testOrdering(t *testing.T, b storage.Backend) {
+	var questionIDs []int
+	for i := 0; i < 5; i++ {
+		questionIDs = append(questionIDs, createQuestion(t, b, fmt.Sprintf("question %d", i)).ID)
+	}
+	// Answers are created out of question order
+	var answerIDs []int
+	for _, i := range []int{3, 0, 3, 1} {
+		answerIDs = append(answerIDs, createAnswer(t, b, questionIDs[i], fmt.Sprintf("user-%d", i)).ID)
+	}
+	require.NoError(t, b.Questions.Delete(questionIDs[2], 0))
+
+	page, err := b.Questions.GetPage(0, 2)
+	require.NoError(t, err)
+	assert.Equal(t, questionIDs[:2], ids(page, questionID))
+	page, err = b.Questions.GetPage(questionIDs[1], 2)
+	require.NoError(t, err)
+	assert.Equal(t, []int{questionIDs[3], questionIDs[4]}, ids(page, questionID), "deleted questions are skipped")
+
+	withAnswers, err := b.Questions.GetPageWithAnswers(questionIDs[2], 10)
+	require.NoError(t, err)
+	require.Len(t, withAnswers, 2)
+	assert.Equal(t, []int{answerIDs[0], answerIDs[2]}, ids(withAnswers[0].Answers, answerID))
+	assert.Empty(t, withAnswers[1].Answers)
+
+	all, err := b.Questions.GetAll()
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []int{questionIDs[0], questionIDs[1], questionIDs[3], questionIDs[4]}, ids(all, questionID))
+	allWithAnswers, err := b.Questions.GetAllWithAnswers()
+	require.NoError(t, err)
+	assert.Len(t, allWithAnswers, 4)
+
+	byIDs, err := b.Questions.GetByIDs([]int{questionIDs[4], questionIDs[2], questionIDs[0]})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []int{questionIDs[0], questionIDs[4]}, ids(byIDs, questionID))
+
+	answers, err := b.Answers.GetByQuestionIDs([]int{questionIDs[3], questionIDs[0]}, 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, answerIDs[:3], ids(answers, answerID))
+	answers, err = b.Answers.GetByQuestionIDs([]int{questionIDs[3], questionIDs[0]}, 0, 1)
+	require.NoError(t, err)
+	assert.Equal(t, answerIDs[:2], ids(answers, answerID), "the limit applies per question")
+	answers, err = b.Answers.GetByQuestionIDs([]int{questionIDs[3], questionIDs[0]}, answerIDs[0], 1)
+	require.NoError(t, err)
+	assert.Equal(t, answerIDs[1:3], ids(answers, answerID))
+	answers, err = b.Answers.GetByUserIDs([]string{"user-3", "user-1"}, 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []int{answerIDs[0], answerIDs[2], answerIDs[3]}, ids(answers, answerID))
+	answers, err = b.Answers.GetByUserIDs([]string{"user-3", "user-1"}, 0, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []int{answerIDs[0], answerIDs[3]}, ids(answers, answerID), "the limit applies per user")
+}
+
+func testCreateInBatches(t *testing.T, b storage.Backend) {
+	questions := []models.Question{
+		{Text: "first", Answers: []models.Answer{{UserID: "alice", Text: "a"}, {UserID: "bob", Text: "b"}}},
+		{Text: "second"},
+		{Text: "third", Answers: []models.Answer{{UserID: "carol", Text: "c"}}},
+	}
+	require.NoError(t, b.Questions.CreateInBatches(questions, 2))
+	for _, question := range questions {
+		assert.Greater(t, question.ID, 0)
+		for _, answer := range question.Answers {
+			assert.Greater(t, answer.ID, 0)
+			assert.Equal(t, question.ID, answer.QuestionID)
+		}
+	}
+
+	stored, err := b.Questions.GetPageWithAnswers(0, 10)
+	require.NoError(t, err)
+	require.Len(t, stored, 3)
+	assert.Equal(t, 2, stored[0].AnswerCount)
+	assert.Len(t, stored[0].Answers, 2)
+	assert.Equal(t, 0, stored[1].AnswerCount)
+	assert.Equal(t, 1, stored[2].AnswerCount)
+
+	claimed, err := b.Outbox.ClaimDue(100, time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		events.QuestionCreated, events.AnswerCreated, events.AnswerCreated,
+		events.QuestionCreated,
+		events.QuestionCreated, events.AnswerCreated,
+	}, eventTypes(claimed))
+	assert.Equal(t, questions[2].Answers[0].ID, claimed[5].AggregateID)
+}
+
+func testOutbox(t *testing.T, b storage.Backend) {
+	question := createQuestion(t, b, "question")
+	answer := createAnswer(t, b, question.ID, "alice")
+	other := createQuestion(t, b, "other")
+	require.NoError(t, b.Answers.Delete(answer.ID, 0))
+
+	history, err := b.Outbox.EventsSince(question.ID, 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []string{events.QuestionCreated, events.AnswerCreated, events.AnswerDeleted}, eventTypes(history))
+	assert.Equal(t, "alice", history[1].UserID())
+	since, err := b.Outbox.EventsSince(question.ID, history[0].ID, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{events.AnswerCreated}, eventTypes(since))
+
+	event, err := b.Outbox.GetByID(history[1].ID)
+	require.NoError(t, err)
+	assert.Equal(t, answer.ID, event.AggregateID)
+	assert.Equal(t, question.ID, event.QuestionID)
+	_, err = b.Outbox.GetByID(history[2].ID + 1000)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	claimed, err := b.Outbox.ClaimDue(3, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, claimed, 3)
+	assert.Equal(t, history[0].ID, claimed[0].ID, "oldest first")
+	rest, err := b.Outbox.ClaimDue(10, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, rest, 1, "claimed events are leased")
+	assert.Equal(t, history[2].ID, rest[0].ID)
+	assert.Equal(t, other.ID, claimed[2].QuestionID)
+
+	require.NoError(t, b.Outbox.MarkDispatched(claimed[0].ID))
+	require.NoError(t, b.Outbox.MarkFailed(claimed[1].ID, 1, time.Now().Add(-time.Second), "sink down"))
+	due, err := b.Outbox.ClaimDue(10, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, due, 1)
+	assert.Equal(t, claimed[1].ID, due[0].ID)
+	assert.Equal(t, 1, due[0].Attempts)
+}
+
+func testWebhooks(t *testing.T, b storage.Backend) {
+	question := createQuestion(t, b, "question")
+	all := &models.Webhook{URL: "https://example.com/all", Secret: "s", EventTypes: models.StringList{events.AnswerCreated}}
+	require.NoError(t, b.Webhooks.Create(all))
+	otherID := question.ID + 1000
+	filtered := &models.Webhook{URL: "https://example.com/other", Secret: "s", EventTypes: models.StringList{events.AnswerCreated, events.AnswerDeleted}, QuestionID: &otherID}
+	require.NoError(t, b.Webhooks.Create(filtered))
+
+	stored, err := b.Webhooks.GetByID(filtered.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.StringList{events.AnswerCreated, events.AnswerDeleted}, stored.EventTypes)
+	require.NotNil(t, stored.QuestionID)
+	assert.Equal(t, otherID, *stored.QuestionID)
+	_, err = b.Webhooks.GetByID(filtered.ID + 1000)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	matching, err := b.Webhooks.FindForQuestion(question.ID)
+	require.NoError(t, err)
+	require.Len(t, matching, 1)
+	assert.Equal(t, all.ID, matching[0].ID)
+
+	deliveries := []models.WebhookDelivery{
+		{WebhookID: all.ID, EventID: 1, EventType: events.AnswerCreated, Payload: `{"id":1}`, NextAttemptAt: time.Now()},
+		{WebhookID: all.ID, EventID: 2, EventType: events.AnswerCreated, Payload: `{"id":2}`, NextAttemptAt: time.Now()},
+	}
+	require.NoError(t, b.Webhooks.EnqueueDeliveries(deliveries))
+	duplicate := []models.WebhookDelivery{{WebhookID: all.ID, EventID: 1, EventType: events.AnswerCreated, Payload: `{"id":1}`, NextAttemptAt: time.Now()}}
+	require.NoError(t, b.Webhooks.EnqueueDeliveries(duplicate), "an event is enqueued once per webhook")
+
+	listed, err := b.Webhooks.GetDeliveries(all.ID)
+	require.NoError(t, err)
+	require.Len(t, listed, 2)
+	assert.Greater(t, listed[0].ID, listed[1].ID, "newest first")
+	assert.Equal(t, models.DeliveryPending, listed[0].Status)
+
+	claimed, err := b.Webhooks.ClaimDueDeliveries(1, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	require.NotNil(t, claimed[0].Webhook, "the webhook is attached")
+	assert.Equal(t, all.URL, claimed[0].Webhook.URL)
+
+	delivered := claimed[0]
+	now := time.Now()
+	delivered.Status = models.DeliveryDelivered
+	delivered.Attempts = 1
+	delivered.ResponseStatus = 200
+	delivered.DeliveredAt = &now
+	require.NoError(t, b.Webhooks.UpdateDelivery(&delivered))
+	rest, err := b.Webhooks.ClaimDueDeliveries(10, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, rest, 1)
+	assert.NotEqual(t, delivered.ID, rest[0].ID)
+
+	require.NoError(t, b.Webhooks.Delete(all.ID))
+	_, err = b.Webhooks.GetByID(all.ID)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	listed, err = b.Webhooks.GetDeliveries(all.ID)
+	require.NoError(t, err)
+	assert.Empty(t, listed, "deliveries are deleted with their webhook")
+}
+
+func testUsers(t *testing.T, b storage.Backend) {
+	question := &models.Question{UserID: "alice", Text: "question"}
+	require.NoError(t, b.Questions.Create(question))
+	answer := createAnswer(t, b, question.ID, "bob")
+	createAnswer(t, b, question.ID, "bob")
+
+	alice, err := b.Users.GetByID("alice")
+	require.NoError(t, err, "authors are created on their first contribution")
+	assert.False(t, alice.JoinedAt.IsZero())
+	assert.Empty(t, alice.DisplayName)
+	_, err = b.Users.GetByID("nobody")
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	bob, err := b.Users.GetByID("bob")
+	require.NoError(t, err)
+	profile := &models.User{ID: "bob", DisplayName: "Bob", Bio: "Gopher"}
+	require.NoError(t, b.Users.Save(profile))
+	assert.WithinDuration(t, bob.JoinedAt, profile.JoinedAt, time.Second, "the join date is kept")
+	stored, err := b.Users.GetByID("bob")
+	require.NoError(t, err)
+	assert.Equal(t, "Bob", stored.DisplayName)
+	assert.Equal(t, "Gopher", stored.Bio)
+	require.NoError(t, b.Users.Save(&models.User{ID: "carol", AvatarURL: "https://example.com/carol.png"}))
+	carol, err := b.Users.GetByID("carol")
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/carol.png", carol.AvatarURL)
+	assert.False(t, carol.JoinedAt.IsZero())
+
+	questions, err := b.Users.GetQuestions("alice")
+	require.NoError(t, err)
+	assert.Equal(t, []int{question.ID}, ids(questions, questionID))
+	require.NoError(t, b.Answers.Delete(answer.ID, 0))
+	answers, err := b.Users.GetAnswers("bob")
+	require.NoError(t, err)
+	assert.Len(t, answers, 1, "deleted answers are not listed")
+
+	require.NoError(t, b.Questions.CreateInBatches([]models.Question{
+		{UserID: "dave", Text: "imported", Answers: []models.Answer{{UserID: "erin", Text: "imported answer"}}},
+	}, 10))
+	for _, id := range []string{"dave", "erin"} {
+		_, err := b.Users.GetByID(id)
+		assert.NoError(t, err, "imports create their authors")
+	}
+}
+
+func testVotes(t *testing.T, b storage.Backend) {
+	question := &models.Question{UserID: "alice", Text: "question"}
+	require.NoError(t, b.Questions.Create(question))
+	first := createAnswer(t, b, question.ID, "bob")
+	second := createAnswer(t, b, question.ID, "carol")
+	history, err := b.Outbox.EventsAfter(0, 100)
+	require.NoError(t, err)
+	last := history[len(history)-1].ID
+
+	require.NoError(t, b.Votes.Cast(first.ID, "dave", 1))
+	require.NoError(t, b.Votes.Cast(first.ID, "dave", 1), "the same vote again changes nothing")
+	require.NoError(t, b.Votes.Cast(first.ID, "dave", -1))
+	require.NoError(t, b.Votes.Cast(first.ID, "dave", 0))
+	require.NoError(t, b.Votes.Cast(first.ID, "dave", 0))
+	assert.ErrorIs(t, b.Votes.Cast(first.ID+1000, "dave", 1), gorm.ErrRecordNotFound)
+	_, err = b.Users.GetByID("dave")
+	assert.NoError(t, err, "voters become users")
+
+	votes, err := b.Outbox.EventsAfter(last, 10)
+	require.NoError(t, err)
+	require.Equal(t, []string{events.VoteCast, events.VoteCast, events.VoteCast}, eventTypes(votes))
+	var payloads []events.VotePayload
+	for _, event := range votes {
+		var payload events.VotePayload
+		require.NoError(t, json.Unmarshal(event.Payload, &payload))
+		payloads = append(payloads, payload)
+	}
+	assert.Equal(t, events.VotePayload{AnswerID: first.ID, QuestionID: question.ID, UserID: "bob", VoterID: "dave", Value: 1}, payloads[0])
+	assert.Equal(t, [2]int{-1, 1}, [2]int{payloads[1].Value, payloads[1].Previous})
+	assert.Equal(t, [2]int{0, -1}, [2]int{payloads[2].Value, payloads[2].Previous})
+	assert.Equal(t, "bob", votes[0].UserID(), "vote events belong to the answer author")
+	last = votes[2].ID
+
+	require.NoError(t, b.Votes.Accept(first.ID))
+	stored, err := b.Questions.GetByID(question.ID)
+	require.NoError(t, err)
+	require.NotNil(t, stored.AcceptedAnswerID)
+	assert.Equal(t, first.ID, *stored.AcceptedAnswerID)
+	assert.Equal(t, 4, stored.Version, "accepting changes the question representation")
+	require.NoError(t, b.Votes.Accept(first.ID), "accepting the accepted answer changes nothing")
+	require.NoError(t, b.Votes.Accept(second.ID))
+	assert.ErrorIs(t, b.Votes.Accept(second.ID+1000), gorm.ErrRecordNotFound)
+
+	accepts, err := b.Outbox.EventsAfter(last, 10)
+	require.NoError(t, err)
+	require.Equal(t, []string{events.AnswerAccepted, events.AnswerAccepted}, eventTypes(accepts))
+	var payload events.AcceptPayload
+	require.NoError(t, json.Unmarshal(accepts[1].Payload, &payload))
+	assert.Equal(t, events.AcceptPayload{
+		AnswerID: second.ID, QuestionID: question.ID, UserID: "carol", AcceptedBy: "alice",
+		PreviousAnswerID: first.ID, PreviousUserID: "bob",
+	}, payload)
+}
+
+func testReputationLedger(t *testing.T, b storage.Backend) {
+	require.NoError(t, b.Users.Save(&models.User{ID: "bob", DisplayName: "Bob"}))
+	entries := []models.ReputationEntry{
+		{UserID: "bob", EventID: 1, Reason: "vote", Delta: 10},
+		{UserID: "bob", EventID: 2, Reason: "accepted", Delta: 15},
+		{UserID: "alice", EventID: 2, Reason: "accept", Delta: 2},
+		{UserID: "carol", EventID: 3, Reason: "vote", Delta: 2},
+	}
+	require.NoError(t, b.Reputation.Record(entries))
+	require.NoError(t, b.Reputation.Record(entries[:1]), "recording an entry again is ignored")
+	require.NoError(t, b.Reputation.Record(nil))
+
+	total, err := b.Reputation.Total("bob")
+	require.NoError(t, err)
+	assert.Equal(t, 25, total)
+	total, err = b.Reputation.Total("nobody")
+	require.NoError(t, err)
+	assert.Zero(t, total)
+
+	standings, err := b.Reputation.Leaderboard(2)
+	require.NoError(t, err)
+	assert.Equal(t, []models.Standing{
+		{UserID: "bob", DisplayName: "Bob", Reputation: 25},
+		{UserID: "alice", Reputation: 2},
+	}, standings, "ties are ordered by user ID")
+
+	rebuilt := []models.ReputationEntry{{UserID: "alice", EventID: 1, Reason: "vote", Delta: 10}}
+	require.NoError(t, b.Reputation.Replace(rebuilt, 2))
+	standings, err = b.Reputation.Leaderboard(10)
+	require.NoError(t, err)
+	assert.Equal(t, []models.Standing{
+		{UserID: "alice", Reputation: 10},
+		{UserID: "carol", Reputation: 2},
+	}, standings, "entries of later events are kept")
+
+	require.NoError(t, b.Reputation.Replace(nil, 3))
+	standings, err = b.Reputation.Leaderboard(10)
+	require.NoError(t, err)
+	assert.Empty(t, standings)
+}
+
+func testViews(t *testing.T, b storage.Backend) {
+	question := &models.Question{UserID: "alice", Text: "question"}
+	require.NoError(t, b.Questions.Create(question))
+	other := createQuestion(t, b, "other")
+	milestones := []int{2, 10}
+	require.NoError(t, b.Questions.RecordViews(map[int]int{question.ID: 1, other.ID: 1, other.ID + 1000: 1}, milestones))
+	require.NoError(t, b.Questions.RecordViews(map[int]int{question.ID: 2, other.ID: 1}, milestones), "unknown questions are skipped")
+	require.NoError(t, b.Questions.RecordViews(nil, milestones))
+
+	stored, err := b.Questions.GetByID(question.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 3, stored.ViewCount)
+	assert.Equal(t, 1, stored.Version, "views do not change the version")
+
+	history, err := b.Outbox.EventsSince(question.ID, 0, 10)
+	require.NoError(t, err)
+	require.Equal(t, []string{events.QuestionCreated, events.QuestionViewed}, eventTypes(history), "only milestones are events")
+	var payload events.ViewPayload
+	require.NoError(t, json.Unmarshal(history[1].Payload, &payload))
+	assert.Equal(t, events.ViewPayload{QuestionID: question.ID, UserID: "alice", ViewCount: 3}, payload, "a batch passing a milestone records the new count")
+	history, err = b.Outbox.EventsSince(other.ID, 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []string{events.QuestionCreated, events.QuestionViewed}, eventTypes(history), "reaching a milestone exactly counts")
+
+	require.NoError(t, b.Questions.Delete(question.ID, 0))
+	require.NoError(t, b.Questions.RecordViews(map[int]int{question.ID: 10}, milestones))
+	stored, err = b.Questions.Restore(question.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 3, stored.ViewCount, "deleted questions are not counted")
+}
+
+func testBadges(t *testing.T, b storage.Backend) {
+	popular := &models.Question{UserID: "alice", Text: "popular"}
+	require.NoError(t, b.Questions.Create(popular))
+	other := &models.Question{UserID: "alice", Text: "other"}
+	require.NoError(t, b.Questions.Create(other))
+	require.NoError(t, b.Questions.RecordViews(map[int]int{popular.ID: 3, other.ID: 1}, nil))
+	accepted := createAnswer(t, b, popular.ID, "bob")
+	createAnswer(t, b, popular.ID, "carol")
+	downvoted := createAnswer(t, b, other.ID, "bob")
+	require.NoError(t, b.Votes.Cast(accepted.ID, "carol", 1))
+	require.NoError(t, b.Votes.Cast(accepted.ID, "dave", 1))
+	require.NoError(t, b.Votes.Cast(downvoted.ID, "dave", -1))
+	require.NoError(t, b.Votes.Accept(accepted.ID))
+	require.NoError(t, b.Reputation.Record([]models.ReputationEntry{{UserID: "bob", EventID: 1, Reason: "vote", Delta: 25}}))
+
+	activity, err := b.Badges.Activity("alice")
+	require.NoError(t, err)
+	assert.Equal(t, models.Activity{Questions: 2, QuestionViews: 3}, activity)
+	activity, err = b.Badges.Activity("bob")
+	require.NoError(t, err)
+	assert.Equal(t, models.Activity{Answers: 2, AcceptedAnswers: 1, AnswerScore: 2, Reputation: 25}, activity)
+	activity, err = b.Badges.Activity("dave")
+	require.NoError(t, err)
+	assert.Equal(t, models.Activity{VotesCast: 2}, activity)
+
+	require.NoError(t, b.Answers.Delete(accepted.ID, 0))
+	activity, err = b.Badges.Activity("bob")
+	require.NoError(t, err)
+	assert.Equal(t, models.Activity{Answers: 1, Reputation: 25}, activity, "deleted answers and negative scores do not count")
+
+	first := time.Now().Add(-time.Hour).Truncate(time.Second)
+	awards := []models.BadgeAward{
+		{UserID: "bob", Badge: "solver", Tier: 1, AwardedAt: first.Add(time.Minute)},
+		{UserID: "bob", Badge: "first_answer", Tier: 1, AwardedAt: first},
+		{UserID: "carol", Badge: "first_answer", Tier: 1, AwardedAt: first},
+	}
+	require.NoError(t, b.Badges.Award(awards))
+	require.NoError(t, b.Badges.Award([]models.BadgeAward{{UserID: "bob", Badge: "solver", Tier: 1, AwardedAt: time.Now()}}),
+		"awarding a badge again is ignored")
+	require.NoError(t, b.Badges.Award(nil))
+	held, err := b.Badges.Awards("bob")
+	require.NoError(t, err)
+	require.Len(t, held, 2)
+	assert.Equal(t, "first_answer", held[0].Badge)
+	assert.Equal(t, "solver", held[1].Badge)
+	assert.WithinDuration(t, first.Add(time.Minute), held[1].AwardedAt, time.Second)
+	held, err = b.Badges.Awards("nobody")
+	require.NoError(t, err)
+	assert.Empty(t, held)
+
+	userIDs, err := b.Badges.UserIDs("", 2)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"alice", "bob"}, userIDs)
+	userIDs, err = b.Badges.UserIDs("bob", 10)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"carol", "dave"}, userIDs)
+}
+
+func testNotifications(t *testing.T, b storage.Backend) {
+	question := &models.Question{UserID: "alice", Text: "question"}
+	require.NoError(t, b.Questions.Create(question))
+	anonymous := createQuestion(t, b, "anonymous")
+
+	author, err := b.Notifications.QuestionAuthor(question.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "alice", author)
+	author, err = b.Notifications.QuestionAuthor(anonymous.ID)
+	require.NoError(t, err)
+	assert.Empty(t, author)
+
+	require.NoError(t, b.Notifications.Follow(question.ID, "carol"))
+	require.NoError(t, b.Notifications.Follow(question.ID, "bob"))
+	require.NoError(t, b.Notifications.Follow(question.ID, "bob"), "following again changes nothing")
+	assert.ErrorIs(t, b.Notifications.Follow(question.ID+1000, "bob"), gorm.ErrRecordNotFound)
+	followers, err := b.Notifications.Followers(question.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"bob", "carol"}, followers)
+	_, err = b.Users.GetByID("carol")
+	assert.NoError(t, err, "followers become users")
+	require.NoError(t, b.Notifications.Unfollow(question.ID, "carol"))
+	require.NoError(t, b.Notifications.Unfollow(question.ID, "carol"))
+	followers, err = b.Notifications.Followers(question.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"bob"}, followers)
+
+	require.NoError(t, b.Notifications.SavePreferences("bob", map[string]bool{"upvote": false, "answer": true}))
+	require.NoError(t, b.Notifications.SavePreferences("bob", map[string]bool{"answer": false}))
+	preferences, err := b.Notifications.GetPreferences("bob")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]bool{"upvote": false, "answer": false}, preferences)
+	muted, err := b.Notifications.Muted("upvote", []string{"alice", "bob"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"bob"}, muted)
+	preferences, err = b.Notifications.GetPreferences("nobody")
+	require.NoError(t, err)
+	assert.Empty(t, preferences)
+
+	created := time.Now().Add(-time.Hour).Truncate(time.Second)
+	notifications := []models.Notification{
+		{UserID: "alice", EventID: 1, Kind: "answer", QuestionID: question.ID, AnswerID: 5, ActorID: "bob", CreatedAt: created},
+		{UserID: "bob", EventID: 1, Kind: "followed_answer", QuestionID: question.ID, AnswerID: 5, ActorID: "bob", CreatedAt: created},
+		{UserID: "alice", EventID: 2, Kind: "upvote", QuestionID: question.ID, AnswerID: 5, CreatedAt: created},
+		{UserID: "alice", EventID: 3, Kind: "accepted", QuestionID: question.ID, CreatedAt: created},
+	}
+	require.NoError(t, b.Notifications.Notify(notifications))
+	require.NoError(t, b.Notifications.Notify(notifications[:1]), "notifying about an event again is ignored")
+	require.NoError(t, b.Notifications.Notify(nil))
+
+	inbox, err := b.Notifications.List("alice", 0, 10)
+	require.NoError(t, err)
+	require.Len(t, inbox, 3)
+	assert.Equal(t, []string{"accepted", "upvote", "answer"}, []string{inbox[0].Kind, inbox[1].Kind, inbox[2].Kind}, "newest first")
+	assert.Equal(t, "bob", inbox[2].ActorID)
+	assert.Equal(t, 5, inbox[2].AnswerID)
+	assert.WithinDuration(t, created, inbox[2].CreatedAt, time.Second)
+	page, err := b.Notifications.List("alice", inbox[0].ID, 1)
+	require.NoError(t, err)
+	require.Len(t, page, 1)
+	assert.Equal(t, inbox[1].ID, page[0].ID)
+
+	unread, err := b.Notifications.UnreadCount("alice")
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), unread)
+	require.NoError(t, b.Notifications.MarkRead("alice", inbox[2].ID))
+	require.NoError(t, b.Notifications.MarkRead("alice", inbox[2].ID), "marking a read notification again is fine")
+	assert.ErrorIs(t, b.Notifications.MarkRead("bob", inbox[1].ID), gorm.ErrRecordNotFound, "other users' notifications are not found")
+	assert.ErrorIs(t, b.Notifications.MarkRead("alice", inbox[0].ID+1000), gorm.ErrRecordNotFound)
+	unread, err = b.Notifications.UnreadCount("alice")
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), unread)
+
+	marked, err := b.Notifications.MarkAllRead("alice")
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), marked)
+	inbox, err = b.Notifications.List("alice", 0, 10)
+	require.NoError(t, err)
+	for _, notification := range inbox {
+		assert.NotNil(t, notification.ReadAt)
+	}
+	unread, err = b.Notifications.UnreadCount("bob")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), unread, "marking all read only touches the user's own")
+}
+
+func testConcurrentWrites(t *testing.T, b storage.Backend) {
+	question := createQuestion(t, b, "question")
+
+	const writers = 20
+	var wg sync.WaitGroup
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			answer := &models.Answer{QuestionID: question.ID, UserID: fmt.Sprintf("user-%d", i), Text: "concurrent"}
+			assert.NoError(t, b.Answers.Create(answer))
+		}(i)
+	}
+	wg.Wait()
+
+	stored, err := b.Questions.GetByID(question.ID)
+	require.NoError(t, err)
+	assert.Equal(t, writers, stored.AnswerCount)
+	assert.Equal(t, writers+1, stored.Version)
+	assert.Len(t, stored.Answers, writers)
+
+	history, err := b.Outbox.EventsSince(question.ID, 0, 100)
+	require.NoError(t, err)
+	assert.Len(t, history, writers+1)
+	for i := 1; i < len(history); i++ {
+		assert.Greater(t, history[i].ID, history[i-1].ID)
+	}
+}
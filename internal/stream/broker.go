@@ -0,0 +1,115 @@
+package stream
+
+import (
+	"qa-api/internal/events"
+	"sync"
+)
+
+// Subscription receives the events matched by its filter
+type Subscription struct {
+	events chan events.Event
+	gaps   chan struct{}
+	match  func(events.Event) bool
+	closed bool
+}
+
+// Events returns the channel of matched events. The channel is closed when the
+// subscriber falls behind or unsubscribes; SSE clients then reconnect and
+// resume from the last event ID they saw.
+func (s *Subscription) Events() <-chan events.Event {
+	return s.events
+}
+
+// Gaps receives a value when events may have been missed upstream, as while
+// the LISTEN connection was down. Subscribers catch up from the outbox or
+// treat everything as changed.
+func (s *Subscription) Gaps() <-chan struct{} {
+	return s.gaps
+}
+
+// Broker fans live events out to subscribers in this process
+type Broker struct {
+	mu   sync.RWMutex
+	subs map[*Subscription]struct{}
+}
+
+// NewBroker creates a new Broker
+func NewBroker() *Broker {
+	return &Broker{subs: make(map[*Subscription]struct{})}
+}
+
+// Subscribe registers a subscriber for the events accepted by match
+func (b *Broker) Subscribe(match func(events.Event) bool, buffer int) *Subscription {
+	sub := &Subscription{
+		events: make(chan events.Event, buffer),
+		gaps:   make(chan struct{}, 1),
+		match:  match,
+	}
+
+	b.mu.Lock()
+	b.subs[sub] = struct{}{}
+	b.mu.Unlock()
+
+	return sub
+}
+
+// Unsubscribe removes a subscriber and closes its channel
+func (b *Broker) Unsubscribe(sub *Subscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.remove(sub)
+}
+
+// Publish delivers an event to every matching subscriber without blocking.
+// Subscribers whose buffer is full are dropped.
+func (b *Broker) Publish(event events.Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for sub := range b.subs {
+		if !sub.match(event) {
+			continue
+		}
+		select {
+		case sub.events <- event:
+		default:
+			b.remove(sub)
+		}
+	}
+}
+
+// Gap tells every subscriber that events may have been missed. Pending
+// signals are not repeated, one catch-up covers them all.
+func (b *Broker) Gap() {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for sub := range b.subs {
+		select {
+		case sub.gaps <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (b *Broker) remove(sub *Subscription) {
+	if sub.closed {
+		return
+	}
+	sub.closed = true
+	delete(b.subs, sub)
+	close(sub.events)
+}
+
+// ForQuestion matches answer events of a single question
+func ForQuestion(questionID int) func(events.Event) bool {
+	return func(event events.Event) bool {
+		return event.QuestionID == questionID &&
+			(event.Type == events.AnswerCreated || event.Type == events.AnswerDeleted)
+	}
+}
+
+// History provides past events for resuming streams
+type History interface {
+	EventsSince(questionID int, afterID int64, limit int) ([]events.Event, error)
+}
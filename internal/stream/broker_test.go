@@ -0,0 +1,50 @@
+package stream
+
+import (
+	"qa-api/internal/events"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBroker_Publish(t *testing.T) {
+	broker := NewBroker()
+	sub := broker.Subscribe(ForQuestion(1), 4)
+	defer broker.Unsubscribe(sub)
+
+	broker.Publish(events.Event{ID: 1, Type: events.AnswerCreated, QuestionID: 2})
+	broker.Publish(events.Event{ID: 2, Type: events.QuestionCreated, QuestionID: 1})
+	broker.Publish(events.Event{ID: 3, Type: events.AnswerCreated, QuestionID: 1})
+
+	assert.Equal(t, int64(3), (<-sub.Events()).ID)
+	assert.Len(t, sub.Events(), 0)
+}
+
+func TestBroker_Gap(t *testing.T) {
+	broker := NewBroker()
+	sub := broker.Subscribe(ForQuestion(1), 4)
+	defer broker.Unsubscribe(sub)
+
+	broker.Gap()
+	broker.Gap()
+	assert.Len(t, sub.Gaps(), 1, "pending gaps are signalled once")
+	<-sub.Gaps()
+	assert.Len(t, sub.Events(), 0)
+}
+
+func TestBroker_DropsSlowSubscribers(t *testing.T) {
+	broker := NewBroker()
+	sub := broker.Subscribe(func(events.Event) bool { return true }, 1)
+
+	broker.Publish(events.Event{ID: 1})
+	broker.Publish(events.Event{ID: 2})
+
+	event, ok := <-sub.Events()
+	assert.True(t, ok)
+	assert.Equal(t, int64(1), event.ID)
+	_, ok = <-sub.Events()
+	assert.False(t, ok)
+
+	// Unsubscribing a dropped subscriber is a no-op
+	broker.Unsubscribe(sub)
+}
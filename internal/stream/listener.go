@@ -0,0 +1,82 @@
+package stream
+
+import (
+	"context"
+	"log"
+	"qa-api/internal/events"
+	"strconv"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// EventLoader loads committed events by ID
+type EventLoader interface {
+	GetByID(id int64) (events.Event, error)
+}
+
+// Listener receives outbox notifications through PostgreSQL LISTEN/NOTIFY and
+// publishes the events to the local broker, so every replica sees every event
+type Listener struct {
+	databaseURL string
+	loader      EventLoader
+	broker      *Broker
+}
+
+// NewListener creates a new Listener
+func NewListener(databaseURL string, loader EventLoader, broker *Broker) *Listener {
+	return &Listener{
+		databaseURL: databaseURL,
+		loader:      loader,
+		broker:      broker,
+	}
+}
+
+// Run listens for notifications until ctx is cancelled
+func (l *Listener) Run(ctx context.Context) error {
+	listener := pq.NewListener(l.databaseURL, time.Second, time.Minute, func(ev pq.ListenerEventType, err error) {
+		if err != nil {
+			log.Printf("Event listener connection problem: %v", err)
+		}
+	})
+	defer listener.Close()
+
+	if err := listener.Listen(events.NotifyChannel); err != nil {
+		return err
+	}
+
+	ping := time.NewTicker(90 * time.Second)
+	defer ping.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case n := <-listener.Notify:
+			// A nil notification means the connection was re-established;
+			// notifications sent in the meantime are lost
+			if n == nil {
+				l.broker.Gap()
+				continue
+			}
+			l.handle(n.Extra)
+		case <-ping.C:
+			go listener.Ping()
+		}
+	}
+}
+
+func (l *Listener) handle(payload string) {
+	id, err := strconv.ParseInt(payload, 10, 64)
+	if err != nil {
+		log.Printf("Invalid event notification %q: %v", payload, err)
+		return
+	}
+
+	event, err := l.loader.GetByID(id)
+	if err != nil {
+		log.Printf("Failed to load event %d: %v", id, err)
+		return
+	}
+	l.broker.Publish(event)
+}
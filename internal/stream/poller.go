@@ -0,0 +1,67 @@
+package stream
+
+import (
+	"context"
+	"log"
+	"qa-api/internal/events"
+	"time"
+)
+
+// Tail reads the outbox in commit order
+type Tail interface {
+	LastEventID() (int64, error)
+	EventsAfter(afterID int64, limit int) ([]events.Event, error)
+}
+
+// Poller publishes new outbox events to the local broker by polling the
+// outbox, for databases without LISTEN/NOTIFY. It relies on events being
+// committed in ID order, which holds for SQLite with its single writer.
+type Poller struct {
+	tail     Tail
+	broker   *Broker
+	interval time.Duration
+	batch    int
+}
+
+// NewPoller creates a new Poller
+func NewPoller(tail Tail, broker *Broker, interval time.Duration) *Poller {
+	return &Poller{tail: tail, broker: broker, interval: interval, batch: 100}
+}
+
+// Run polls until ctx is cancelled. Events committed before Run are not
+// published, like notifications sent before LISTEN.
+func (p *Poller) Run(ctx context.Context) error {
+	lastID, err := p.tail.LastEventID()
+	if err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			lastID = p.poll(lastID)
+		}
+	}
+}
+
+// poll publishes the events after lastID and returns the ID of the last one
+func (p *Poller) poll(lastID int64) int64 {
+	for {
+		batch, err := p.tail.EventsAfter(lastID, p.batch)
+		if err != nil {
+			log.Printf("Failed to poll events: %v", err)
+			return lastID
+		}
+		for _, event := range batch {
+			p.broker.Publish(event)
+			lastID = event.ID
+		}
+		if len(batch) < p.batch {
+			return lastID
+		}
+	}
+}
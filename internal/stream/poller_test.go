@@ -0,0 +1,53 @@
+package stream
+
+import (
+	"qa-api/internal/events"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeTail serves events from a slice ordered by ID
+type fakeTail struct {
+	events []events.Event
+}
+
+func (f *fakeTail) LastEventID() (int64, error) {
+	if len(f.events) == 0 {
+		return 0, nil
+	}
+	return f.events[len(f.events)-1].ID, nil
+}
+
+func (f *fakeTail) EventsAfter(afterID int64, limit int) ([]events.Event, error) {
+	var result []events.Event
+	for _, event := range f.events {
+		if event.ID > afterID && len(result) < limit {
+			result = append(result, event)
+		}
+	}
+	return result, nil
+}
+
+func TestPoller_Poll(t *testing.T) {
+	tail := &fakeTail{}
+	for id := int64(1); id <= 5; id++ {
+		tail.events = append(tail.events, events.Event{ID: id})
+	}
+	broker := NewBroker()
+	sub := broker.Subscribe(func(events.Event) bool { return true }, 10)
+	defer broker.Unsubscribe(sub)
+
+	poller := NewPoller(tail, broker, time.Second)
+	poller.batch = 2
+	assert.Equal(t, int64(5), poller.poll(2), "batches are read until the outbox is drained")
+
+	var ids []int64
+	for len(sub.Events()) > 0 {
+		ids = append(ids, (<-sub.Events()).ID)
+	}
+	assert.Equal(t, []int64{3, 4, 5}, ids)
+	assert.Equal(t, int64(5), poller.poll(5))
+	assert.Len(t, sub.Events(), 0)
+}
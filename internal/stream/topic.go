@@ -0,0 +1,46 @@
+package stream
+
+import (
+	"fmt"
+	"qa-api/internal/events"
+	"strconv"
+	"strings"
+)
+
+// Topic prefixes understood by ParseTopic
+const (
+	TopicQuestions = "questions"
+	topicQuestion  = "question:"
+	topicUser      = "user:"
+)
+
+// ParseTopic returns the event filter for a topic:
+//
+//	questions      new questions
+//	question:<id>  answers created or deleted on a question
+//	user:<id>      answers created or deleted by a user
+func ParseTopic(topic string) (func(events.Event) bool, error) {
+	switch {
+	case topic == TopicQuestions:
+		return func(event events.Event) bool {
+			return event.Type == events.QuestionCreated
+		}, nil
+	case strings.HasPrefix(topic, topicQuestion):
+		id, err := strconv.Atoi(strings.TrimPrefix(topic, topicQuestion))
+		if err != nil || id <= 0 {
+			return nil, fmt.Errorf("invalid question ID in topic %q", topic)
+		}
+		return ForQuestion(id), nil
+	case strings.HasPrefix(topic, topicUser):
+		userID := strings.TrimPrefix(topic, topicUser)
+		if userID == "" {
+			return nil, fmt.Errorf("empty user ID in topic %q", topic)
+		}
+		return func(event events.Event) bool {
+			return (event.Type == events.AnswerCreated || event.Type == events.AnswerDeleted) &&
+				event.UserID() == userID
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown topic %q", topic)
+	}
+}
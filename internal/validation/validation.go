@@ -0,0 +1,144 @@
+// Package validation checks request DTOs against rules declared in struct tags.
+//
+// Rules are listed in the `validate` tag, separated by commas:
+//
+//	required   the value must not be blank
+//	min=N      at least N characters
+//	max=N      at most N characters
+//	nocontrol  no control characters other than tab and line breaks
+//	userid     1-64 characters of letters, digits, '.', '_', '@' and '-'
+//	url        an absolute http or https URL
+//
+// Before the rules run every string field is normalized to Unicode NFC, so
+// visually identical input is stored and compared the same way.
+package validation
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// FieldError describes why a single field was rejected
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Errors is the list of rejected fields of a request
+type Errors []FieldError
+
+func (e Errors) Error() string {
+	messages := make([]string, len(e))
+	for i, fieldErr := range e {
+		messages[i] = fieldErr.Field + ": " + fieldErr.Message
+	}
+	return strings.Join(messages, "; ")
+}
+
+var userIDPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._@-]{0,63}$`)
+
+// Struct normalizes the string fields of the struct v points to and checks
+// them against their rules. It returns nil when everything is valid.
+func Struct(v interface{}) Errors {
+	value := reflect.ValueOf(v)
+	if value.Kind() != reflect.Ptr || value.Elem().Kind() != reflect.Struct {
+		panic("validation: Struct expects a pointer to a struct")
+	}
+	value = value.Elem()
+
+	var errs Errors
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		fieldValue := value.Field(i)
+		if fieldValue.Kind() != reflect.String {
+			continue
+		}
+
+		normalized := norm.NFC.String(fieldValue.String())
+		fieldValue.SetString(normalized)
+
+		tag := field.Tag.Get("validate")
+		if tag == "" {
+			continue
+		}
+		name := fieldName(field)
+		for _, rule := range strings.Split(tag, ",") {
+			if fieldErr := check(name, rule, normalized); fieldErr != nil {
+				errs = append(errs, *fieldErr)
+				break
+			}
+		}
+	}
+
+	return errs
+}
+
+// check applies a single rule; blank optional values pass every rule but required
+func check(field, rule, value string) *FieldError {
+	name, arg, _ := strings.Cut(rule, "=")
+	if name != "required" && value == "" {
+		return nil
+	}
+
+	switch name {
+	case "required":
+		if strings.TrimSpace(value) == "" {
+			return &FieldError{Field: field, Code: "required", Message: "is required"}
+		}
+	case "min":
+		if utf8.RuneCountInString(value) < limit(rule, arg) {
+			return &FieldError{Field: field, Code: "too_short", Message: fmt.Sprintf("must be at least %s characters", arg)}
+		}
+	case "max":
+		if utf8.RuneCountInString(value) > limit(rule, arg) {
+			return &FieldError{Field: field, Code: "too_long", Message: fmt.Sprintf("must be at most %s characters", arg)}
+		}
+	case "nocontrol":
+		for _, r := range value {
+			if unicode.IsControl(r) && r != '\t' && r != '\n' && r != '\r' {
+				return &FieldError{Field: field, Code: "control_characters", Message: "must not contain control characters"}
+			}
+		}
+	case "userid":
+		if !userIDPattern.MatchString(value) {
+			return &FieldError{Field: field, Code: "invalid_format", Message: "must be 1-64 letters, digits, '.', '_', '@' or '-'"}
+		}
+	case "url":
+		parsed, err := url.Parse(value)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return &FieldError{Field: field, Code: "invalid_url", Message: "must be an absolute http or https URL"}
+		}
+	default:
+		panic("validation: unknown rule " + strconv.Quote(rule))
+	}
+	return nil
+}
+
+func limit(rule, arg string) int {
+	n, err := strconv.Atoi(arg)
+	if err != nil {
+		panic("validation: bad limit in rule " + strconv.Quote(rule))
+	}
+	return n
+}
+
+// fieldName reports the field under its JSON name so clients can map errors back
+func fieldName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" || name == "-" {
+		return field.Name
+	}
+	return name
+}
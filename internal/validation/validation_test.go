@@ -0,0 +1,55 @@
+package validation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type request struct {
+	UserID  string `json:"user_id" validate:"required,userid"`
+	Text    string `json:"text" validate:"required,max=10,nocontrol"`
+	URL     string `json:"url" validate:"url"`
+	Comment string `json:"comment"`
+}
+
+func TestStruct(t *testing.T) {
+	tests := []struct {
+		name  string
+		req   request
+		codes map[string]string
+	}{
+		{"valid", request{UserID: "user-1", Text: "Hello"}, nil},
+		{"missing fields", request{Text: "   "}, map[string]string{"user_id": "required", "text": "required"}},
+		{"too long", request{UserID: "user-1", Text: "Hello, world"}, map[string]string{"text": "too_long"}},
+		{"length counts characters", request{UserID: "user-1", Text: "Привет мир"}, nil},
+		{"control characters", request{UserID: "user-1", Text: "a\x00b"}, map[string]string{"text": "control_characters"}},
+		{"line breaks allowed", request{UserID: "user-1", Text: "a\r\nb\tc"}, nil},
+		{"bad user id", request{UserID: "user 1", Text: "Hello"}, map[string]string{"user_id": "invalid_format"}},
+		{"bad url", request{UserID: "user-1", Text: "Hello", URL: "ftp://example.com"}, map[string]string{"url": "invalid_url"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := Struct(&tt.req)
+			codes := map[string]string{}
+			for _, fieldErr := range errs {
+				codes[fieldErr.Field] = fieldErr.Code
+			}
+			if tt.codes == nil {
+				assert.Empty(t, errs)
+			} else {
+				assert.Equal(t, tt.codes, codes)
+			}
+		})
+	}
+}
+
+func TestStructNormalizesToNFC(t *testing.T) {
+	// "e" and "е" followed by a combining diaeresis compose into "ë" and "ё"
+	req := request{UserID: "user-1", Text: "e\u0308", Comment: "\u0435\u0308"}
+	require.Empty(t, Struct(&req))
+	assert.Equal(t, "\u00eb", req.Text)
+	assert.Equal(t, "\u0451", req.Comment)
+}
@@ -0,0 +1,156 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"qa-api/internal/models"
+	"strconv"
+	"time"
+)
+
+// DeliveryStore provides access to pending webhook deliveries
+type DeliveryStore interface {
+	ClaimDueDeliveries(limit int, lease time.Duration) ([]models.WebhookDelivery, error)
+	UpdateDelivery(delivery *models.WebhookDelivery) error
+}
+
+// Deliverer sends pending deliveries to webhook endpoints, retrying with
+// exponential backoff and moving them to the dead state after MaxAttempts
+type Deliverer struct {
+	store  DeliveryStore
+	client *http.Client
+
+	MaxAttempts  int
+	PollInterval time.Duration
+	BatchSize    int
+	Lease        time.Duration
+	BaseBackoff  time.Duration
+	MaxBackoff   time.Duration
+}
+
+// NewDeliverer creates a new Deliverer with default settings. Deliveries
+// to loopback, link-local and private addresses fail unless allowPrivate
+// is set; requests never go through a proxy, so the check sees the
+// endpoint itself.
+func NewDeliverer(store DeliveryStore, maxAttempts int, allowPrivate bool) *Deliverer {
+	transport := &http.Transport{
+		DialContext:         newDialer(allowPrivate).DialContext,
+		TLSHandshakeTimeout: 10 * time.Second,
+		MaxIdleConns:        10,
+		IdleConnTimeout:     90 * time.Second,
+	}
+	return &Deliverer{
+		store:        store,
+		client:       &http.Client{Timeout: 10 * time.Second, Transport: transport},
+		MaxAttempts:  maxAttempts,
+		PollInterval: time.Second,
+		BatchSize:    50,
+		Lease:        time.Minute,
+		BaseBackoff:  5 * time.Second,
+		MaxBackoff:   time.Hour,
+	}
+}
+
+// Run polls for due deliveries until ctx is cancelled
+func (d *Deliverer) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := d.DeliverOnce(ctx); err != nil {
+			log.Printf("Webhook delivery failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DeliverOnce attempts a single batch of due deliveries and returns its size
+func (d *Deliverer) DeliverOnce(ctx context.Context) (int, error) {
+	deliveries, err := d.store.ClaimDueDeliveries(d.BatchSize, d.Lease)
+	if err != nil {
+		return 0, fmt.Errorf("failed to claim webhook deliveries: %w", err)
+	}
+
+	for i := range deliveries {
+		delivery := &deliveries[i]
+		d.attempt(ctx, delivery)
+		if err := d.store.UpdateDelivery(delivery); err != nil {
+			return 0, fmt.Errorf("failed to update delivery %d: %w", delivery.ID, err)
+		}
+	}
+
+	return len(deliveries), nil
+}
+
+// attempt sends the delivery and records the outcome on it
+func (d *Deliverer) attempt(ctx context.Context, delivery *models.WebhookDelivery) {
+	delivery.Attempts++
+
+	status, err := d.send(ctx, delivery)
+	delivery.ResponseStatus = status
+	if err == nil {
+		now := time.Now()
+		delivery.Status = models.DeliveryDelivered
+		delivery.DeliveredAt = &now
+		delivery.LastError = ""
+		return
+	}
+
+	delivery.LastError = err.Error()
+	if delivery.Webhook == nil || delivery.Attempts >= d.MaxAttempts {
+		delivery.Status = models.DeliveryDead
+		return
+	}
+	delivery.NextAttemptAt = time.Now().Add(d.backoff(delivery.Attempts))
+}
+
+func (d *Deliverer) send(ctx context.Context, delivery *models.WebhookDelivery) (int, error) {
+	if delivery.Webhook == nil {
+		return 0, errors.New("webhook no longer exists")
+	}
+
+	body := []byte(delivery.Payload)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.Webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "qa-api-webhooks")
+	req.Header.Set(EventHeader, delivery.EventType)
+	req.Header.Set(DeliveryHeader, strconv.FormatInt(delivery.ID, 10))
+	req.Header.Set(SignatureHeader, Sign(delivery.Webhook.Secret, body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("endpoint responded with status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// backoff returns the delay before the given attempt number is retried
+func (d *Deliverer) backoff(attempts int) time.Duration {
+	delay := d.BaseBackoff
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= d.MaxBackoff {
+			return d.MaxBackoff
+		}
+	}
+	return delay
+}
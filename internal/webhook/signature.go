@@ -0,0 +1,28 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// Headers sent with every webhook request
+const (
+	SignatureHeader = "X-QA-Signature"
+	EventHeader     = "X-QA-Event"
+	DeliveryHeader  = "X-QA-Delivery"
+)
+
+const signaturePrefix = "sha256="
+
+// Sign returns the HMAC-SHA256 signature of body in the "sha256=<hex>" format
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks a signature produced by Sign in constant time
+func Verify(secret string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, body)), []byte(signature))
+}
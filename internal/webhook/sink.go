@@ -0,0 +1,61 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"qa-api/internal/events"
+	"qa-api/internal/models"
+	"time"
+)
+
+// SubscriptionStore provides webhook subscriptions and stores new deliveries
+type SubscriptionStore interface {
+	FindForQuestion(questionID int) ([]models.Webhook, error)
+	EnqueueDeliveries(deliveries []models.WebhookDelivery) error
+}
+
+// Sink turns domain events into pending deliveries for matching webhooks.
+// It is registered with the events dispatcher; HTTP calls are made by the Deliverer.
+type Sink struct {
+	store SubscriptionStore
+}
+
+// NewSink creates a new Sink
+func NewSink(store SubscriptionStore) *Sink {
+	return &Sink{store: store}
+}
+
+// Name returns the sink name
+func (s *Sink) Name() string {
+	return "webhooks"
+}
+
+// Publish enqueues a delivery for every webhook subscribed to the event
+func (s *Sink) Publish(ctx context.Context, event events.Event) error {
+	webhooks, err := s.store.FindForQuestion(event.QuestionID)
+	if err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	var deliveries []models.WebhookDelivery
+	for _, webhook := range webhooks {
+		if !webhook.Matches(event.Type, event.QuestionID) {
+			continue
+		}
+		deliveries = append(deliveries, models.WebhookDelivery{
+			WebhookID:     webhook.ID,
+			EventID:       event.ID,
+			EventType:     event.Type,
+			Payload:       models.RawJSON(body),
+			Status:        models.DeliveryPending,
+			NextAttemptAt: time.Now(),
+		})
+	}
+
+	return s.store.EnqueueDeliveries(deliveries)
+}
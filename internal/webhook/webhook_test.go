@@ -0,0 +1,184 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"qa-api/internal/events"
+	"qa-api/internal/models"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryStore is an in-memory implementation of SubscriptionStore and DeliveryStore
+type memoryStore struct {
+	mu         sync.Mutex
+	webhooks   []models.Webhook
+	deliveries []models.WebhookDelivery
+}
+
+func (s *memoryStore) FindForQuestion(questionID int) ([]models.Webhook, error) {
+	return s.webhooks, nil
+}
+
+func (s *memoryStore) EnqueueDeliveries(deliveries []models.WebhookDelivery) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, d := range deliveries {
+		d.ID = int64(len(s.deliveries) + 1)
+		s.deliveries = append(s.deliveries, d)
+	}
+	return nil
+}
+
+func (s *memoryStore) ClaimDueDeliveries(limit int, lease time.Duration) ([]models.WebhookDelivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var due []models.WebhookDelivery
+	for _, d := range s.deliveries {
+		if d.Status == models.DeliveryPending {
+			for i := range s.webhooks {
+				if s.webhooks[i].ID == d.WebhookID {
+					d.Webhook = &s.webhooks[i]
+				}
+			}
+			due = append(due, d)
+		}
+	}
+	return due, nil
+}
+
+func (s *memoryStore) UpdateDelivery(delivery *models.WebhookDelivery) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.deliveries {
+		if s.deliveries[i].ID == delivery.ID {
+			s.deliveries[i] = *delivery
+		}
+	}
+	return nil
+}
+
+func TestSignature(t *testing.T) {
+	body := []byte(`{"id":1}`)
+	signature := Sign("secret", body)
+
+	assert.True(t, Verify("secret", body, signature))
+	assert.False(t, Verify("other", body, signature))
+	assert.False(t, Verify("secret", []byte(`{"id":2}`), signature))
+}
+
+func TestSink_Publish(t *testing.T) {
+	questionID := 2
+	store := &memoryStore{webhooks: []models.Webhook{
+		{ID: 1, EventTypes: models.StringList{events.AnswerCreated}},
+		{ID: 2, EventTypes: models.StringList{events.AnswerCreated}, QuestionID: &questionID},
+		{ID: 3, EventTypes: models.StringList{events.QuestionCreated}},
+	}}
+
+	err := NewSink(store).Publish(context.Background(), events.Event{ID: 10, Type: events.AnswerCreated, QuestionID: 1})
+
+	require.NoError(t, err)
+	require.Len(t, store.deliveries, 1)
+	assert.Equal(t, 1, store.deliveries[0].WebhookID)
+	assert.Equal(t, int64(10), store.deliveries[0].EventID)
+}
+
+func TestDeliverer_DeliverOnce(t *testing.T) {
+	t.Run("signed delivery", func(t *testing.T) {
+		var signature, body string
+		receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			data, _ := io.ReadAll(r.Body)
+			body = string(data)
+			signature = r.Header.Get(SignatureHeader)
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer receiver.Close()
+
+		store := &memoryStore{webhooks: []models.Webhook{{ID: 1, URL: receiver.URL, Secret: "s3cret"}}}
+		store.EnqueueDeliveries([]models.WebhookDelivery{{WebhookID: 1, EventType: events.AnswerCreated, Payload: `{"id":1}`, Status: models.DeliveryPending}})
+
+		n, err := NewDeliverer(store, 3, true).DeliverOnce(context.Background())
+
+		require.NoError(t, err)
+		assert.Equal(t, 1, n)
+		assert.Equal(t, `{"id":1}`, body)
+		assert.True(t, Verify("s3cret", []byte(body), signature))
+		assert.Equal(t, models.DeliveryDelivered, store.deliveries[0].Status)
+		assert.Equal(t, http.StatusNoContent, store.deliveries[0].ResponseStatus)
+	})
+
+	t.Run("dead after max attempts", func(t *testing.T) {
+		receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer receiver.Close()
+
+		store := &memoryStore{webhooks: []models.Webhook{{ID: 1, URL: receiver.URL, Secret: "s3cret"}}}
+		store.EnqueueDeliveries([]models.WebhookDelivery{{WebhookID: 1, Payload: `{}`, Status: models.DeliveryPending}})
+		deliverer := NewDeliverer(store, 3, true)
+
+		_, err := deliverer.DeliverOnce(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, models.DeliveryPending, store.deliveries[0].Status)
+		assert.True(t, store.deliveries[0].NextAttemptAt.After(time.Now()))
+
+		deliverer.DeliverOnce(context.Background())
+		deliverer.DeliverOnce(context.Background())
+
+		assert.Equal(t, models.DeliveryDead, store.deliveries[0].Status)
+		assert.Equal(t, 3, store.deliveries[0].Attempts)
+		assert.Equal(t, http.StatusInternalServerError, store.deliveries[0].ResponseStatus)
+	})
+
+	t.Run("internal addresses are refused on dial", func(t *testing.T) {
+		delivered := false
+		receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			delivered = true
+		}))
+		defer receiver.Close()
+
+		store := &memoryStore{webhooks: []models.Webhook{{ID: 1, URL: receiver.URL, Secret: "s3cret"}}}
+		store.EnqueueDeliveries([]models.WebhookDelivery{{WebhookID: 1, Payload: `{}`, Status: models.DeliveryPending}})
+
+		_, err := NewDeliverer(store, 3, false).DeliverOnce(context.Background())
+		require.NoError(t, err)
+		assert.False(t, delivered)
+		assert.Equal(t, models.DeliveryPending, store.deliveries[0].Status)
+		assert.Contains(t, store.deliveries[0].LastError, ErrForbiddenTarget.Error())
+	})
+}
+
+func TestCheckURL(t *testing.T) {
+	ctx := context.Background()
+	for _, target := range []string{
+		"https://93.184.215.14/hook",
+		"http://[2606:2800:21f:cb07:6820:80da:af6b:8b2c]:8080/hook",
+	} {
+		assert.NoError(t, CheckURL(ctx, target, false), target)
+	}
+	for _, target := range []string{
+		"http://127.0.0.1/hook",
+		"http://localhost:8080/hook",
+		"http://api.localhost/hook",
+		"http://[::1]/hook",
+		"http://169.254.169.254/latest/meta-data",
+		"http://10.0.0.5/hook",
+		"http://192.168.1.1/hook",
+		"http://100.64.0.1/hook",
+		"http://0.0.0.0/hook",
+		"http://[fd00::1]/hook",
+		"http://[::ffff:127.0.0.1]/hook",
+	} {
+		assert.ErrorIs(t, CheckURL(ctx, target, false), ErrForbiddenTarget, target)
+	}
+	for _, target := range []string{"ftp://example.com/hook", "/hook", "http://"} {
+		assert.EqualError(t, CheckURL(ctx, target, false), "url must be an absolute http or https URL", target)
+	}
+	assert.NoError(t, CheckURL(ctx, "http://127.0.0.1/hook", true), "private targets can be allowed")
+}
@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE questions ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+ALTER TABLE questions ADD COLUMN IF NOT EXISTS answer_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE answers ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_questions_deleted_at ON questions(deleted_at);
+CREATE INDEX IF NOT EXISTS idx_answers_deleted_at ON answers(deleted_at);
+
+UPDATE questions SET answer_count = (
+    SELECT COUNT(*) FROM answers WHERE answers.question_id = questions.id
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM answers WHERE deleted_at IS NOT NULL;
+DELETE FROM questions WHERE deleted_at IS NOT NULL;
+DROP INDEX IF EXISTS idx_answers_deleted_at;
+DROP INDEX IF EXISTS idx_questions_deleted_at;
+ALTER TABLE answers DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE questions DROP COLUMN IF EXISTS answer_count;
+ALTER TABLE questions DROP COLUMN IF EXISTS deleted_at;
+-- +goose StatementEnd
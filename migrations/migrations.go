@@ -0,0 +1,23 @@
+// Package migrations embeds the goose SQL migrations, so the binaries do not
+// depend on the working directory they are started from.
+package migrations
+
+import (
+	"embed"
+	"io/fs"
+)
+
+// FS holds the *.sql migrations at its root, written for PostgreSQL; the
+// SQLite variants of the same versions are in the sqlite directory
+//
+//go:embed *.sql sqlite/*.sql
+var FS embed.FS
+
+// ForDialect returns the migrations of a SQL dialect ("postgres" or "sqlite")
+func ForDialect(dialect string) fs.FS {
+	if dialect == "sqlite" {
+		sqlite, _ := fs.Sub(FS, "sqlite")
+		return sqlite
+	}
+	return FS
+}
@@ -0,0 +1,43 @@
+package migrations
+
+import (
+	"fmt"
+	"io/fs"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmbeddedMigrations(t *testing.T) {
+	names, err := fs.Glob(FS, "*.sql")
+	require.NoError(t, err)
+	require.NotEmpty(t, names)
+
+	for i, name := range names {
+		assert.True(t, strings.HasPrefix(name, fmt.Sprintf("%03d_", i+1)), "%s is out of sequence", name)
+
+		content, err := fs.ReadFile(FS, name)
+		require.NoError(t, err)
+		assert.Contains(t, string(content), "-- +goose Up", name)
+		assert.Contains(t, string(content), "-- +goose Down", name)
+	}
+}
+
+func TestSQLiteMigrations(t *testing.T) {
+	postgres, err := fs.Glob(FS, "*.sql")
+	require.NoError(t, err)
+	sqlite, err := fs.Glob(ForDialect("sqlite"), "*.sql")
+	require.NoError(t, err)
+	assert.Equal(t, postgres, sqlite, "every migration needs a SQLite variant of the same name")
+
+	for _, name := range sqlite {
+		content, err := fs.ReadFile(ForDialect("sqlite"), name)
+		require.NoError(t, err)
+		assert.Contains(t, string(content), "-- +goose Up", name)
+		assert.Contains(t, string(content), "-- +goose Down", name)
+		assert.NotContains(t, string(content), "SERIAL", name)
+		assert.NotContains(t, string(content), "JSONB", name)
+	}
+}
@@ -0,0 +1,49 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS users (
+    id VARCHAR(255) PRIMARY KEY,
+    display_name VARCHAR(100) NOT NULL DEFAULT '',
+    avatar_url TEXT NOT NULL DEFAULT '',
+    bio TEXT NOT NULL DEFAULT '',
+    joined_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Everyone who answered so far joined with their first answer
+INSERT INTO users (id, joined_at)
+SELECT user_id, MIN(created_at) FROM answers GROUP BY user_id;
+
+ALTER TABLE questions ADD COLUMN user_id VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE questions ADD COLUMN accepted_answer_id INTEGER;
+CREATE INDEX IF NOT EXISTS idx_questions_user_id ON questions(user_id);
+
+CREATE TABLE IF NOT EXISTS votes (
+    answer_id INTEGER NOT NULL REFERENCES answers(id) ON DELETE CASCADE,
+    user_id VARCHAR(255) NOT NULL,
+    value INTEGER NOT NULL CHECK (value IN (-1, 1)),
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (answer_id, user_id)
+);
+
+CREATE TABLE IF NOT EXISTS reputation_ledger (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id VARCHAR(255) NOT NULL,
+    event_id INTEGER NOT NULL,
+    reason VARCHAR(50) NOT NULL,
+    delta INTEGER NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (event_id, user_id, reason)
+);
+
+CREATE INDEX IF NOT EXISTS idx_reputation_ledger_user_id ON reputation_ledger(user_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_reputation_ledger_user_id;
+DROP TABLE IF EXISTS reputation_ledger;
+DROP TABLE IF EXISTS votes;
+DROP INDEX IF EXISTS idx_questions_user_id;
+ALTER TABLE questions DROP COLUMN accepted_answer_id;
+ALTER TABLE questions DROP COLUMN user_id;
+DROP TABLE IF EXISTS users;
+-- +goose StatementEnd
@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE questions ADD COLUMN view_count INTEGER NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS badge_awards (
+    user_id VARCHAR(255) NOT NULL,
+    badge VARCHAR(50) NOT NULL,
+    tier INTEGER NOT NULL,
+    awarded_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, badge, tier)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS badge_awards;
+ALTER TABLE questions DROP COLUMN view_count;
+-- +goose StatementEnd